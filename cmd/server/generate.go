@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/drone/ff-mock-server/internal/generator"
+)
+
+// generateCommand writes a generated dataset which can be served with --dataset
+type generateCommand struct {
+	Flags    int    `long:"flags" default:"10000" description:"Number of generated flags"`
+	Segments int    `long:"segments" default:"100" description:"Number of generated segments"`
+	Targets  int    `long:"targets" default:"1000" description:"Number of distinct targets referenced by segments and target maps"`
+	Rules    int    `long:"rules" default:"5" description:"Maximum number of serving rules of a flag"`
+	Seed     int64  `long:"seed" default:"1" description:"Seed of the dataset, same seed generates same dataset"`
+	Output   string `long:"output" description:"Output file, the dataset is written to stdout if not set"`
+}
+
+// Execute generates the dataset and writes it as json
+func (c *generateCommand) Execute(_ []string) error {
+	options := generator.Options{
+		Seed:     c.Seed,
+		Flags:    c.Flags,
+		Segments: c.Segments,
+		Targets:  c.Targets,
+		Rules:    c.Rules,
+	}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	dataset := generator.Generate(options)
+
+	if c.Output == "" {
+		return json.NewEncoder(os.Stdout).Encode(dataset)
+	}
+	file, err := os.Create(filepath.Clean(c.Output))
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(dataset); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
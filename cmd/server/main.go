@@ -3,87 +3,52 @@ package main
 import (
 	"context"
 	"log"
-	"net/http"
 	"os"
 	"os/signal"
 	"time"
 
 	"github.com/drone/ff-mock-server/internal/config"
+	"github.com/drone/ff-mock-server/internal/server"
 	"github.com/jessevdk/go-flags"
-
-	oapimdl "github.com/deepmap/oapi-codegen/pkg/middleware"
-	"github.com/drone/ff-mock-server/internal"
-	"github.com/drone/ff-mock-server/internal/dto"
-	"github.com/drone/ff-mock-server/internal/repository"
-	"github.com/drone/ff-mock-server/internal/router"
-	"github.com/drone/ff-mock-server/pkg/api"
-	"github.com/getkin/kin-openapi/openapi3filter"
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
-	"github.com/r3labs/sse/v2"
 )
 
 func main() {
-	_, err := flags.ParseArgs(&config.Options, os.Args)
-	if err != nil {
+	parser := flags.NewParser(&config.Options, flags.Default)
+	parser.SubcommandsOptional = true
+	if _, err := parser.AddCommand("generate", "Generate dataset",
+		"Writes a reproducible dataset of flags and segments for load and scale testing", &generateCommand{}); err != nil {
 		log.Fatal(err)
 	}
-
-	e := echo.New()
-	e.HideBanner = true
-
-	e.Server.ReadTimeout = 15 * time.Second
-	e.Server.WriteTimeout = 15 * time.Second
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-	e.Use(router.ProcessCLIFlags())
-
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, "Cache-Control",
-			echo.HeaderAuthorization, "api-key", "Pragma"},
-	}))
-
-	e.GET("/health", HealthCheck)
-
-	clientSwagger, err := api.GetSwagger()
-	if err != nil {
-		log.Fatalf("Error loading swagger spec\n: %s", err)
+	if _, err := parser.Parse(); err != nil {
+		// the parser already printed the error or help
+		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(1)
 	}
-
-	jwtConfig := middleware.JWTConfig{
-		Skipper: func(e echo.Context) bool {
-			jwt, ok := e.Get(internal.JWTKey).(bool)
-			if ok && jwt {
-				return false
-			}
-			return true
-		},
-		Claims:     &dto.JWTCustomClaims{},
-		SigningKey: []byte(config.GetAuthSecret()), // SDK_AUTH_TOKEN change on next deploy
+	if parser.Active != nil {
+		// subcommand was executed by the parser
+		return
 	}
 
-	clientGroup := e.Group("api/1.0")
-	clientGroup.Use(oapimdl.OapiRequestValidatorWithOptions(clientSwagger, &oapimdl.Options{
-		Options: openapi3filter.Options{
-			AuthenticationFunc: router.JWTValidation,
-		},
-	}))
-	clientGroup.Use(middleware.JWTWithConfig(jwtConfig))
-
-	clientGroup.Use(router.ValidateEnvironment())
-
-	server := sse.New()
-	repo := repository.NewDummyRepository()
-	handler := router.NewHandler(repo, server)
-	api.RegisterHandlers(clientGroup, handler)
-
+	s, err := server.New(config.Options)
+	if err != nil {
+		log.Fatalf("Error creating server: %s", err)
+	}
 	// Start server
-	go func() {
-		if err := e.Start(":3000"); err != nil && err != http.ErrServerClosed {
-			e.Logger.Fatal("shutting down the server")
-		}
-	}()
+	if err := s.Start(); err != nil {
+		log.Fatalf("Error starting server: %s", err)
+	}
+	scheme := "http"
+	if s.TLS() {
+		scheme = "https"
+	}
+	for _, address := range config.Options.Listen {
+		s.Logger.Infof("Listening on %s://%s", scheme, address)
+	}
+	for _, address := range config.Options.EventsListen {
+		s.Logger.Infof("Serving stream and metrics on %s://%s", scheme, address)
+	}
 
 	// Wait for interrupt signal to gracefully shutdown the server with a timeout of 10 seconds.
 	// Use a buffered channel to avoid missing signals as recommended for signal.Notify
@@ -93,12 +58,8 @@ func main() {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	if err := e.Shutdown(ctx); err != nil {
-		e.Logger.Fatal(err)
+	s.Close()
+	if err := s.Shutdown(ctx); err != nil {
+		s.Logger.Fatal(err)
 	}
 }
-
-// HealthCheck returns the health of the service
-func HealthCheck(ctx echo.Context) error {
-	return ctx.String(http.StatusOK, "healthy")
-}
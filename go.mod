@@ -3,14 +3,16 @@ module github.com/drone/ff-mock-server
 go 1.16
 
 require (
+	github.com/andybalholm/brotli v1.0.4
 	github.com/deepmap/oapi-codegen v1.8.3
 	github.com/getkin/kin-openapi v0.61.0
 	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/jessevdk/go-flags v1.5.0
 	github.com/labstack/echo/v4 v4.6.1
 	github.com/labstack/gommon v0.3.1
+	github.com/prometheus/client_golang v1.12.2
 	github.com/r3labs/sse/v2 v2.7.2
+	github.com/sirupsen/logrus v1.9.3
 	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871 // indirect
-	golang.org/x/net v0.0.0-20211118161319-6a13c67c3ce4 // indirect
-	golang.org/x/sys v0.0.0-20211117180635-dee7805ff2e1 // indirect
+	golang.org/x/net v0.0.0-20211118161319-6a13c67c3ce4
 )
@@ -0,0 +1,179 @@
+// Package clock provides the time source of the server, tests freeze, advance
+// or speed it up to go through token lifetimes and stream outages instantly
+package clock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrInvalidSpeed is returned when speed isn't a positive number
+var ErrInvalidSpeed = errors.New("speed must be a positive number")
+
+// State describes the clock at a point in time
+type State struct {
+	Time   time.Time `json:"time"`
+	Speed  float64   `json:"speed"`
+	Frozen bool      `json:"frozen"`
+}
+
+// Clock runs with wall clock time unless it is frozen, advanced or its speed is
+// changed. Timers fire when the clock reaches their deadline
+type Clock struct {
+	mu sync.Mutex
+	// virtual is the clock time at wall time real, the clock moves from there
+	// with speed unless it is frozen
+	real    time.Time
+	virtual time.Time
+	speed   float64
+	frozen  bool
+	// changed is closed and replaced on every change so timers recompute deadlines
+	changed chan struct{}
+}
+
+// New returns new Clock running with wall clock time
+func New() *Clock {
+	now := time.Now()
+	return &Clock{
+		real:    now,
+		virtual: now,
+		speed:   1,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now returns current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now()
+}
+
+func (c *Clock) now() time.Time {
+	if c.frozen {
+		return c.virtual
+	}
+	elapsed := time.Since(c.real)
+	return c.virtual.Add(time.Duration(float64(elapsed) * c.speed))
+}
+
+// Since returns clock time elapsed since t
+func (c *Clock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// State returns current time, speed and whether the clock is frozen
+func (c *Clock) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return State{Time: c.now(), Speed: c.speed, Frozen: c.frozen}
+}
+
+// Freeze stops the clock until it is resumed, it can still be advanced
+func (c *Clock) Freeze() State {
+	return c.change(func() {
+		c.frozen = true
+	})
+}
+
+// Resume starts the frozen clock from the time it was frozen at
+func (c *Clock) Resume() State {
+	return c.change(func() {
+		c.frozen = false
+	})
+}
+
+// Advance moves the clock forward by d, timers due in the meantime fire
+func (c *Clock) Advance(d time.Duration) State {
+	return c.change(func() {
+		c.virtual = c.virtual.Add(d)
+	})
+}
+
+// SetSpeed changes how fast the clock runs compared to wall clock, speed 60
+// makes a minute pass in a second
+func (c *Clock) SetSpeed(speed float64) (State, error) {
+	if speed <= 0 {
+		return State{}, ErrInvalidSpeed
+	}
+	return c.change(func() {
+		c.speed = speed
+	}), nil
+}
+
+// change rebases the clock on current wall time, applies fn and wakes up timers
+func (c *Clock) change(fn func()) State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.virtual = c.now()
+	c.real = time.Now()
+	fn()
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return State{Time: c.now(), Speed: c.speed, Frozen: c.frozen}
+}
+
+// wait returns wall time duration until deadline at current speed and channel
+// closed on the next change, negative duration means the deadline was reached
+// and zero that it won't be reached without a change
+func (c *Clock) wait(deadline time.Time) (time.Duration, <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	remaining := deadline.Sub(c.now())
+	if remaining <= 0 {
+		return -1, c.changed
+	}
+	if c.frozen {
+		return 0, c.changed
+	}
+	return time.Duration(float64(remaining)/c.speed) + 1, c.changed
+}
+
+// Sleep blocks until the clock moved by d or ctx is done
+func (c *Clock) Sleep(ctx context.Context, d time.Duration) error {
+	return c.sleepUntil(ctx, c.Now().Add(d))
+}
+
+// sleepUntil blocks until the clock reached deadline or ctx is done
+func (c *Clock) sleepUntil(ctx context.Context, deadline time.Time) error {
+	for {
+		delay, changed := c.wait(deadline)
+		if delay < 0 {
+			return nil
+		}
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		if delay > 0 {
+			timer = time.NewTimer(delay)
+			timeout = timer.C
+		}
+		select {
+		case <-timeout:
+		case <-changed:
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return ctx.Err()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// After returns channel receiving the clock time once it moved by d, the timer
+// is released when ctx is done. The deadline is set before After returns so
+// changes of the clock right after the call aren't missed
+func (c *Clock) After(ctx context.Context, d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	deadline := c.Now().Add(d)
+	go func() {
+		if err := c.sleepUntil(ctx, deadline); err == nil {
+			ch <- c.Now()
+		}
+	}()
+	return ch
+}
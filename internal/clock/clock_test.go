@@ -0,0 +1,152 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// sleepAsync sleeps in a goroutine and returns channel receiving its result
+func sleepAsync(ctx context.Context, c *Clock, d time.Duration) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Sleep(ctx, d)
+	}()
+	return done
+}
+
+func expectBlocked(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		t.Fatalf("sleep returned %v before the clock reached its deadline", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func expectDone(t *testing.T, done <-chan error, want error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != want {
+			t.Fatalf("sleep returned %v, want %v", err, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sleep didn't return")
+	}
+}
+
+func TestFreezeAndAdvance(t *testing.T) {
+	c := New()
+	frozen := c.Freeze()
+	if !frozen.Frozen || frozen.Speed != 1 {
+		t.Fatalf("got state %+v after freezing", frozen)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if !c.Now().Equal(frozen.Time) {
+		t.Error("frozen clock moved")
+	}
+	if state := c.Advance(time.Hour); !state.Time.Equal(frozen.Time.Add(time.Hour)) {
+		t.Errorf("advanced clock is at %s, want %s", state.Time, frozen.Time.Add(time.Hour))
+	}
+	if since := c.Since(frozen.Time); since != time.Hour {
+		t.Errorf("got %s since freezing, want 1h", since)
+	}
+
+	resumed := c.Resume()
+	if elapsed := resumed.Time.Sub(frozen.Time.Add(time.Hour)); resumed.Frozen || elapsed < 0 || elapsed > time.Millisecond {
+		t.Errorf("got state %+v after resuming", resumed)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if !c.Now().After(resumed.Time) {
+		t.Error("resumed clock doesn't move")
+	}
+}
+
+func TestSetSpeed(t *testing.T) {
+	c := New()
+	for _, speed := range []float64{0, -1} {
+		if _, err := c.SetSpeed(speed); err != ErrInvalidSpeed {
+			t.Errorf("SetSpeed(%g) = %v, want %v", speed, err, ErrInvalidSpeed)
+		}
+	}
+	state, err := c.SetSpeed(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if elapsed := c.Since(state.Time); elapsed < 5*time.Second {
+		t.Errorf("clock at speed 1000 moved by %s in 10ms", elapsed)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	tests := []struct {
+		name string
+		// change is applied to the frozen clock while Sleep(time.Minute) blocks
+		change func(c *Clock)
+		done   bool
+	}{
+		{name: "advanced past deadline", change: func(c *Clock) { c.Advance(2 * time.Minute) }, done: true},
+		{name: "advanced to deadline", change: func(c *Clock) { c.Advance(time.Minute) }, done: true},
+		{name: "advanced before deadline", change: func(c *Clock) { c.Advance(30 * time.Second) }},
+		{name: "resumed", change: func(c *Clock) { c.Resume() }},
+		{name: "resumed fast", change: func(c *Clock) {
+			_, _ = c.SetSpeed(60 * 1000)
+			c.Resume()
+		}, done: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			c.Freeze()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := sleepAsync(ctx, c, time.Minute)
+			expectBlocked(t, done)
+			tt.change(c)
+			if tt.done {
+				expectDone(t, done, nil)
+			} else {
+				expectBlocked(t, done)
+			}
+		})
+	}
+}
+
+func TestSleepElapsed(t *testing.T) {
+	c := New()
+	c.Freeze()
+	for _, d := range []time.Duration{0, -time.Second} {
+		if err := c.Sleep(context.Background(), d); err != nil {
+			t.Errorf("Sleep(%s) = %v", d, err)
+		}
+	}
+}
+
+func TestSleepCanceled(t *testing.T) {
+	c := New()
+	c.Freeze()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := sleepAsync(ctx, c, time.Minute)
+	expectBlocked(t, done)
+	cancel()
+	expectDone(t, done, context.Canceled)
+}
+
+func TestAfter(t *testing.T) {
+	c := New()
+	start := c.Freeze().Time
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	after := c.After(ctx, time.Minute)
+	c.Advance(90 * time.Second)
+	select {
+	case now := <-after:
+		if want := start.Add(90 * time.Second); !now.Equal(want) {
+			t.Errorf("got time %s, want %s", now, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timer didn't fire after advancing the clock")
+	}
+}
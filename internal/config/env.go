@@ -1,7 +1,10 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/drone/ff-mock-server/internal"
 )
@@ -14,3 +17,24 @@ func GetAuthSecret() string {
 	}
 	return authJwtSecret
 }
+
+// GetIdentityServiceSecret get secret for IdentityService tokens from environment variable
+func GetIdentityServiceSecret() string {
+	secret := os.Getenv("IDENTITY_SERVICE_SECRET")
+	if len(secret) == 0 {
+		secret = internal.DefaultIdentityServiceSecret
+	}
+	return secret
+}
+
+// GetSigningKey returns secret for HMAC signing methods or PEM encoded private
+// key loaded from file for RSA and ECDSA, empty key means it is generated
+func GetSigningKey(options Config) ([]byte, error) {
+	if strings.HasPrefix(options.SigningMethod, "HS") {
+		return []byte(GetAuthSecret()), nil
+	}
+	if options.SigningKeyFile == "" {
+		return nil, nil
+	}
+	return ioutil.ReadFile(filepath.Clean(options.SigningKeyFile))
+}
@@ -1,11 +1,67 @@
 package config
 
-// Options holds cli flags
-var Options struct {
-	Timeout        *int     `short:"t" long:"timeout" description:"Request timeout"`
-	StatusCode     *int     `short:"s" long:"status-code" description:"returns HTTP status code"`
-	Message        string   `short:"m" long:"message" description:"Message to display in response"`
-	SSEOffSequence []int    `short:"e" long:"sse" description:"SSEOffSequence off sequence in sec"`
-	SSEOffDuration *int     `long:"sse-out" description:"SSEOffSequence off time in sec"`
-	Handlers       []string `short:"o" long:"operation" description:"operation"`
+import "github.com/jessevdk/go-flags"
+
+// Options holds cli flags parsed in main
+var Options Config
+
+// Config holds options of the server, it is filled from cli flags or by pkg/mockserver
+type Config struct {
+	Timeout            *int     `short:"t" long:"timeout" description:"Request timeout"`
+	StatusCode         *int     `short:"s" long:"status-code" description:"returns HTTP status code"`
+	Message            string   `short:"m" long:"message" description:"Message to display in response"`
+	SSEOffSequence     []int    `short:"e" long:"sse" description:"SSEOffSequence off sequence in sec"`
+	SSEOffDuration     *int     `long:"sse-out" description:"SSEOffSequence off time in sec"`
+	Handlers           []string `short:"o" long:"operation" description:"operation"`
+	TokenLifetime      int64    `long:"token-lifetime" description:"Lifetime of issued tokens in sec, 0 means tokens never expire"`
+	TokenIssuer        string   `long:"token-issuer" default:"Harness Inc" description:"Issuer claim of issued tokens"`
+	TokenNotBefore     int64    `long:"token-not-before" description:"Offset of not before claim from issue time in sec"`
+	SigningMethod      string   `long:"signing-method" default:"HS256" choice:"HS256" choice:"HS384" choice:"HS512" choice:"RS256" choice:"ES256" description:"Algorithm used for signing tokens"`
+	SigningKeyFile     string   `long:"signing-key" description:"PEM encoded private key for RS256 and ES256, generated on startup if not set"`
+	APIKeysFile        string   `long:"api-keys" description:"JSON file with additional server and client api keys"`
+	ValidateResponses  string   `long:"validate-responses" default:"off" choice:"off" choice:"log" choice:"fail" description:"Validate responses against api.yaml and log or fail on mismatch"`
+	MaxPageSize        int      `long:"max-page-size" description:"Maximum page size of list endpoints, forces SDKs to load multiple pages"`
+	DatasetFile        string   `long:"dataset" description:"JSON file with flags and segments written by the generate command"`
+	GenerateFlags      int      `long:"generate-flags" description:"Number of flags generated on startup instead of the mocked flag"`
+	GenerateSegments   int      `long:"generate-segments" description:"Number of segments generated on startup"`
+	GenerateTargets    int      `long:"generate-targets" default:"1000" description:"Number of distinct targets referenced by generated segments and target maps"`
+	GenerateRules      int      `long:"generate-rules" default:"5" description:"Maximum number of serving rules of a generated flag"`
+	GenerateSeed       int64    `long:"generate-seed" default:"1" description:"Seed of the generated dataset, same seed generates same dataset"`
+	JournalSize        int      `long:"journal-size" default:"1000" description:"Number of requests kept in the request journal"`
+	StateFile          string   `long:"state-file" description:"State document exported from /admin/state loaded on startup, e.g. a baseline shared by test suites"`
+	ReplayFile         string   `long:"replay" description:"Session file with recorded responses and stream events to replay"`
+	ReplaySpeed        float64  `long:"replay-speed" default:"1" description:"Replay speed multiplier, 2 plays the session twice as fast"`
+	ClockSpeed         float64  `long:"clock-speed" default:"1" description:"Speed of the server clock, 60 makes a minute pass in a second"`
+	ETagMode           string   `long:"etag-mode" default:"strong" choice:"strong" choice:"missing" choice:"stale" description:"Entity tags of configuration responses, missing and stale check cache validation of SDKs"`
+	Compression        string   `long:"compression" default:"negotiate" choice:"negotiate" choice:"off" choice:"wrong-encoding" choice:"truncated" choice:"unrequested" description:"Compression of responses, other modes than negotiate and off are faults"`
+	CompressionMinSize int      `long:"compression-min-size" default:"1024" description:"Minimum size in bytes of responses compressed in negotiate mode"`
+	RateLimits         []string `long:"rate-limit" description:"Rate limit as by:rate[:burst], by is apiKey, environment, operation or ip, e.g. apiKey:10:20, can be repeated"`
+	Listen             []string `long:"listen" default:":3000" description:"Address to listen on, can be repeated"`
+	EventsListen       []string `long:"events-listen" description:"Address stream and metrics are served on instead of the listen addresses, can be repeated"`
+	EventsStatusCode   int      `long:"events-status-code" description:"HTTP status code returned by stream and metrics"`
+	EventsLatency      int      `long:"events-latency" description:"Delay of stream and metrics requests in ms"`
+	BasePath           string   `long:"base-path" default:"/api/1.0" description:"Path prefix of the client API"`
+	EventsBasePath     string   `long:"events-base-path" description:"Path prefix of stream and metrics, base path is used if not set"`
+	TLSCertFile        string   `long:"tls-cert" description:"PEM encoded certificate, requests are served over TLS when set"`
+	TLSKeyFile         string   `long:"tls-key" description:"PEM encoded private key of the certificate"`
+	TLSSelfSigned      bool     `long:"tls-self-signed" description:"Serve TLS with certificate signed by CA generated on startup"`
+	TLSHosts           []string `long:"tls-host" default:"localhost" default:"127.0.0.1" description:"DNS name or IP address of generated certificate, can be repeated"`
+	TLSCAOut           string   `long:"tls-ca-out" description:"File the generated CA certificate is written to, SDKs use it as custom CA bundle"`
+	TLSClientAuth      bool     `long:"tls-client-auth" description:"Require client certificates (mTLS)"`
+	TLSClientCAFile    string   `long:"tls-client-ca" description:"PEM encoded CA bundle verifying client certificates, generated CA is used if not set"`
+	TLSClientCertOut   string   `long:"tls-client-cert-out" description:"File a client certificate and key signed by the generated CA are written to"`
+	LogLevel           string   `long:"log-level" default:"info" choice:"debug" choice:"info" choice:"warn" choice:"error" description:"Minimum level of logged messages"`
+	LogFormat          string   `long:"log-format" default:"json" choice:"json" choice:"text" description:"Format of logged messages"`
+	DisableHTTP2       bool     `long:"disable-http2" description:"Serve HTTP/1.1 only over TLS"`
+	H2C                bool     `long:"h2c" description:"Serve HTTP/2 without TLS (h2c)"`
+}
+
+// Default returns Config with default values of all cli flags
+func Default() Config {
+	options := Config{}
+	if _, err := flags.NewParser(&options, flags.None).ParseArgs(nil); err != nil {
+		// defaults are declared in struct tags, they parse unless the tags are broken
+		panic(err)
+	}
+	return options
 }
@@ -7,8 +7,13 @@ const (
 	// ClientKey is a randomly generated UUID, it can be used only in
 	// client SDKs
 	ClientKey = "2e2ecf62-ce53-4e9e-8006-b4db0386688c"
+	// ProxyKey is a randomly generated UUID, it can be used only by
+	// the relay proxy
+	ProxyKey = "6c4c5e37-8b9c-4d3b-9fb8-0d6d3d4c0a51"
 	// DefaultAuthSecret is used only if there is no value in env variable
 	DefaultAuthSecret = "mock-server"
+	// DefaultIdentityServiceSecret is used only if there is no value in env variable
+	DefaultIdentityServiceSecret = "mock-identity-service"
 	// DefaultClusterIdentifier is used only if there is no value in env variable
 	DefaultClusterIdentifier = "cluster"
 	// Project mocked value
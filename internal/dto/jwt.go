@@ -17,3 +17,16 @@ type JWTCustomClaims struct {
 	KeyType                string `json:"key_type"`
 	jwt.StandardClaims
 }
+
+// IdentityServiceClaims contains fields of tokens issued by the identity service,
+// our gateway uses them instead of api key tokens
+type IdentityServiceClaims struct {
+	Type                  string `json:"type"`
+	Name                  string `json:"name,omitempty"`
+	Email                 string `json:"email,omitempty"`
+	Username              string `json:"username,omitempty"`
+	AccountID             string `json:"accountId"`
+	Environment           string `json:"environment,omitempty"`
+	EnvironmentIdentifier string `json:"environmentIdentifier,omitempty"`
+	jwt.StandardClaims
+}
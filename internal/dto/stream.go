@@ -0,0 +1,19 @@
+package dto
+
+// Stream event types and domains sent to SDKs when flags or target groups change
+const (
+	StreamEventPatch  = "patch"
+	StreamEventCreate = "create"
+	StreamEventDelete = "delete"
+
+	StreamDomainFlag          = "flag"
+	StreamDomainTargetSegment = "target-segment"
+)
+
+// StreamEvent is the data of SSE messages, SDKs reload the changed item
+type StreamEvent struct {
+	Event      string `json:"event"`
+	Domain     string `json:"domain"`
+	Identifier string `json:"identifier"`
+	Version    int64  `json:"version"`
+}
@@ -0,0 +1,253 @@
+package evaluation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/drone/ff-mock-server/internal/repository"
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+// Clause operators supported in rules and segments
+const (
+	segmentMatchOperator   = "segmentMatch"
+	inOperator             = "in"
+	equalOperator          = "equal"
+	equalSensitiveOperator = "equal_sensitive"
+	startsWithOperator     = "starts_with"
+	endsWithOperator       = "ends_with"
+	containsOperator       = "contains"
+)
+
+// maxPrerequisiteDepth stops evaluation of prerequisites which form a cycle
+const maxPrerequisiteDepth = 10
+
+// Evaluator evaluates flags of the repository for targets, only the target
+// identifier is known to the server so clauses on other attributes never match
+type Evaluator struct {
+	repo repository.Repository
+}
+
+// NewEvaluator returns new Evaluator of flags and segments in repo
+func NewEvaluator(repo repository.Repository) *Evaluator {
+	return &Evaluator{
+		repo: repo,
+	}
+}
+
+// Evaluate returns evaluation of the flag for the target, the value is the
+// value of served variation as declared for the flag kind
+func (e *Evaluator) Evaluate(fc api.FeatureConfig, target api.Target) api.Evaluation {
+	identifier := fc.Feature
+	return api.Evaluation{
+		Flag:       fc.Feature,
+		Identifier: &identifier,
+		Kind:       string(fc.Kind),
+		Value:      variationValue(fc, e.variation(fc, target, 0)),
+	}
+}
+
+// EvaluateAll returns evaluations of all flags for the target
+func (e *Evaluator) EvaluateAll(target api.Target) api.Evaluations {
+	flags := e.repo.GetFlagConfigurations()
+	evaluations := make(api.Evaluations, 0, len(flags))
+	for _, fc := range flags {
+		evaluations = append(evaluations, e.Evaluate(fc, target))
+	}
+	return evaluations
+}
+
+// variation returns identifier of the variation served to the target
+func (e *Evaluator) variation(fc api.FeatureConfig, target api.Target, depth int) string {
+	if fc.State != api.FeatureStateOn || !e.prerequisitesMatch(fc, target, depth) {
+		return fc.OffVariation
+	}
+
+	if fc.VariationToTargetMap != nil {
+		for _, variationMap := range *fc.VariationToTargetMap {
+			if variationMap.Targets != nil {
+				for _, t := range *variationMap.Targets {
+					if t.Identifier == target.Identifier {
+						return variationMap.Variation
+					}
+				}
+			}
+			if variationMap.TargetSegments != nil && e.inAnySegment(*variationMap.TargetSegments, target) {
+				return variationMap.Variation
+			}
+		}
+	}
+
+	if fc.Rules != nil {
+		rules := make([]api.ServingRule, len(*fc.Rules))
+		copy(rules, *fc.Rules)
+		sort.SliceStable(rules, func(i, j int) bool {
+			return rules[i].Priority < rules[j].Priority
+		})
+		for _, rule := range rules {
+			if e.clausesMatch(rule.Clauses, target) {
+				return serve(fc, rule.Serve, target)
+			}
+		}
+	}
+	return serve(fc, fc.DefaultServe, target)
+}
+
+// prerequisitesMatch checks that every prerequisite flag serves one of the
+// required variations to the target
+func (e *Evaluator) prerequisitesMatch(fc api.FeatureConfig, target api.Target, depth int) bool {
+	if fc.Prerequisites == nil {
+		return true
+	}
+	if depth >= maxPrerequisiteDepth {
+		return false
+	}
+	for _, prerequisite := range *fc.Prerequisites {
+		parent, ok := e.repo.GetFlagConfiguration(prerequisite.Feature)
+		if !ok {
+			return false
+		}
+		if !contains(prerequisite.Variations, e.variation(parent, target, depth+1)) {
+			return false
+		}
+	}
+	return true
+}
+
+// clausesMatch checks that all clauses of a rule match the target
+func (e *Evaluator) clausesMatch(clauses []api.Clause, target api.Target) bool {
+	for _, clause := range clauses {
+		if !e.clauseMatches(clause, target) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Evaluator) clauseMatches(clause api.Clause, target api.Target) bool {
+	if clause.Op == segmentMatchOperator {
+		return e.inAnySegment(clause.Values, target) != clause.Negate
+	}
+
+	value, ok := attribute(target, clause.Attribute)
+	if !ok {
+		return false
+	}
+	var matches bool
+	for _, v := range clause.Values {
+		switch clause.Op {
+		case inOperator, equalOperator:
+			matches = strings.EqualFold(value, v)
+		case equalSensitiveOperator:
+			matches = value == v
+		case startsWithOperator:
+			matches = strings.HasPrefix(value, v)
+		case endsWithOperator:
+			matches = strings.HasSuffix(value, v)
+		case containsOperator:
+			matches = strings.Contains(value, v)
+		}
+		if matches {
+			break
+		}
+	}
+	return matches != clause.Negate
+}
+
+// inAnySegment checks if the target is member of any of the segments, excluded
+// targets are never members and any matching rule makes the target a member
+func (e *Evaluator) inAnySegment(identifiers []string, target api.Target) bool {
+	for _, identifier := range identifiers {
+		segment, ok := e.repo.GetTargetGroup(identifier)
+		if !ok {
+			continue
+		}
+		if segment.Excluded != nil && containsTarget(*segment.Excluded, target) {
+			continue
+		}
+		if segment.Included != nil && containsTarget(*segment.Included, target) {
+			return true
+		}
+		if segment.Rules != nil {
+			for _, clause := range *segment.Rules {
+				// nested segment matches are not evaluated to avoid cycles
+				if clause.Op != segmentMatchOperator && e.clauseMatches(clause, target) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// serve returns variation served by a rule, percentage rollouts bucket targets
+// by hash of the bucketBy attribute and flag identifier
+func serve(fc api.FeatureConfig, s api.Serve, target api.Target) string {
+	if s.Variation != nil {
+		return *s.Variation
+	}
+	if s.Distribution == nil || len(s.Distribution.Variations) == 0 {
+		return fc.OffVariation
+	}
+
+	value, ok := attribute(target, s.Distribution.BucketBy)
+	if !ok {
+		value, _ = attribute(target, "identifier")
+	}
+	bucket := int(murmur3([]byte(fmt.Sprintf("%s:%s", value, fc.Feature)))%100) + 1
+
+	total := 0
+	for _, wv := range s.Distribution.Variations {
+		total += wv.Weight
+		if bucket <= total {
+			return wv.Variation
+		}
+	}
+	return s.Distribution.Variations[len(s.Distribution.Variations)-1].Variation
+}
+
+// attribute returns value of the target attribute used in clauses
+func attribute(target api.Target, name string) (string, bool) {
+	switch name {
+	case "identifier":
+		return target.Identifier, true
+	case "name":
+		return target.Name, true
+	}
+	if target.Attributes == nil {
+		return "", false
+	}
+	value, ok := (*target.Attributes)[name]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+func variationValue(fc api.FeatureConfig, identifier string) string {
+	for _, variation := range fc.Variations {
+		if variation.Identifier == identifier {
+			return variation.Value
+		}
+	}
+	return ""
+}
+
+func containsTarget(targets []api.Target, target api.Target) bool {
+	for _, t := range targets {
+		if t.Identifier == target.Identifier {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,396 @@
+package evaluation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/drone/ff-mock-server/internal/repository"
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+func TestMurmur3(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint32
+	}{
+		{"", 0},
+		{"a", 0x3c2569b2},
+		{"abc", 0xb3dd93fa},
+		{"test", 0xba6bd213},
+		{"hello", 0x248bfa47},
+		{"Hello, world!", 0xc0363e43},
+		{"The quick brown fox jumps over the lazy dog", 0x2e4ff723},
+	}
+	for _, tt := range tests {
+		if got := murmur3([]byte(tt.data)); got != tt.want {
+			t.Errorf("murmur3(%q) = %#08x, want %#08x", tt.data, got, tt.want)
+		}
+	}
+}
+
+// boolFlag returns flag which is on and serves false by default
+func boolFlag(feature string) api.FeatureConfig {
+	variation := "false"
+	return api.FeatureConfig{
+		Feature: feature,
+		Kind:    api.FeatureConfigKindBoolean,
+		State:   api.FeatureStateOn,
+		Variations: []api.Variation{
+			{Identifier: "true", Value: "true"},
+			{Identifier: "false", Value: "false"},
+		},
+		OffVariation: "false",
+		DefaultServe: api.Serve{Variation: &variation},
+	}
+}
+
+func serveVariation(variation string) api.Serve {
+	return api.Serve{Variation: &variation}
+}
+
+func target(identifier string, attributes map[string]interface{}) api.Target {
+	t := api.Target{Identifier: identifier, Name: "Target " + identifier}
+	if attributes != nil {
+		t.Attributes = &attributes
+	}
+	return t
+}
+
+func newEvaluator(t *testing.T, flags []api.FeatureConfig, segments []api.Segment) *Evaluator {
+	t.Helper()
+	repo, err := repository.NewRepository(repository.Dataset{Flags: flags, Segments: segments})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewEvaluator(repo)
+}
+
+func TestEvaluateKinds(t *testing.T) {
+	tests := []struct {
+		kind  api.FeatureConfigKind
+		value string
+	}{
+		{api.FeatureConfigKindBoolean, "true"},
+		{api.FeatureConfigKindString, "blue"},
+		{api.FeatureConfigKindInt, "42"},
+		{api.FeatureConfigKindNumber, "1.5"},
+		{api.FeatureConfigKindJson, `{"enabled":true}`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			fc := api.FeatureConfig{
+				Feature: "flag",
+				Kind:    tt.kind,
+				State:   api.FeatureStateOn,
+				Variations: []api.Variation{
+					{Identifier: "served", Value: tt.value},
+					{Identifier: "off", Value: map[api.FeatureConfigKind]string{
+						api.FeatureConfigKindBoolean: "false",
+						api.FeatureConfigKindString:  "red",
+						api.FeatureConfigKindInt:     "0",
+						api.FeatureConfigKindNumber:  "0",
+						api.FeatureConfigKindJson:    "{}",
+					}[tt.kind]},
+				},
+				OffVariation: "off",
+				DefaultServe: serveVariation("served"),
+			}
+			evaluation := newEvaluator(t, []api.FeatureConfig{fc}, nil).Evaluate(fc, target("alice", nil))
+			if evaluation.Flag != "flag" || evaluation.Kind != string(tt.kind) || evaluation.Value != tt.value {
+				t.Errorf("got %s %s %s, want flag %s %s", evaluation.Flag, evaluation.Kind, evaluation.Value, tt.kind, tt.value)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	included := []api.Target{{Identifier: "alice"}}
+	excluded := []api.Target{{Identifier: "bob"}}
+	segments := []api.Segment{
+		{Identifier: "beta", Included: &included, Excluded: &excluded, Rules: &[]api.Clause{
+			{Attribute: "plan", Op: equalOperator, Values: []string{"enterprise"}},
+		}},
+	}
+
+	tests := []struct {
+		name   string
+		flag   func(fc *api.FeatureConfig)
+		target api.Target
+		want   string
+	}{
+		{
+			name:   "default serve",
+			flag:   func(fc *api.FeatureConfig) {},
+			target: target("alice", nil),
+			want:   "false",
+		},
+		{
+			name: "off state serves off variation",
+			flag: func(fc *api.FeatureConfig) {
+				fc.State = api.FeatureStateOff
+				fc.OffVariation = "true"
+			},
+			target: target("alice", nil),
+			want:   "true",
+		},
+		{
+			name: "target map",
+			flag: func(fc *api.FeatureConfig) {
+				fc.VariationToTargetMap = &[]api.VariationMap{
+					{Variation: "true", Targets: &[]api.TargetMap{{Identifier: "alice"}}},
+				}
+			},
+			target: target("alice", nil),
+			want:   "true",
+		},
+		{
+			name: "target map of other target",
+			flag: func(fc *api.FeatureConfig) {
+				fc.VariationToTargetMap = &[]api.VariationMap{
+					{Variation: "true", Targets: &[]api.TargetMap{{Identifier: "alice"}}},
+				}
+			},
+			target: target("carol", nil),
+			want:   "false",
+		},
+		{
+			name: "target segment with included target",
+			flag: func(fc *api.FeatureConfig) {
+				fc.VariationToTargetMap = &[]api.VariationMap{{Variation: "true", TargetSegments: &[]string{"beta"}}}
+			},
+			target: target("alice", nil),
+			want:   "true",
+		},
+		{
+			name: "target segment with excluded target matching rule",
+			flag: func(fc *api.FeatureConfig) {
+				fc.VariationToTargetMap = &[]api.VariationMap{{Variation: "true", TargetSegments: &[]string{"beta"}}}
+			},
+			target: target("bob", map[string]interface{}{"plan": "enterprise"}),
+			want:   "false",
+		},
+		{
+			name: "segment rule",
+			flag: func(fc *api.FeatureConfig) {
+				fc.Rules = &[]api.ServingRule{{Priority: 1, Serve: serveVariation("true"), Clauses: []api.Clause{
+					{Op: segmentMatchOperator, Values: []string{"beta"}},
+				}}}
+			},
+			target: target("carol", map[string]interface{}{"plan": "enterprise"}),
+			want:   "true",
+		},
+		{
+			name: "negated segment rule",
+			flag: func(fc *api.FeatureConfig) {
+				fc.Rules = &[]api.ServingRule{{Priority: 1, Serve: serveVariation("true"), Clauses: []api.Clause{
+					{Op: segmentMatchOperator, Values: []string{"beta"}, Negate: true},
+				}}}
+			},
+			target: target("carol", nil),
+			want:   "true",
+		},
+		{
+			name: "unknown segment",
+			flag: func(fc *api.FeatureConfig) {
+				fc.Rules = &[]api.ServingRule{{Priority: 1, Serve: serveVariation("true"), Clauses: []api.Clause{
+					{Op: segmentMatchOperator, Values: []string{"missing"}},
+				}}}
+			},
+			target: target("alice", nil),
+			want:   "false",
+		},
+		{
+			name: "rules are evaluated by priority",
+			flag: func(fc *api.FeatureConfig) {
+				fc.Rules = &[]api.ServingRule{
+					{Priority: 2, Serve: serveVariation("false"), Clauses: []api.Clause{
+						{Attribute: "identifier", Op: equalOperator, Values: []string{"alice"}},
+					}},
+					{Priority: 1, Serve: serveVariation("true"), Clauses: []api.Clause{
+						{Attribute: "identifier", Op: startsWithOperator, Values: []string{"al"}},
+					}},
+				}
+				fc.DefaultServe = serveVariation("false")
+			},
+			target: target("alice", nil),
+			want:   "true",
+		},
+		{
+			name: "all clauses of a rule must match",
+			flag: func(fc *api.FeatureConfig) {
+				fc.Rules = &[]api.ServingRule{{Priority: 1, Serve: serveVariation("true"), Clauses: []api.Clause{
+					{Attribute: "identifier", Op: equalOperator, Values: []string{"alice"}},
+					{Attribute: "country", Op: equalOperator, Values: []string{"uk"}},
+				}}}
+			},
+			target: target("alice", map[string]interface{}{"country": "us"}),
+			want:   "false",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := boolFlag("flag")
+			tt.flag(&fc)
+			evaluator := newEvaluator(t, []api.FeatureConfig{fc}, segments)
+			if got := evaluator.variation(fc, tt.target, 0); got != tt.want {
+				t.Errorf("got variation %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClauseOperators(t *testing.T) {
+	alice := target("Alice", map[string]interface{}{"email": "alice@harness.io", "age": 30})
+	tests := []struct {
+		name   string
+		clause api.Clause
+		want   bool
+	}{
+		{"in", api.Clause{Attribute: "identifier", Op: inOperator, Values: []string{"bob", "alice"}}, true},
+		{"in none", api.Clause{Attribute: "identifier", Op: inOperator, Values: []string{"bob", "carol"}}, false},
+		{"equal ignores case", api.Clause{Attribute: "identifier", Op: equalOperator, Values: []string{"alice"}}, true},
+		{"equal sensitive", api.Clause{Attribute: "identifier", Op: equalSensitiveOperator, Values: []string{"alice"}}, false},
+		{"equal sensitive same case", api.Clause{Attribute: "identifier", Op: equalSensitiveOperator, Values: []string{"Alice"}}, true},
+		{"starts with", api.Clause{Attribute: "email", Op: startsWithOperator, Values: []string{"alice@"}}, true},
+		{"ends with", api.Clause{Attribute: "email", Op: endsWithOperator, Values: []string{"@harness.io"}}, true},
+		{"ends with other", api.Clause{Attribute: "email", Op: endsWithOperator, Values: []string{"@example.com"}}, false},
+		{"contains", api.Clause{Attribute: "email", Op: containsOperator, Values: []string{"harness"}}, true},
+		{"name attribute", api.Clause{Attribute: "name", Op: equalOperator, Values: []string{"target alice"}}, true},
+		{"number attribute", api.Clause{Attribute: "age", Op: equalOperator, Values: []string{"30"}}, true},
+		{"negated", api.Clause{Attribute: "identifier", Op: equalOperator, Values: []string{"alice"}, Negate: true}, false},
+		{"negated mismatch", api.Clause{Attribute: "identifier", Op: equalOperator, Values: []string{"bob"}, Negate: true}, true},
+		{"missing attribute", api.Clause{Attribute: "country", Op: equalOperator, Values: []string{"uk"}}, false},
+		{"negated missing attribute", api.Clause{Attribute: "country", Op: equalOperator, Values: []string{"uk"}, Negate: true}, false},
+		{"unknown operator", api.Clause{Attribute: "identifier", Op: "greater_than", Values: []string{"a"}}, false},
+	}
+	evaluator := newEvaluator(t, nil, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluator.clauseMatches(tt.clause, alice); got != tt.want {
+				t.Errorf("clauseMatches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrerequisites(t *testing.T) {
+	parent := boolFlag("parent")
+	parent.VariationToTargetMap = &[]api.VariationMap{
+		{Variation: "true", Targets: &[]api.TargetMap{{Identifier: "alice"}}},
+	}
+	child := boolFlag("child")
+	child.DefaultServe = serveVariation("true")
+	child.Prerequisites = &[]api.Prerequisite{{Feature: "parent", Variations: []string{"true"}}}
+	orphan := boolFlag("orphan")
+	orphan.DefaultServe = serveVariation("true")
+	orphan.Prerequisites = &[]api.Prerequisite{{Feature: "missing", Variations: []string{"true"}}}
+	cycleA := boolFlag("cycle-a")
+	cycleA.DefaultServe = serveVariation("true")
+	cycleA.Prerequisites = &[]api.Prerequisite{{Feature: "cycle-b", Variations: []string{"true"}}}
+	cycleB := boolFlag("cycle-b")
+	cycleB.DefaultServe = serveVariation("true")
+	cycleB.Prerequisites = &[]api.Prerequisite{{Feature: "cycle-a", Variations: []string{"true"}}}
+	evaluator := newEvaluator(t, []api.FeatureConfig{parent, child, orphan, cycleA, cycleB}, nil)
+
+	tests := []struct {
+		name   string
+		flag   api.FeatureConfig
+		target string
+		want   string
+	}{
+		{"prerequisite served required variation", child, "alice", "true"},
+		{"prerequisite served other variation", child, "bob", "false"},
+		{"missing prerequisite", orphan, "alice", "false"},
+		{"prerequisites forming a cycle", cycleA, "alice", "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluator.variation(tt.flag, target(tt.target, nil), 0); got != tt.want {
+				t.Errorf("got variation %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	distribution := func(trueWeight int, bucketBy string) api.Serve {
+		return api.Serve{Distribution: &api.Distribution{
+			BucketBy: bucketBy,
+			Variations: []api.WeightedVariation{
+				{Variation: "true", Weight: trueWeight},
+				{Variation: "false", Weight: 100 - trueWeight},
+			},
+		}}
+	}
+	served := func(fc api.FeatureConfig, targets int) int {
+		count := 0
+		for i := 0; i < targets; i++ {
+			if serve(fc, fc.DefaultServe, target(fmt.Sprintf("target-%d", i), nil)) == "true" {
+				count++
+			}
+		}
+		return count
+	}
+
+	tests := []struct {
+		name     string
+		weight   int
+		min, max int
+	}{
+		{"all", 100, 1000, 1000},
+		{"none", 0, 0, 0},
+		{"half", 50, 450, 550},
+		{"tenth", 10, 60, 140},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := boolFlag("flag")
+			fc.DefaultServe = distribution(tt.weight, "identifier")
+			if got := served(fc, 1000); got < tt.min || got > tt.max {
+				t.Errorf("true served to %d of 1000 targets, want %d to %d", got, tt.min, tt.max)
+			}
+		})
+	}
+
+	t.Run("bucket by attribute", func(t *testing.T) {
+		fc := boolFlag("flag")
+		fc.DefaultServe = distribution(50, "email")
+		for i := 0; i < 100; i++ {
+			email := map[string]interface{}{"email": fmt.Sprintf("user-%d@harness.io", i)}
+			first := serve(fc, fc.DefaultServe, target("a", email))
+			if second := serve(fc, fc.DefaultServe, target("b", email)); first != second {
+				t.Fatalf("targets with same email got %s and %s", first, second)
+			}
+		}
+	})
+
+	t.Run("missing bucket by attribute falls back to identifier", func(t *testing.T) {
+		byEmail := boolFlag("flag")
+		byEmail.DefaultServe = distribution(50, "email")
+		byIdentifier := boolFlag("flag")
+		byIdentifier.DefaultServe = distribution(50, "identifier")
+		for i := 0; i < 100; i++ {
+			tgt := target(fmt.Sprintf("target-%d", i), nil)
+			if serve(byEmail, byEmail.DefaultServe, tgt) != serve(byIdentifier, byIdentifier.DefaultServe, tgt) {
+				t.Fatalf("target %s is bucketed differently without email", tgt.Identifier)
+			}
+		}
+	})
+
+	t.Run("buckets differ by flag", func(t *testing.T) {
+		a := boolFlag("flag-a")
+		a.DefaultServe = distribution(50, "identifier")
+		b := boolFlag("flag-b")
+		b.DefaultServe = distribution(50, "identifier")
+		same := 0
+		for i := 0; i < 1000; i++ {
+			tgt := target(fmt.Sprintf("target-%d", i), nil)
+			if serve(a, a.DefaultServe, tgt) == serve(b, b.DefaultServe, tgt) {
+				same++
+			}
+		}
+		if same > 600 {
+			t.Errorf("%d of 1000 targets got same variation of both flags", same)
+		}
+	})
+}
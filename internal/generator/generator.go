@@ -0,0 +1,315 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/repository"
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+// Clause operators used in generated rules
+const (
+	segmentMatchOperator = "segmentMatch"
+	inOperator           = "in"
+	equalOperator        = "equal"
+	startsWithOperator   = "starts_with"
+	endsWithOperator     = "ends_with"
+	containsOperator     = "contains"
+)
+
+var (
+	attributes = []string{"identifier", "name", "email", "country", "plan", "version"}
+	operators  = []string{inOperator, equalOperator, startsWithOperator, endsWithOperator, containsOperator}
+	values     = []string{"harness", "beta", "enterprise", "free", "us", "eu", "uk", "@harness.io", "v1", "v2"}
+)
+
+// Options controls size and complexity of the generated dataset
+type Options struct {
+	// Seed makes the dataset reproducible, same options generate same dataset
+	Seed int64
+	// Flags is number of generated flags
+	Flags int
+	// Segments is number of generated segments
+	Segments int
+	// Targets is number of distinct targets referenced by segments and target maps
+	Targets int
+	// Rules is maximum number of serving rules of a flag
+	Rules int
+}
+
+// Validate checks the numbers of generated items are not negative
+func (o Options) Validate() error {
+	if o.Flags < 0 || o.Segments < 0 || o.Targets < 0 || o.Rules < 0 {
+		return errors.New("numbers of flags, segments, targets and rules must not be negative")
+	}
+	return nil
+}
+
+// generator keeps random source of a single Generate call
+type generator struct {
+	rnd     *rand.Rand
+	options Options
+	version int64
+	flags   []api.FeatureConfig
+}
+
+// Generate returns dataset with flags of mixed kinds, segments with included and
+// excluded targets, rules and percentage rollouts derived from options.Seed
+func Generate(options Options) repository.Dataset {
+	g := &generator{
+		rnd:     rand.New(rand.NewSource(options.Seed)), // #nosec G404 reproducible datasets need a seeded source
+		options: options,
+		version: 1,
+	}
+
+	segments := make([]api.Segment, 0, options.Segments)
+	for i := 0; i < options.Segments; i++ {
+		segments = append(segments, g.segment(i))
+	}
+	g.flags = make([]api.FeatureConfig, 0, options.Flags)
+	for i := 0; i < options.Flags; i++ {
+		g.flags = append(g.flags, g.flag(i))
+	}
+	return repository.Dataset{
+		Flags:    g.flags,
+		Segments: segments,
+	}
+}
+
+func (g *generator) segment(i int) api.Segment {
+	env := internal.Environment
+	segment := api.Segment{
+		Environment: &env,
+		Identifier:  segmentIdentifier(i),
+		Name:        fmt.Sprintf("Segment %d", i),
+		Version:     &g.version,
+	}
+	if included := g.targets(10); len(included) > 0 {
+		segment.Included = &included
+	}
+	if excluded := g.targets(3); len(excluded) > 0 {
+		segment.Excluded = &excluded
+	}
+	if n := g.rnd.Intn(4); n > 0 {
+		rules := make([]api.Clause, 0, n)
+		for j := 0; j < n; j++ {
+			rules = append(rules, g.clause(false))
+		}
+		segment.Rules = &rules
+	}
+	return segment
+}
+
+func (g *generator) flag(i int) api.FeatureConfig {
+	kind, variations := g.variations()
+	fc := api.FeatureConfig{
+		Project:      internal.Project,
+		Environment:  internal.Environment,
+		Feature:      fmt.Sprintf("flag-%d", i),
+		Kind:         kind,
+		State:        api.FeatureStateOn,
+		Variations:   variations,
+		OffVariation: variations[len(variations)-1].Identifier,
+		DefaultServe: g.serve(variations, 20),
+		Version:      &g.version,
+	}
+	if g.rnd.Intn(5) == 0 {
+		fc.State = api.FeatureStateOff
+	}
+
+	if n := g.rnd.Intn(g.options.Rules + 1); n > 0 {
+		rules := make([]api.ServingRule, 0, n)
+		for j := 0; j < n; j++ {
+			rules = append(rules, api.ServingRule{
+				RuleId:   g.uuid(),
+				Priority: j + 1,
+				Clauses:  g.clauses(),
+				Serve:    g.serve(variations, 30),
+			})
+		}
+		fc.Rules = &rules
+	}
+
+	if g.rnd.Intn(10) < 3 {
+		targetMap := g.variationToTargetMap(variations)
+		fc.VariationToTargetMap = &targetMap
+	}
+
+	// prerequisites only reference earlier flags so they never form a cycle
+	if i > 0 && g.rnd.Intn(10) == 0 {
+		prerequisite := g.flags[g.rnd.Intn(i)]
+		fc.Prerequisites = &[]api.Prerequisite{{
+			Feature:    prerequisite.Feature,
+			Variations: []string{prerequisite.Variations[0].Identifier},
+		}}
+	}
+	return fc
+}
+
+// variations returns kind and variations of a flag, half of the flags are boolean
+func (g *generator) variations() (api.FeatureConfigKind, []api.Variation) {
+	switch n := g.rnd.Intn(20); {
+	case n < 10:
+		return api.FeatureConfigKindBoolean, []api.Variation{
+			{Identifier: "true", Value: "true"},
+			{Identifier: "false", Value: "false"},
+		}
+	case n < 13:
+		return api.FeatureConfigKindString, g.nVariations(func(j int) string {
+			return fmt.Sprintf("variant-%c", 'a'+j)
+		})
+	case n < 15:
+		return api.FeatureConfigKindInt, g.nVariations(func(j int) string {
+			return strconv.Itoa((j + 1) * 10)
+		})
+	case n < 17:
+		return api.FeatureConfigKindNumber, g.nVariations(func(j int) string {
+			return strconv.FormatFloat(float64(j)+0.5, 'f', -1, 64)
+		})
+	default:
+		return api.FeatureConfigKindJson, g.nVariations(func(j int) string {
+			return fmt.Sprintf(`{"variant":%d,"enabled":%t}`, j, j%2 == 0)
+		})
+	}
+}
+
+// nVariations returns between 2 and 5 variations with values returned by value
+func (g *generator) nVariations(value func(j int) string) []api.Variation {
+	n := 2 + g.rnd.Intn(4)
+	variations := make([]api.Variation, 0, n)
+	for j := 0; j < n; j++ {
+		variations = append(variations, api.Variation{
+			Identifier: fmt.Sprintf("variation-%d", j),
+			Value:      value(j),
+		})
+	}
+	return variations
+}
+
+// serve returns a single variation or, with distributionPercent chance,
+// a percentage rollout across all variations
+func (g *generator) serve(variations []api.Variation, distributionPercent int) api.Serve {
+	if g.rnd.Intn(100) >= distributionPercent {
+		variation := variations[g.rnd.Intn(len(variations))].Identifier
+		return api.Serve{Variation: &variation}
+	}
+
+	weighted := make([]api.WeightedVariation, 0, len(variations))
+	remaining := 100
+	for j, variation := range variations {
+		weight := remaining
+		if j < len(variations)-1 {
+			weight = g.rnd.Intn(remaining + 1)
+		}
+		remaining -= weight
+		weighted = append(weighted, api.WeightedVariation{
+			Variation: variation.Identifier,
+			Weight:    weight,
+		})
+	}
+	return api.Serve{Distribution: &api.Distribution{
+		BucketBy:   "identifier",
+		Variations: weighted,
+	}}
+}
+
+func (g *generator) clauses() []api.Clause {
+	n := 1 + g.rnd.Intn(3)
+	clauses := make([]api.Clause, 0, n)
+	for j := 0; j < n; j++ {
+		clauses = append(clauses, g.clause(g.options.Segments > 0 && g.rnd.Intn(4) == 0))
+	}
+	return clauses
+}
+
+func (g *generator) clause(segmentMatch bool) api.Clause {
+	if segmentMatch {
+		return api.Clause{
+			Id:     g.uuid(),
+			Op:     segmentMatchOperator,
+			Values: []string{segmentIdentifier(g.rnd.Intn(g.options.Segments))},
+		}
+	}
+
+	op := operators[g.rnd.Intn(len(operators))]
+	n := 1
+	if op == inOperator {
+		n = 1 + g.rnd.Intn(4)
+	}
+	clauseValues := make([]string, 0, n)
+	for j := 0; j < n; j++ {
+		clauseValues = append(clauseValues, values[g.rnd.Intn(len(values))])
+	}
+	return api.Clause{
+		Id:        g.uuid(),
+		Attribute: attributes[g.rnd.Intn(len(attributes))],
+		Op:        op,
+		Negate:    g.rnd.Intn(10) == 0,
+		Values:    clauseValues,
+	}
+}
+
+func (g *generator) variationToTargetMap(variations []api.Variation) []api.VariationMap {
+	n := 1 + g.rnd.Intn(len(variations))
+	result := make([]api.VariationMap, 0, n)
+	for j := 0; j < n; j++ {
+		variationMap := api.VariationMap{Variation: variations[j].Identifier}
+		targets := g.targets(5)
+		if len(targets) > 0 {
+			targetMaps := make([]api.TargetMap, 0, len(targets))
+			for _, target := range targets {
+				targetMaps = append(targetMaps, api.TargetMap{
+					Identifier: target.Identifier,
+					Name:       target.Name,
+				})
+			}
+			variationMap.Targets = &targetMaps
+		}
+		if g.options.Segments > 0 && g.rnd.Intn(2) == 0 {
+			variationMap.TargetSegments = &[]string{segmentIdentifier(g.rnd.Intn(g.options.Segments))}
+		}
+		result = append(result, variationMap)
+	}
+	return result
+}
+
+// targets returns up to max distinct targets picked from the pool of options.Targets targets
+func (g *generator) targets(max int) []api.Target {
+	if g.options.Targets == 0 {
+		return nil
+	}
+	n := g.rnd.Intn(max + 1)
+	picked := make(map[int]bool, n)
+	targets := make([]api.Target, 0, n)
+	for j := 0; j < n; j++ {
+		id := g.rnd.Intn(g.options.Targets)
+		if picked[id] {
+			continue
+		}
+		picked[id] = true
+		targets = append(targets, api.Target{
+			Identifier:  fmt.Sprintf("target-%d", id),
+			Name:        fmt.Sprintf("Target %d", id),
+			Project:     internal.Project,
+			Environment: internal.Environment,
+		})
+	}
+	return targets
+}
+
+// uuid returns UUID v4 formatted id taken from the seeded source
+func (g *generator) uuid() string {
+	b := make([]byte, 16)
+	_, _ = g.rnd.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func segmentIdentifier(i int) string {
+	return fmt.Sprintf("segment-%d", i)
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drone/ff-mock-server/internal/repository"
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+	}{
+		{name: "zero", options: Options{}},
+		{name: "positive", options: Options{Flags: 10, Segments: 2, Targets: 5, Rules: 3}},
+		{name: "negative flags", options: Options{Flags: -1}, wantErr: true},
+		{name: "negative segments", options: Options{Segments: -1}, wantErr: true},
+		{name: "negative targets", options: Options{Targets: -1}, wantErr: true},
+		{name: "negative rules", options: Options{Rules: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	options := Options{Seed: 42, Flags: 50, Segments: 10, Targets: 100, Rules: 5}
+	if !reflect.DeepEqual(Generate(options), Generate(options)) {
+		t.Error("same options generated different datasets")
+	}
+	other := options
+	other.Seed++
+	if reflect.DeepEqual(Generate(options), Generate(other)) {
+		t.Error("different seeds generated same dataset")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{name: "empty", options: Options{Seed: 1}},
+		{name: "flags only", options: Options{Seed: 1, Flags: 200, Targets: 10, Rules: 3}},
+		{name: "no targets", options: Options{Seed: 2, Flags: 100, Segments: 10, Rules: 5}},
+		{name: "no rules", options: Options{Seed: 3, Flags: 100, Segments: 10, Targets: 50}},
+		{name: "mixed", options: Options{Seed: 4, Flags: 500, Segments: 50, Targets: 1000, Rules: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataset := Generate(tt.options)
+			if len(dataset.Flags) != tt.options.Flags || len(dataset.Segments) != tt.options.Segments {
+				t.Fatalf("got %d flags and %d segments, want %d and %d",
+					len(dataset.Flags), len(dataset.Segments), tt.options.Flags, tt.options.Segments)
+			}
+			if _, err := repository.NewRepository(dataset); err != nil {
+				t.Fatalf("generated dataset is invalid: %s", err)
+			}
+
+			segments := make(map[string]bool)
+			for _, segment := range dataset.Segments {
+				segments[segment.Identifier] = true
+				checkTargets(t, segment.Included, tt.options.Targets)
+				checkTargets(t, segment.Excluded, tt.options.Targets)
+			}
+			flags := make(map[string]bool)
+			for _, fc := range dataset.Flags {
+				checkFlag(t, fc, tt.options, segments, flags)
+				flags[fc.Feature] = true
+			}
+		})
+	}
+}
+
+// checkFlag checks the flag only references generated segments and earlier flags,
+// and its rules and distributions are within bounds
+func checkFlag(t *testing.T, fc api.FeatureConfig, options Options, segments, earlier map[string]bool) {
+	t.Helper()
+	if fc.Rules != nil && len(*fc.Rules) > options.Rules {
+		t.Errorf("flag %s has %d rules, want at most %d", fc.Feature, len(*fc.Rules), options.Rules)
+	}
+	checkServe(t, fc.Feature, fc.DefaultServe)
+	if fc.Rules != nil {
+		for _, rule := range *fc.Rules {
+			checkServe(t, fc.Feature, rule.Serve)
+			for _, clause := range rule.Clauses {
+				if clause.Op == segmentMatchOperator && !segments[clause.Values[0]] {
+					t.Errorf("flag %s matches unknown segment %s", fc.Feature, clause.Values[0])
+				}
+			}
+		}
+	}
+	if fc.VariationToTargetMap != nil {
+		for _, variationMap := range *fc.VariationToTargetMap {
+			if variationMap.TargetSegments != nil {
+				for _, segment := range *variationMap.TargetSegments {
+					if !segments[segment] {
+						t.Errorf("flag %s targets unknown segment %s", fc.Feature, segment)
+					}
+				}
+			}
+		}
+	}
+	if fc.Prerequisites != nil {
+		for _, prerequisite := range *fc.Prerequisites {
+			if !earlier[prerequisite.Feature] {
+				t.Errorf("flag %s has prerequisite %s which is not an earlier flag", fc.Feature, prerequisite.Feature)
+			}
+		}
+	}
+}
+
+// checkServe checks weights of distributions add up to 100
+func checkServe(t *testing.T, feature string, serve api.Serve) {
+	t.Helper()
+	if serve.Distribution == nil {
+		if serve.Variation == nil {
+			t.Errorf("flag %s serves neither variation nor distribution", feature)
+		}
+		return
+	}
+	total := 0
+	for _, variation := range serve.Distribution.Variations {
+		total += variation.Weight
+	}
+	if total != 100 {
+		t.Errorf("weights of flag %s distribution add up to %d", feature, total)
+	}
+}
+
+// checkTargets checks targets are distinct and picked from the pool
+func checkTargets(t *testing.T, targets *[]api.Target, pool int) {
+	t.Helper()
+	if targets == nil {
+		return
+	}
+	if pool == 0 {
+		t.Errorf("got %d targets without target pool", len(*targets))
+	}
+	seen := make(map[string]bool)
+	for _, target := range *targets {
+		if seen[target.Identifier] || !strings.HasPrefix(target.Identifier, "target-") {
+			t.Errorf("target %s is duplicate or not from the pool", target.Identifier)
+		}
+		seen[target.Identifier] = true
+	}
+}
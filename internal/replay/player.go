@@ -0,0 +1,175 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+)
+
+// Player serves recorded session entries in order, respecting their
+// relative timing scaled by speed
+type Player struct {
+	clock     *clock.Clock
+	speed     float64
+	mu        sync.Mutex
+	name      string
+	started   bool
+	start     time.Time
+	responses map[string][]Response
+	events    []Event
+	// played is the number of events streams played, new streams start there.
+	// generation changes when events are replaced so older streams stop
+	// moving it
+	played     int
+	generation int
+}
+
+// Stream is the position of one replayed stream in the recorded events, every
+// stream plays events on its own so concurrent streams receive all of them
+type Stream struct {
+	player     *Player
+	generation int
+	events     []Event
+	next       int
+}
+
+// NewPlayer returns new Player for session, speed 2 plays the session twice
+// as fast, speed 0.5 twice as slow. Offsets are measured with clock
+func NewPlayer(session *Session, speed float64, clock *clock.Clock) *Player {
+	if speed <= 0 {
+		speed = 1
+	}
+	p := &Player{
+		clock: clock,
+		speed: speed,
+	}
+	p.Replace(session)
+	return p
+}
+
+// Replace replaces entries left to play with the session, it starts again with
+// the next request. Events are played in order of their offsets
+func (p *Player) Replace(session *Session) {
+	responses := make(map[string][]Response)
+	for _, response := range session.Responses {
+		key := routeKey(response.Method, response.Path)
+		responses[key] = append(responses[key], response)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.name = session.Name
+	p.started = false
+	p.responses = responses
+	p.events = append([]Event(nil), session.Events...)
+	sort.SliceStable(p.events, func(i, j int) bool {
+		return p.events[i].Offset < p.events[j].Offset
+	})
+	p.played = 0
+	p.generation++
+}
+
+// Session returns entries left to play ordered by offset
+func (p *Player) Session() Session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	session := Session{
+		Name:      p.name,
+		Responses: []Response{},
+		Events:    append([]Event{}, p.events[p.played:]...),
+	}
+	for _, queue := range p.responses {
+		session.Responses = append(session.Responses, queue...)
+	}
+	sort.SliceStable(session.Responses, func(i, j int) bool {
+		a, b := session.Responses[i], session.Responses[j]
+		if a.Offset != b.Offset {
+			return a.Offset < b.Offset
+		}
+		return routeKey(a.Method, a.Path) < routeKey(b.Method, b.Path)
+	})
+	return session
+}
+
+// Start marks beginning of the session, only the first call has effect
+func (p *Player) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.started {
+		p.start = p.clock.Now()
+		p.started = true
+	}
+}
+
+// NextResponse returns next recorded response for method and path
+func (p *Player) NextResponse(method, path string) (Response, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := routeKey(method, path)
+	queue := p.responses[key]
+	if len(queue) == 0 {
+		return Response{}, false
+	}
+	p.responses[key] = queue[1:]
+	return queue[0], true
+}
+
+// HasEvents reports if there are stream events left to play
+func (p *Player) HasEvents() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.played < len(p.events)
+}
+
+// Stream returns new stream starting at the first event no stream played yet,
+// a client that disconnects before an event is played receives it after
+// reconnecting
+func (p *Player) Stream() *Stream {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return &Stream{
+		player:     p,
+		generation: p.generation,
+		events:     p.events,
+		next:       p.played,
+	}
+}
+
+// Next waits for the next event of the stream, io.EOF is returned when the
+// stream played all events
+func (s *Stream) Next(ctx context.Context) (Event, error) {
+	if s.next >= len(s.events) {
+		return Event{}, io.EOF
+	}
+	event := s.events[s.next]
+	if err := s.player.Wait(ctx, event.Offset); err != nil {
+		return Event{}, err
+	}
+	s.next++
+
+	p := s.player
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.generation == s.generation && p.played < s.next {
+		p.played = s.next
+	}
+	return event, nil
+}
+
+// Wait blocks until offset (in milliseconds) is reached or ctx is done
+func (p *Player) Wait(ctx context.Context, offset int64) error {
+	p.Start()
+	p.mu.Lock()
+	start := p.start
+	p.mu.Unlock()
+	at := start.Add(time.Duration(float64(offset) / p.speed * float64(time.Millisecond)))
+	return p.clock.Sleep(ctx, at.Sub(p.clock.Now()))
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + strings.TrimRight(path, "/")
+}
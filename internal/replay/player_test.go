@@ -0,0 +1,164 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+)
+
+// frozenPlayer returns player of the session on a frozen clock
+func frozenPlayer(session *Session, speed float64) (*Player, *clock.Clock) {
+	clk := clock.New()
+	clk.Freeze()
+	return NewPlayer(session, speed, clk), clk
+}
+
+// next returns the next event of the stream once the clock advanced by d, the
+// event must not be played earlier
+func next(t *testing.T, clk *clock.Clock, stream *Stream, d time.Duration) Event {
+	t.Helper()
+	type result struct {
+		event Event
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		event, err := stream.Next(context.Background())
+		done <- result{event, err}
+	}()
+	if d > 0 {
+		select {
+		case r := <-done:
+			t.Fatalf("got event %+v and error %v before the clock advanced by %s", r.event, r.err, d)
+		case <-time.After(20 * time.Millisecond):
+		}
+		clk.Advance(d)
+	}
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		return r.event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event after the clock advanced by %s", d)
+	}
+	return Event{}
+}
+
+func testSession() *Session {
+	return &Session{
+		Name: "incident",
+		Events: []Event{
+			{Offset: 1000, Event: "*", Data: json.RawMessage(`"second"`)},
+			{Offset: 0, Event: "*", Data: json.RawMessage(`"first"`)},
+			{Offset: 3000, Disconnect: true},
+			{Offset: 3000, Event: "*", Data: json.RawMessage(`"third"`)},
+		},
+	}
+}
+
+func TestStreamTiming(t *testing.T) {
+	tests := []struct {
+		name  string
+		speed float64
+		// waits are clock advances before each event is played
+		waits []time.Duration
+	}{
+		{name: "recorded speed", speed: 1, waits: []time.Duration{0, time.Second, 2 * time.Second, 0}},
+		{name: "twice as fast", speed: 2, waits: []time.Duration{0, 500 * time.Millisecond, time.Second, 0}},
+		{name: "twice as slow", speed: 0.5, waits: []time.Duration{0, 2 * time.Second, 4 * time.Second, 0}},
+		{name: "default speed", speed: 0, waits: []time.Duration{0, time.Second, 2 * time.Second, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player, clk := frozenPlayer(testSession(), tt.speed)
+			player.Start()
+			stream := player.Stream()
+			want := []string{"first", "second", "", "third"}
+			for i, wait := range tt.waits {
+				event := next(t, clk, stream, wait)
+				if got := string(event.Payload()); got != want[i] {
+					t.Errorf("event %d has payload %q, want %q", i, got, want[i])
+				}
+			}
+			if _, err := stream.Next(context.Background()); err != io.EOF {
+				t.Errorf("got error %v after the last event, want EOF", err)
+			}
+			if player.HasEvents() {
+				t.Error("player has events after the stream played all of them")
+			}
+		})
+	}
+}
+
+func TestConcurrentStreams(t *testing.T) {
+	player, clk := frozenPlayer(testSession(), 1)
+	player.Start()
+	first, second := player.Stream(), player.Stream()
+
+	if got := next(t, clk, first, 0); string(got.Payload()) != "first" {
+		t.Errorf("first stream got %q, want first", got.Payload())
+	}
+	if got := next(t, clk, first, time.Second); string(got.Payload()) != "second" {
+		t.Errorf("first stream got %q, want second", got.Payload())
+	}
+	// the second stream still receives events the first one played
+	for _, want := range []string{"first", "second"} {
+		if got := next(t, clk, second, 0); string(got.Payload()) != want {
+			t.Errorf("second stream got %q, want %s", got.Payload(), want)
+		}
+	}
+
+	// a reconnecting client continues with the events no stream played yet
+	reconnected := player.Stream()
+	if got := next(t, clk, reconnected, 2*time.Second); !got.Disconnect {
+		t.Errorf("reconnected stream got %+v, want disconnect", got)
+	}
+	if got := player.Stream(); len(got.events)-got.next != 1 {
+		t.Errorf("new stream has %d events left, want 1", len(got.events)-got.next)
+	}
+	if session := player.Session(); len(session.Events) != 1 || string(session.Events[0].Payload()) != "third" {
+		t.Errorf("got events %+v left to play, want third", session.Events)
+	}
+}
+
+func TestReplaceRestartsEvents(t *testing.T) {
+	player, clk := frozenPlayer(testSession(), 1)
+	player.Start()
+	old := player.Stream()
+	next(t, clk, old, 0)
+
+	player.Replace(&Session{Events: []Event{{Offset: 0, Data: json.RawMessage(`"replaced"`)}}})
+	next(t, clk, old, time.Second)
+	if !player.HasEvents() {
+		t.Fatal("stream of the replaced session played events of the new one")
+	}
+	if got := next(t, clk, player.Stream(), 0); string(got.Payload()) != "replaced" {
+		t.Errorf("got %q, want replaced", got.Payload())
+	}
+}
+
+func TestNextResponse(t *testing.T) {
+	session := &Session{Responses: []Response{
+		{Offset: 0, Method: "get", Path: "/client/env/feature-configs", Body: json.RawMessage(`[]`)},
+		{Offset: 10, Method: "get", Path: "/client/env/feature-configs/", Status: 500},
+	}}
+	if err := session.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	player, _ := frozenPlayer(session, 1)
+	for _, want := range []int{200, 500} {
+		response, ok := player.NextResponse("GET", "/client/env/feature-configs")
+		if !ok || response.Status != want {
+			t.Errorf("got response %+v and %t, want status %d", response, ok, want)
+		}
+	}
+	if _, ok := player.NextResponse("GET", "/client/env/feature-configs"); ok {
+		t.Error("got response after all of them were played")
+	}
+}
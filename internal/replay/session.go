@@ -0,0 +1,95 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Session is a recorded sequence of HTTP responses and stream events,
+// offsets are relative to the first request received by the server
+type Session struct {
+	Name      string     `json:"name,omitempty"`
+	Responses []Response `json:"responses"`
+	Events    []Event    `json:"events"`
+}
+
+// Response is a recorded HTTP response, it is served for the request
+// with the same method and path
+type Response struct {
+	// Offset in milliseconds from the start of the session
+	Offset  int64             `json:"offset"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Event is a recorded SSE event, if Disconnect is set the stream
+// is closed instead of sending the event
+type Event struct {
+	// Offset in milliseconds from the start of the session
+	Offset     int64           `json:"offset"`
+	Event      string          `json:"event,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Disconnect bool            `json:"disconnect,omitempty"`
+}
+
+// LoadSession reads session from the json file
+func LoadSession(filename string) (*Session, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{}
+	if err := json.Unmarshal(content, session); err != nil {
+		return nil, fmt.Errorf("session file %s: %w", filename, err)
+	}
+
+	if err := session.Validate(); err != nil {
+		return nil, fmt.Errorf("session file %s: %w", filename, err)
+	}
+	return session, nil
+}
+
+// Validate checks every response has method and path, missing statuses are
+// set to 200 and methods are upper cased
+func (s *Session) Validate() error {
+	for i, response := range s.Responses {
+		if response.Method == "" || response.Path == "" {
+			return fmt.Errorf("response %d has no method or path", i)
+		}
+		if response.Status == 0 {
+			s.Responses[i].Status = 200
+		}
+		s.Responses[i].Method = strings.ToUpper(response.Method)
+	}
+	return nil
+}
+
+// Payload returns the recorded body
+func (r Response) Payload() []byte {
+	return payload(r.Body)
+}
+
+// Payload returns the recorded event data
+func (e Event) Payload() []byte {
+	return payload(e.Data)
+}
+
+// payload returns raw bytes of the recorded value, json strings are
+// unquoted so non json bodies can be recorded as well
+func payload(raw json.RawMessage) []byte {
+	if len(raw) == 0 {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return []byte(text)
+	}
+	return raw
+}
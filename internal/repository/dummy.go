@@ -1,6 +1,11 @@
 package repository
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
 	"github.com/drone/ff-mock-server/internal"
 	"github.com/drone/ff-mock-server/pkg/api"
 )
@@ -53,87 +58,278 @@ var (
 )
 
 var (
-	evaluation = api.Evaluation{
-		Flag:       featureConfig.Feature,
-		Identifier: &featureConfig.Feature,
-		Kind:       string(featureConfig.Kind),
-		Value:      "true",
-	}
+	stringFeatureConfig = multivariateFeatureConfig("string-flag", api.FeatureConfigKindString,
+		"red", "green", "blue")
+	intFeatureConfig = multivariateFeatureConfig("int-flag", api.FeatureConfigKindInt,
+		"10", "20", "30")
+	numberFeatureConfig = multivariateFeatureConfig("number-flag", api.FeatureConfigKindNumber,
+		"1.5", "2.5", "3.5")
+	jsonFeatureConfig = multivariateFeatureConfig("json-flag", api.FeatureConfigKindJson,
+		`{"theme":"light","size":1}`, `{"theme":"dark","size":2}`)
 )
 
-// DummyRepository contains mocked configurations, target groups and
-// evaluations
+// multivariateFeatureConfig returns mocked flag of the kind serving the first value
+// by default and the last value when the flag is off
+func multivariateFeatureConfig(feature string, kind api.FeatureConfigKind, values ...string) api.FeatureConfig {
+	variations := make([]api.Variation, 0, len(values))
+	for i, value := range values {
+		variations = append(variations, api.Variation{
+			Identifier: fmt.Sprintf("variation-%d", i+1),
+			Value:      value,
+		})
+	}
+	return api.FeatureConfig{
+		Project: internal.Project,
+		DefaultServe: api.Serve{
+			Variation: &variations[0].Identifier,
+		},
+		Environment:  internal.Environment,
+		Feature:      feature,
+		Kind:         kind,
+		OffVariation: variations[len(variations)-1].Identifier,
+		State:        api.FeatureStateOn,
+		Variations:   variations,
+		Version:      &version,
+	}
+}
+
+// DummyRepository contains mocked configurations and target groups
 type DummyRepository struct {
+	mu             sync.RWMutex
 	featureConfigs map[string]api.FeatureConfig
 	targetGroups   map[string]api.Segment
-	evaluations    map[string]api.Evaluation
 }
 
-var _ Repository = DummyRepository{}
+var _ Store = &DummyRepository{}
 
 // NewDummyRepository returns new DummyRepository with initialized
-// dummy data
+// dummy data, a flag of every kind and a target group
 func NewDummyRepository() *DummyRepository {
+	featureConfigs := make(map[string]api.FeatureConfig)
+	for _, fc := range []api.FeatureConfig{featureConfig, stringFeatureConfig, intFeatureConfig,
+		numberFeatureConfig, jsonFeatureConfig} {
+		featureConfigs[fc.Feature] = fc
+	}
 	return &DummyRepository{
-		featureConfigs: map[string]api.FeatureConfig{
-			featureConfig.Feature: featureConfig,
-		},
+		featureConfigs: featureConfigs,
 		targetGroups: map[string]api.Segment{
 			segment.Identifier: segment,
 		},
-		evaluations: map[string]api.Evaluation{
-			evaluation.Flag: evaluation,
-		},
 	}
 }
 
-// GetFlagConfigurations returns all mocked configurations
+// NewRepository returns new DummyRepository serving flags and segments of the
+// dataset, every flag is validated before it is served
+func NewRepository(dataset Dataset) (*DummyRepository, error) {
+	r := &DummyRepository{
+		featureConfigs: make(map[string]api.FeatureConfig, len(dataset.Flags)),
+		targetGroups:   make(map[string]api.Segment, len(dataset.Segments)),
+	}
+	for _, fc := range dataset.Flags {
+		if err := ValidateFeatureConfig(fc); err != nil {
+			return nil, err
+		}
+		r.featureConfigs[fc.Feature] = fc
+	}
+	for _, segment := range dataset.Segments {
+		r.targetGroups[segment.Identifier] = segment
+	}
+	return r, nil
+}
+
+// GetFlagConfigurations returns all mocked configurations sorted by identifier
 // there is no need for env because environment is also mocked
-func (r DummyRepository) GetFlagConfigurations() []api.FeatureConfig {
+func (r *DummyRepository) GetFlagConfigurations() []api.FeatureConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	slice := make([]api.FeatureConfig, 0, len(r.featureConfigs))
 	for _, val := range r.featureConfigs {
 		slice = append(slice, val)
 	}
+	sort.Slice(slice, func(i, j int) bool {
+		return slice[i].Feature < slice[j].Feature
+	})
 	return slice
 }
 
 // GetFlagConfiguration returns mocked configurations with identifier specified
 // there is no need for env because environment is also mocked
-func (r DummyRepository) GetFlagConfiguration(identifier string) (fc api.FeatureConfig, exists bool) {
+func (r *DummyRepository) GetFlagConfiguration(identifier string) (fc api.FeatureConfig, exists bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	fc, exists = r.featureConfigs[identifier]
 	return
 }
 
-// GetTargetGroups returns all mocked target groups
+// GetTargetGroups returns all mocked target groups sorted by identifier
 // there is no need for env because environment is also mocked
-func (r DummyRepository) GetTargetGroups() []api.Segment {
+func (r *DummyRepository) GetTargetGroups() []api.Segment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	slice := make([]api.Segment, 0, len(r.targetGroups))
 	for _, val := range r.targetGroups {
 		slice = append(slice, val)
 	}
+	sort.Slice(slice, func(i, j int) bool {
+		return slice[i].Identifier < slice[j].Identifier
+	})
 	return slice
 }
 
 // GetTargetGroup returns mocked target group with identifier specified
 // there is no need for env because environment is also mocked
-func (r DummyRepository) GetTargetGroup(identifier string) (segment api.Segment, exists bool) {
+func (r *DummyRepository) GetTargetGroup(identifier string) (segment api.Segment, exists bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	segment, exists = r.targetGroups[identifier]
 	return
 }
 
-// GetEvaluations returns all mocked evaluations
-// there is no need for env because environment is also mocked
-func (r DummyRepository) GetEvaluations() api.Evaluations {
-	slice := make([]api.Evaluation, 0, len(r.evaluations))
-	for _, val := range r.evaluations {
-		slice = append(slice, val)
+// SetFlagConfiguration validates and stores the flag, version of a replaced
+// flag is incremented unless a higher version is set
+func (r *DummyRepository) SetFlagConfiguration(fc api.FeatureConfig) (api.FeatureConfig, error) {
+	if err := ValidateFeatureConfig(fc); err != nil {
+		return api.FeatureConfig{}, err
 	}
-	return slice
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous, exists := r.featureConfigs[fc.Feature]
+	fc.Version = nextVersion(fc.Version, previous.Version, exists)
+	r.featureConfigs[fc.Feature] = fc
+	return fc, nil
 }
 
-// GetEvaluation returns mocked evaluation with identifier specified
-// there is no need for env because environment is also mocked
-func (r DummyRepository) GetEvaluation(identifier string) (evaluation api.Evaluation, exists bool) {
-	evaluation, exists = r.evaluations[identifier]
-	return
+// DeleteFlagConfiguration removes the flag, false is returned when it doesn't exist
+func (r *DummyRepository) DeleteFlagConfiguration(identifier string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, exists := r.featureConfigs[identifier]
+	delete(r.featureConfigs, identifier)
+	return exists
+}
+
+// SetTargetGroup stores the target group, version of a replaced target group
+// is incremented unless a higher version is set
+func (r *DummyRepository) SetTargetGroup(segment api.Segment) (api.Segment, error) {
+	if segment.Identifier == "" {
+		return api.Segment{}, fmt.Errorf("target group identifier is required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous, exists := r.targetGroups[segment.Identifier]
+	segment.Version = nextVersion(segment.Version, previous.Version, exists)
+	r.targetGroups[segment.Identifier] = segment
+	return segment, nil
+}
+
+// DeleteTargetGroup removes the target group, false is returned when it doesn't exist
+func (r *DummyRepository) DeleteTargetGroup(identifier string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, exists := r.targetGroups[identifier]
+	delete(r.targetGroups, identifier)
+	return exists
+}
+
+// Changes lists items changed by Replace, deleted items are returned as they
+// were stored
+type Changes struct {
+	Flags           []api.FeatureConfig
+	DeletedFlags    []api.FeatureConfig
+	Segments        []api.Segment
+	DeletedSegments []api.Segment
+}
+
+// Dataset returns all flags and segments sorted by identifier
+func (r *DummyRepository) Dataset() Dataset {
+	return Dataset{
+		Flags:    r.GetFlagConfigurations(),
+		Segments: r.GetTargetGroups(),
+	}
+}
+
+// Replace validates flags and segments of the dataset and replaces all stored
+// ones with them at once. Changed items get a higher version like when they are
+// set, items equal to the stored ones keep their version
+func (r *DummyRepository) Replace(dataset Dataset) (Changes, error) {
+	for _, fc := range dataset.Flags {
+		if err := ValidateFeatureConfig(fc); err != nil {
+			return Changes{}, err
+		}
+	}
+	for _, segment := range dataset.Segments {
+		if segment.Identifier == "" {
+			return Changes{}, fmt.Errorf("target group identifier is required")
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changes := Changes{}
+	featureConfigs := make(map[string]api.FeatureConfig, len(dataset.Flags))
+	for _, fc := range dataset.Flags {
+		previous, exists := r.featureConfigs[fc.Feature]
+		if exists && equalFlags(fc, previous) {
+			featureConfigs[fc.Feature] = previous
+			continue
+		}
+		fc.Version = nextVersion(fc.Version, previous.Version, exists)
+		featureConfigs[fc.Feature] = fc
+		changes.Flags = append(changes.Flags, fc)
+	}
+	for identifier, previous := range r.featureConfigs {
+		if _, exists := featureConfigs[identifier]; !exists {
+			changes.DeletedFlags = append(changes.DeletedFlags, previous)
+		}
+	}
+
+	targetGroups := make(map[string]api.Segment, len(dataset.Segments))
+	for _, segment := range dataset.Segments {
+		previous, exists := r.targetGroups[segment.Identifier]
+		if exists && equalSegments(segment, previous) {
+			targetGroups[segment.Identifier] = previous
+			continue
+		}
+		segment.Version = nextVersion(segment.Version, previous.Version, exists)
+		targetGroups[segment.Identifier] = segment
+		changes.Segments = append(changes.Segments, segment)
+	}
+	for identifier, previous := range r.targetGroups {
+		if _, exists := targetGroups[identifier]; !exists {
+			changes.DeletedSegments = append(changes.DeletedSegments, previous)
+		}
+	}
+
+	r.featureConfigs = featureConfigs
+	r.targetGroups = targetGroups
+	return changes, nil
+}
+
+// equalFlags compares flags ignoring their versions
+func equalFlags(a, b api.FeatureConfig) bool {
+	a.Version, b.Version = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// equalSegments compares target groups ignoring their versions
+func equalSegments(a, b api.Segment) bool {
+	a.Version, b.Version = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+// nextVersion returns version of a stored item, SDKs ignore changes which don't
+// increase the version
+func nextVersion(requested, previous *int64, exists bool) *int64 {
+	next := int64(1)
+	if exists && previous != nil {
+		next = *previous + 1
+	}
+	if requested != nil && *requested > next {
+		next = *requested
+	}
+	return &next
 }
@@ -2,54 +2,30 @@ package repository
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
-	"log"
 	"path/filepath"
 
 	"github.com/drone/ff-mock-server/pkg/api"
 )
 
-const source = "./tests"
-
-type memoryStore struct {
-	configs  []api.FeatureConfig
-	segments []api.Segment
-}
-
-type fileStruct struct {
-	Flag     api.FeatureConfig `json:"flag"`
-	Segments []api.Segment     `json:"segments"`
+// Dataset holds flags and segments served by the repository, it is the format
+// of dataset files written by the generate command
+type Dataset struct {
+	Flags    []api.FeatureConfig `json:"flags"`
+	Segments []api.Segment       `json:"segments"`
 }
 
-func init() {
-	loadFiles()
-}
-
-func loadFiles() {
-	files, err := ioutil.ReadDir(source)
+// LoadDataset reads dataset from the json file
+func LoadDataset(filename string) (Dataset, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(filename))
 	if err != nil {
-		log.Print(err)
+		return Dataset{}, err
 	}
 
-	for _, file := range files {
-		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
-			continue
-		}
-
-	}
-}
-
-func loadFile(filename string) fileStruct {
-	fp := filepath.Clean(filepath.Join(source, filename))
-	content, err := ioutil.ReadFile(fp)
-	if err != nil {
-		log.Print(err)
-	}
-
-	result := fileStruct{}
-	err = json.Unmarshal(content, &result)
-	if err != nil {
-		log.Print(err)
+	dataset := Dataset{}
+	if err := json.Unmarshal(content, &dataset); err != nil {
+		return Dataset{}, fmt.Errorf("dataset file %s: %w", filename, err)
 	}
-	return result
+	return dataset, nil
 }
@@ -8,6 +8,15 @@ type Repository interface {
 	GetFlagConfiguration(identifier string) (fc api.FeatureConfig, exists bool)
 	GetTargetGroups() []api.Segment
 	GetTargetGroup(identifier string) (segment api.Segment, exists bool)
-	GetEvaluations() api.Evaluations
-	GetEvaluation(identifier string) (evaluation api.Evaluation, exists bool)
+}
+
+// Store is a Repository which can be changed at runtime
+type Store interface {
+	Repository
+	SetFlagConfiguration(fc api.FeatureConfig) (api.FeatureConfig, error)
+	DeleteFlagConfiguration(identifier string) bool
+	SetTargetGroup(segment api.Segment) (api.Segment, error)
+	DeleteTargetGroup(identifier string) bool
+	Dataset() Dataset
+	Replace(dataset Dataset) (Changes, error)
 }
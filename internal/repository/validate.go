@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+// ValidateFeatureConfig checks that variations served by the flag exist and
+// values of all variations parse as the declared kind
+func ValidateFeatureConfig(fc api.FeatureConfig) error {
+	if len(fc.Variations) < 2 {
+		return fmt.Errorf("flag %s: at least two variations are required", fc.Feature)
+	}
+
+	variations := make(map[string]bool, len(fc.Variations))
+	for _, variation := range fc.Variations {
+		if variations[variation.Identifier] {
+			return fmt.Errorf("flag %s: duplicate variation %s", fc.Feature, variation.Identifier)
+		}
+		variations[variation.Identifier] = true
+		if err := validateValue(fc.Kind, variation.Value); err != nil {
+			return fmt.Errorf("flag %s: variation %s: %w", fc.Feature, variation.Identifier, err)
+		}
+	}
+
+	exists := func(identifier, usage string) error {
+		if !variations[identifier] {
+			return fmt.Errorf("flag %s: %s variation %s does not exist", fc.Feature, usage, identifier)
+		}
+		return nil
+	}
+	if err := exists(fc.OffVariation, "off"); err != nil {
+		return err
+	}
+	if err := validateServe(fc.Feature, fc.DefaultServe, "default", exists); err != nil {
+		return err
+	}
+	if fc.Rules != nil {
+		for _, rule := range *fc.Rules {
+			if err := validateServe(fc.Feature, rule.Serve, "rule "+rule.RuleId, exists); err != nil {
+				return err
+			}
+		}
+	}
+	if fc.VariationToTargetMap != nil {
+		for _, variationMap := range *fc.VariationToTargetMap {
+			if err := exists(variationMap.Variation, "target map"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateServe checks that served variation or variations of the distribution exist
+func validateServe(feature string, serve api.Serve, usage string, exists func(identifier, usage string) error) error {
+	switch {
+	case serve.Variation != nil:
+		return exists(*serve.Variation, usage)
+	case serve.Distribution != nil:
+		for _, wv := range serve.Distribution.Variations {
+			if err := exists(wv.Variation, usage); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("flag %s: %s serve has neither variation nor distribution", feature, usage)
+	}
+}
+
+// validateValue checks that value parses as the flag kind
+func validateValue(kind api.FeatureConfigKind, value string) error {
+	var err error
+	switch kind {
+	case api.FeatureConfigKindBoolean:
+		if value != "true" && value != "false" {
+			err = fmt.Errorf("value %q is not a boolean", value)
+		}
+	case api.FeatureConfigKindInt:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case api.FeatureConfigKindNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case api.FeatureConfigKindJson:
+		if !json.Valid([]byte(value)) {
+			err = fmt.Errorf("value %q is not valid json", value)
+		}
+	case api.FeatureConfigKindString:
+	default:
+		err = fmt.Errorf("unknown kind %s", kind)
+	}
+	return err
+}
@@ -0,0 +1,872 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/drone/ff-mock-server/internal/replay"
+	"github.com/drone/ff-mock-server/internal/repository"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/admin"
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// AdminHandler serves admin.yaml endpoints for controlling the mock server at runtime
+type AdminHandler struct {
+	auth        *service.Auth
+	signer      *service.Signer
+	identity    *service.IdentityService
+	store       repository.Store
+	faults      *service.Faults
+	journal     *service.Journal
+	metrics     *service.ReceivedMetrics
+	handler     *Handler
+	events      *Events
+	clock       *clock.Clock
+	compression *service.Compression
+	rateLimits  *service.RateLimits
+	network     *service.NetworkFaults
+	player      *replay.Player
+	tenants     Tenants
+	snapshots   snapshots
+	// mu is held by admin requests and state changes, see Serialize
+	mu sync.RWMutex
+}
+
+// Events holds fault rules and availability of stream and metrics which apply
+// independently of the client API ones
+type Events struct {
+	Faults       *service.Faults
+	Availability *service.Availability
+}
+
+var _ admin.ServerInterface = &AdminHandler{}
+
+// NewAdminHandler returns new AdminHandler sharing the state with the client api Handler
+func NewAdminHandler(auth *service.Auth, signer *service.Signer, identity *service.IdentityService,
+	store repository.Store, faults *service.Faults, journal *service.Journal, metrics *service.ReceivedMetrics,
+	handler *Handler, events *Events, clock *clock.Clock, compression *service.Compression,
+	rateLimits *service.RateLimits, network *service.NetworkFaults, player *replay.Player, tenants Tenants) *AdminHandler {
+	return &AdminHandler{
+		auth:        auth,
+		signer:      signer,
+		identity:    identity,
+		store:       store,
+		faults:      faults,
+		journal:     journal,
+		metrics:     metrics,
+		handler:     handler,
+		events:      events,
+		clock:       clock,
+		compression: compression,
+		rateLimits:  rateLimits,
+		network:     network,
+		player:      player,
+		tenants:     tenants,
+	}
+}
+
+// GetTokenOptions returns options used for issuing tokens
+func (h *AdminHandler) GetTokenOptions(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.auth.TokenOptions())
+}
+
+// SetTokenOptions changes options for tokens issued from now on
+func (h *AdminHandler) SetTokenOptions(ctx echo.Context) error {
+	options := service.TokenOptions{}
+	if err := ctx.Bind(&options); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	h.auth.SetTokenOptions(options)
+	return ctx.JSON(http.StatusOK, options)
+}
+
+// ExpireTokens makes all tokens issued so far expired, SDKs get 401
+// on the next request and have to authenticate again
+func (h *AdminHandler) ExpireTokens(ctx echo.Context) error {
+	h.auth.ExpireTokens()
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// RejectTokens rejects next count authenticated requests with 401
+func (h *AdminHandler) RejectTokens(ctx echo.Context) error {
+	request := admin.RejectTokensRequest{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.auth.RejectTokens(request.Count); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetAPIKeys returns all accepted api keys
+func (h *AdminHandler) GetAPIKeys(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.auth.Keys())
+}
+
+// CreateAPIKey adds api key, the key is generated when it is not set in request
+func (h *AdminHandler) CreateAPIKey(ctx echo.Context) error {
+	key := service.APIKey{}
+	if err := ctx.Bind(&key); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	key, err := h.auth.AddKey(key)
+	switch {
+	case errors.Is(err, service.ErrKeyExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey removes api key, tokens issued with it are rejected from now on
+func (h *AdminHandler) RevokeAPIKey(ctx echo.Context, key string) error {
+	if !h.auth.RevokeKey(key) {
+		return echo.NewHTTPError(http.StatusNotFound, "api key not found")
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// CreateIdentityToken signs IdentityService token with claims from request
+func (h *AdminHandler) CreateIdentityToken(ctx echo.Context) error {
+	claims := dto.IdentityServiceClaims{}
+	if err := ctx.Bind(&claims); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	token, err := h.identity.Sign(claims)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, api.AuthenticationResponse{
+		AuthToken: token,
+	})
+}
+
+// GetSigningKeys returns keys used for verifying tokens
+func (h *AdminHandler) GetSigningKeys(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.signer.Keys())
+}
+
+// RotateSigningKey replaces the signing key with the one from request or
+// generates new one, tokens signed with previous keys stay valid unless
+// revokePrevious is set
+func (h *AdminHandler) RotateSigningKey(ctx echo.Context) error {
+	request := admin.RotateSigningKeyRequest{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	var key []byte
+	if request.Key != nil {
+		key = []byte(*request.Key)
+	}
+	if err := h.signer.Rotate(key, request.RevokePrevious != nil && *request.RevokePrevious); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, h.signer.Keys())
+}
+
+// GetFlags returns all served flags
+func (h *AdminHandler) GetFlags(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.store.GetFlagConfigurations())
+}
+
+// SetFlag creates or replaces the flag, identifier in body defaults to the path one
+func (h *AdminHandler) SetFlag(ctx echo.Context, identifier admin.IdentifierPathParam) error {
+	fc := api.FeatureConfig{}
+	if err := ctx.Bind(&fc); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if fc.Feature == "" {
+		fc.Feature = string(identifier)
+	}
+	if fc.Feature != string(identifier) {
+		return echo.NewHTTPError(http.StatusBadRequest, "feature doesn't match identifier in path")
+	}
+	fc, err := h.UpdateFlag(fc)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, fc)
+}
+
+// DeleteFlag removes the flag
+func (h *AdminHandler) DeleteFlag(ctx echo.Context, identifier admin.IdentifierPathParam) error {
+	deleted, err := h.RemoveFlag(string(identifier))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return echo.NewHTTPError(http.StatusNotFound, "flag not found")
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetSegments returns all served target groups
+func (h *AdminHandler) GetSegments(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.store.GetTargetGroups())
+}
+
+// SetSegment creates or replaces the target group, identifier in body defaults to the path one
+func (h *AdminHandler) SetSegment(ctx echo.Context, identifier admin.IdentifierPathParam) error {
+	segment := api.Segment{}
+	if err := ctx.Bind(&segment); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if segment.Identifier == "" {
+		segment.Identifier = string(identifier)
+	}
+	if segment.Identifier != string(identifier) {
+		return echo.NewHTTPError(http.StatusBadRequest, "identifier doesn't match identifier in path")
+	}
+	segment, err := h.UpdateSegment(segment)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, segment)
+}
+
+// DeleteSegment removes the target group
+func (h *AdminHandler) DeleteSegment(ctx echo.Context, identifier admin.IdentifierPathParam) error {
+	deleted, err := h.RemoveSegment(string(identifier))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return echo.NewHTTPError(http.StatusNotFound, "segment not found")
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// UpdateFlag stores the flag and notifies connected SDKs about the change, empty
+// project and environment are set to the mocked ones
+func (h *AdminHandler) UpdateFlag(fc api.FeatureConfig) (api.FeatureConfig, error) {
+	if fc.Project == "" {
+		fc.Project = internal.Project
+	}
+	if fc.Environment == "" {
+		fc.Environment = internal.Environment
+	}
+	fc, err := h.store.SetFlagConfiguration(fc)
+	if err != nil {
+		return api.FeatureConfig{}, err
+	}
+	return fc, h.handler.NotifyChange(dto.StreamDomainFlag, fc.Feature, dto.StreamEventPatch, *fc.Version)
+}
+
+// RemoveFlag removes the flag and notifies connected SDKs, false is returned
+// when the flag doesn't exist
+func (h *AdminHandler) RemoveFlag(identifier string) (bool, error) {
+	previous, ok := h.store.GetFlagConfiguration(identifier)
+	if !ok || !h.store.DeleteFlagConfiguration(identifier) {
+		return false, nil
+	}
+	return true, h.handler.NotifyChange(dto.StreamDomainFlag, identifier, dto.StreamEventDelete,
+		version(previous.Version)+1)
+}
+
+// UpdateSegment stores the target group and notifies connected SDKs about the change
+func (h *AdminHandler) UpdateSegment(segment api.Segment) (api.Segment, error) {
+	segment, err := h.store.SetTargetGroup(segment)
+	if err != nil {
+		return api.Segment{}, err
+	}
+	return segment, h.handler.NotifyChange(dto.StreamDomainTargetSegment, segment.Identifier, dto.StreamEventPatch,
+		*segment.Version)
+}
+
+// RemoveSegment removes the target group and notifies connected SDKs, false is
+// returned when the target group doesn't exist
+func (h *AdminHandler) RemoveSegment(identifier string) (bool, error) {
+	previous, ok := h.store.GetTargetGroup(identifier)
+	if !ok || !h.store.DeleteTargetGroup(identifier) {
+		return false, nil
+	}
+	return true, h.handler.NotifyChange(dto.StreamDomainTargetSegment, identifier, dto.StreamEventDelete,
+		version(previous.Version)+1)
+}
+
+func version(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// GetFaults returns active fault rules
+func (h *AdminHandler) GetFaults(ctx echo.Context) error {
+	return getFaults(ctx, h.faults)
+}
+
+// CreateFault adds the fault rule
+func (h *AdminHandler) CreateFault(ctx echo.Context) error {
+	return createFault(ctx, h.faults)
+}
+
+// ClearFaults removes all fault rules
+func (h *AdminHandler) ClearFaults(ctx echo.Context) error {
+	h.faults.Clear()
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// DeleteFault removes the fault rule
+func (h *AdminHandler) DeleteFault(ctx echo.Context, id string) error {
+	return deleteFault(ctx, h.faults, id)
+}
+
+// GetEventsFaults returns active fault rules of stream and metrics
+func (h *AdminHandler) GetEventsFaults(ctx echo.Context) error {
+	return getFaults(ctx, h.events.Faults)
+}
+
+// CreateEventsFault adds the fault rule of stream and metrics
+func (h *AdminHandler) CreateEventsFault(ctx echo.Context) error {
+	return createFault(ctx, h.events.Faults)
+}
+
+// ClearEventsFaults removes all fault rules of stream and metrics
+func (h *AdminHandler) ClearEventsFaults(ctx echo.Context) error {
+	h.events.Faults.Clear()
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// DeleteEventsFault removes the fault rule of stream and metrics
+func (h *AdminHandler) DeleteEventsFault(ctx echo.Context, id string) error {
+	return deleteFault(ctx, h.events.Faults, id)
+}
+
+// GetEventsAvailability returns whether stream and metrics serve requests
+func (h *AdminHandler) GetEventsAvailability(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, admin.Availability{
+		Available: h.events.Availability.Available(),
+	})
+}
+
+// SetEventsAvailability makes stream and metrics respond with 503 until they are
+// available again, connected streams are closed
+func (h *AdminHandler) SetEventsAvailability(ctx echo.Context) error {
+	request := admin.Availability{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	h.events.Availability.SetAvailable(request.Available)
+	if !request.Available {
+		h.handler.DisconnectStreams("")
+	}
+	return ctx.JSON(http.StatusOK, request)
+}
+
+func getFaults(ctx echo.Context, faults *service.Faults) error {
+	return ctx.JSON(http.StatusOK, toAdminFaults(faults.Rules()))
+}
+
+func createFault(ctx echo.Context, faults *service.Faults) error {
+	request := admin.FaultRule{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	rule, err := fromAdminFault(request)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusCreated, toAdminFault(faults.Add(rule)))
+}
+
+// fromAdminFault converts and validates the rule
+func fromAdminFault(request admin.FaultRule) (service.FaultRule, error) {
+	rule := service.FaultRule{}
+	if request.Operation != nil {
+		operation, err := NormalizeOperation(*request.Operation)
+		if err != nil {
+			return service.FaultRule{}, err
+		}
+		rule.Operation = operation
+	}
+	if request.Status != nil {
+		rule.Status = *request.Status
+	}
+	if request.Message != nil {
+		rule.Message = *request.Message
+	}
+	if request.Delay != nil {
+		rule.Delay = time.Duration(*request.Delay) * time.Millisecond
+	}
+	if request.Times != nil {
+		rule.Times = *request.Times
+	}
+	if rule.Status == 0 && rule.Delay <= 0 {
+		return service.FaultRule{}, errors.New("status or delay must be set")
+	}
+	if rule.Status != 0 && (rule.Status < 100 || rule.Status > 599) {
+		return service.FaultRule{}, errors.New("status must be a valid http status code")
+	}
+	if rule.Times < 0 {
+		return service.FaultRule{}, errors.New("times must be a positive number")
+	}
+	return rule, nil
+}
+
+func deleteFault(ctx echo.Context, faults *service.Faults, id string) error {
+	if !faults.Remove(id) {
+		return echo.NewHTTPError(http.StatusNotFound, "fault not found")
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func toAdminFault(rule service.FaultRule) admin.FaultRule {
+	delay := int64(rule.Delay / time.Millisecond)
+	return admin.FaultRule{
+		Id:        &rule.ID,
+		Operation: &rule.Operation,
+		Status:    &rule.Status,
+		Message:   &rule.Message,
+		Delay:     &delay,
+		Times:     &rule.Times,
+	}
+}
+
+// GetRateLimits returns active rate limits
+func (h *AdminHandler) GetRateLimits(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, toAdminRateLimits(h.rateLimits.Limits()))
+}
+
+// CreateRateLimit adds the rate limit
+func (h *AdminHandler) CreateRateLimit(ctx echo.Context) error {
+	request := admin.RateLimit{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	limit, err := fromAdminRateLimit(request)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusCreated, toAdminRateLimit(h.rateLimits.Add(limit)))
+}
+
+// ClearRateLimits removes all rate limits
+func (h *AdminHandler) ClearRateLimits(ctx echo.Context) error {
+	h.rateLimits.Clear()
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// DeleteRateLimit removes the rate limit
+func (h *AdminHandler) DeleteRateLimit(ctx echo.Context, id string) error {
+	if !h.rateLimits.Remove(id) {
+		return echo.NewHTTPError(http.StatusNotFound, "rate limit not found")
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// fromAdminRateLimit converts and validates the limit
+func fromAdminRateLimit(request admin.RateLimit) (service.RateLimit, error) {
+	limit := service.RateLimit{
+		By:   string(request.By),
+		Rate: request.Rate,
+	}
+	if request.Operation != nil {
+		operation, err := NormalizeOperation(*request.Operation)
+		if err != nil {
+			return service.RateLimit{}, err
+		}
+		limit.Operation = operation
+	}
+	if request.Burst != nil {
+		limit.Burst = *request.Burst
+	}
+	if request.RetryAfter != nil {
+		limit.RetryAfter = string(*request.RetryAfter)
+	}
+	return limit, limit.Validate()
+}
+
+func toAdminRateLimit(limit service.RateLimit) admin.RateLimit {
+	retryAfter := admin.RateLimitRetryAfter(limit.RetryAfter)
+	if retryAfter == "" {
+		retryAfter = service.RetryAfterSeconds
+	}
+	return admin.RateLimit{
+		Id:         &limit.ID,
+		By:         admin.RateLimitBy(limit.By),
+		Operation:  &limit.Operation,
+		Rate:       limit.Rate,
+		Burst:      &limit.Burst,
+		RetryAfter: &retryAfter,
+	}
+}
+
+func toAdminRateLimits(limits []service.RateLimit) []admin.RateLimit {
+	result := make([]admin.RateLimit, 0, len(limits))
+	for _, limit := range limits {
+		result = append(result, toAdminRateLimit(limit))
+	}
+	return result
+}
+
+// GetNetworkFaults returns active network faults
+func (h *AdminHandler) GetNetworkFaults(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, toAdminNetworkFaults(h.network.Faults()))
+}
+
+// CreateNetworkFault adds the network fault
+func (h *AdminHandler) CreateNetworkFault(ctx echo.Context) error {
+	request := admin.NetworkFault{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	fault, err := fromAdminNetworkFault(request)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusCreated, toAdminNetworkFault(h.network.Add(fault)))
+}
+
+// ClearNetworkFaults removes all network faults
+func (h *AdminHandler) ClearNetworkFaults(ctx echo.Context) error {
+	h.network.Clear()
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// DeleteNetworkFault removes the network fault
+func (h *AdminHandler) DeleteNetworkFault(ctx echo.Context, id string) error {
+	if !h.network.Remove(id) {
+		return echo.NewHTTPError(http.StatusNotFound, "network fault not found")
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// fromAdminNetworkFault converts and validates the fault
+func fromAdminNetworkFault(request admin.NetworkFault) (service.NetworkFault, error) {
+	fault := service.NetworkFault{}
+	if request.Operation != nil {
+		operation, err := NormalizeOperation(*request.Operation)
+		if err != nil {
+			return service.NetworkFault{}, err
+		}
+		fault.Operation = operation
+	}
+	if request.HeaderDelay != nil {
+		fault.HeaderDelay = time.Duration(*request.HeaderDelay) * time.Millisecond
+	}
+	if request.BodyDelay != nil {
+		fault.BodyDelay = time.Duration(*request.BodyDelay) * time.Millisecond
+	}
+	if request.BytesPerSecond != nil {
+		fault.BytesPerSecond = *request.BytesPerSecond
+	}
+	if request.Disconnect != nil {
+		fault.Disconnect = string(*request.Disconnect)
+	}
+	if request.After != nil {
+		fault.After = *request.After
+	}
+	if request.ContentLength != nil {
+		fault.ContentLength = string(*request.ContentLength)
+	}
+	if request.Times != nil {
+		fault.Times = *request.Times
+	}
+	return fault, fault.Validate()
+}
+
+func toAdminNetworkFault(fault service.NetworkFault) admin.NetworkFault {
+	headerDelay := int64(fault.HeaderDelay / time.Millisecond)
+	bodyDelay := int64(fault.BodyDelay / time.Millisecond)
+	result := admin.NetworkFault{
+		Id:             &fault.ID,
+		Operation:      &fault.Operation,
+		HeaderDelay:    &headerDelay,
+		BodyDelay:      &bodyDelay,
+		BytesPerSecond: &fault.BytesPerSecond,
+		After:          &fault.After,
+		Times:          &fault.Times,
+	}
+	if fault.Disconnect != "" {
+		disconnect := admin.NetworkFaultDisconnect(fault.Disconnect)
+		result.Disconnect = &disconnect
+	}
+	if fault.ContentLength != "" {
+		contentLength := admin.NetworkFaultContentLength(fault.ContentLength)
+		result.ContentLength = &contentLength
+	}
+	return result
+}
+
+func toAdminNetworkFaults(faults []service.NetworkFault) []admin.NetworkFault {
+	result := make([]admin.NetworkFault, 0, len(faults))
+	for _, fault := range faults {
+		result = append(result, toAdminNetworkFault(fault))
+	}
+	return result
+}
+
+// GetStreams returns connected streams
+func (h *AdminHandler) GetStreams(ctx echo.Context) error {
+	streams := h.handler.Streams()
+	result := make([]admin.Stream, 0, len(streams))
+	for _, stream := range streams {
+		result = append(result, admin.Stream{
+			Key:         stream.Key,
+			Connections: stream.Connections,
+		})
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// PublishStreamEvent sends the event to the stream of the key or to all streams
+func (h *AdminHandler) PublishStreamEvent(ctx echo.Context, params admin.PublishStreamEventParams) error {
+	event := admin.StreamEvent{}
+	if err := ctx.Bind(&event); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if event.Identifier == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "identifier is required")
+	}
+	err := h.handler.PublishEvent(keyParam(params.Key), dto.StreamEvent{
+		Event:      string(event.Event),
+		Domain:     string(event.Domain),
+		Identifier: event.Identifier,
+		Version:    event.Version,
+	})
+	if err != nil {
+		return err
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// DisconnectStreams closes the stream of the key or all streams
+func (h *AdminHandler) DisconnectStreams(ctx echo.Context, params admin.DisconnectStreamsParams) error {
+	h.handler.DisconnectStreams(keyParam(params.Key))
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func keyParam(key *admin.KeyQueryParam) string {
+	if key == nil {
+		return ""
+	}
+	return string(*key)
+}
+
+// GetStreamAvailability returns whether the stream endpoint accepts connections
+func (h *AdminHandler) GetStreamAvailability(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, admin.StreamAvailability{
+		Available: h.handler.StreamsAvailable(),
+	})
+}
+
+// SetStreamAvailability makes the stream endpoint respond with 503 until it is
+// available again, connected streams are closed
+func (h *AdminHandler) SetStreamAvailability(ctx echo.Context) error {
+	request := admin.StreamAvailability{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	h.handler.SetStreamsAvailable(request.Available)
+	return ctx.JSON(http.StatusOK, request)
+}
+
+// GetJournal returns recorded requests of the client api
+func (h *AdminHandler) GetJournal(ctx echo.Context, params admin.GetJournalParams) error {
+	operation := ""
+	if params.Operation != nil {
+		var err error
+		if operation, err = NormalizeOperation(*params.Operation); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	entries := h.journal.Entries(operation)
+	result := make([]admin.JournalEntry, 0, len(entries))
+	for i := range entries {
+		entry := entries[i]
+		result = append(result, admin.JournalEntry{
+			Time:      entry.Time,
+			Operation: entry.Operation,
+			Method:    entry.Method,
+			Path:      entry.Path,
+			Query:     &entry.Query,
+			Body:      &entry.Body,
+			Status:    entry.Status,
+			Duration:  int64(entry.Duration / time.Millisecond),
+			Fault:     &entry.Fault,
+		})
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// ClearJournal removes all recorded requests
+func (h *AdminHandler) ClearJournal(ctx echo.Context) error {
+	h.journal.Clear()
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetReceivedMetrics returns metrics posted by SDKs
+func (h *AdminHandler) GetReceivedMetrics(ctx echo.Context) error {
+	evaluations := h.metrics.Evaluations()
+	counts := make([]admin.EvaluationCount, 0, len(evaluations))
+	for _, evaluation := range evaluations {
+		counts = append(counts, admin.EvaluationCount{
+			Flag:      evaluation.Flag,
+			Variation: evaluation.Variation,
+			Count:     evaluation.Count,
+		})
+	}
+	return ctx.JSON(http.StatusOK, admin.ReceivedMetrics{
+		Payloads:    h.metrics.Payloads(),
+		Evaluations: counts,
+		Targets:     h.metrics.Targets(),
+	})
+}
+
+// ClearReceivedMetrics removes all received metrics
+func (h *AdminHandler) ClearReceivedMetrics(ctx echo.Context) error {
+	h.metrics.Clear()
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// GetClock returns time, speed and state of the server clock
+func (h *AdminHandler) GetClock(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, toAdminClock(h.clock.State()))
+}
+
+// FreezeClock stops the server clock, tokens don't expire until it is advanced
+// or resumed
+func (h *AdminHandler) FreezeClock(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, toAdminClock(h.clock.Freeze()))
+}
+
+// ResumeClock starts the frozen server clock
+func (h *AdminHandler) ResumeClock(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, toAdminClock(h.clock.Resume()))
+}
+
+// AdvanceClock moves the server clock forward, timers due in the meantime fire
+func (h *AdminHandler) AdvanceClock(ctx echo.Context) error {
+	request := admin.ClockAdvance{}
+	if err := ctx.Bind(&request); err != nil || request.Duration < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "duration must be a non negative number of milliseconds")
+	}
+	state := h.clock.Advance(time.Duration(request.Duration) * time.Millisecond)
+	return ctx.JSON(http.StatusOK, toAdminClock(state))
+}
+
+// SetClockSpeed changes speed of the server clock
+func (h *AdminHandler) SetClockSpeed(ctx echo.Context) error {
+	request := admin.ClockSpeed{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	state, err := h.clock.SetSpeed(request.Speed)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, toAdminClock(state))
+}
+
+// GetETags returns mode of entity tags sent with configuration responses
+func (h *AdminHandler) GetETags(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, admin.ETags{Mode: admin.ETagsMode(h.handler.ETagMode())})
+}
+
+// SetETags changes mode of entity tags sent with configuration responses
+func (h *AdminHandler) SetETags(ctx echo.Context) error {
+	request := admin.ETags{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.handler.SetETagMode(string(request.Mode)); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, admin.ETags{Mode: admin.ETagsMode(h.handler.ETagMode())})
+}
+
+// GetCompression returns compression options of responses
+func (h *AdminHandler) GetCompression(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.compression.Options())
+}
+
+// SetCompression changes compression options of responses, options missing from
+// the request are kept
+func (h *AdminHandler) SetCompression(ctx echo.Context) error {
+	options := h.compression.Options()
+	if err := ctx.Bind(&options); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.compression.SetOptions(options); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, h.compression.Options())
+}
+
+// GetTenants returns all tenants
+func (h *AdminHandler) GetTenants(ctx echo.Context) error {
+	tenants := h.tenants.Tenants()
+	result := make([]admin.Tenant, 0, len(tenants))
+	for _, tenant := range tenants {
+		result = append(result, toAdminTenant(tenant))
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CreateTenant creates tenant with generated api keys and environment, the id
+// is generated when it is not set in request
+func (h *AdminHandler) CreateTenant(ctx echo.Context) error {
+	request := admin.TenantRequest{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	id := ""
+	if request.Id != nil {
+		id = *request.Id
+	}
+	tenant, err := h.tenants.CreateTenant(id)
+	switch {
+	case errors.Is(err, ErrTenantExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, ErrTenantIDInvalid):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusCreated, toAdminTenant(tenant))
+}
+
+// GetTenant returns tenant with the id
+func (h *AdminHandler) GetTenant(ctx echo.Context, id admin.TenantPathParam) error {
+	tenant, ok := h.tenants.Tenant(string(id))
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+	}
+	return ctx.JSON(http.StatusOK, toAdminTenant(tenant))
+}
+
+// DeleteTenant disconnects streams of the tenant and drops its state
+func (h *AdminHandler) DeleteTenant(ctx echo.Context, id admin.TenantPathParam) error {
+	if !h.tenants.DeleteTenant(string(id)) {
+		return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func toAdminTenant(tenant Tenant) admin.Tenant {
+	return admin.Tenant{
+		Id:          tenant.ID,
+		Environment: tenant.Environment,
+		ServerKey:   tenant.ServerKey,
+		ClientKey:   tenant.ClientKey,
+		ProxyKey:    tenant.ProxyKey,
+	}
+}
+
+func toAdminClock(state clock.State) admin.Clock {
+	return admin.Clock{
+		Time:   state.Time,
+		Speed:  state.Speed,
+		Frozen: state.Frozen,
+	}
+}
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// Content encodings of compressed responses
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+	encodingBrotli  = "br"
+)
+
+// preferredEncodings are chosen in this order when the client accepts them with
+// the same quality
+var preferredEncodings = []string{encodingBrotli, encodingGzip, encodingDeflate}
+
+// Compress compresses responses with encoding negotiated from Accept-Encoding. Fault
+// modes send wrong Content-Encoding, truncated gzip streams or compressed bodies
+// to clients which didn't accept them, streams are never compressed
+func Compress(compression *service.Compression) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			options := compression.Options()
+			if options.Mode == service.CompressionOff || routeName(c) == "Stream" {
+				return next(c)
+			}
+
+			res := c.Response()
+			writer := &bufferedWriter{ResponseWriter: res.Writer, status: http.StatusOK}
+			res.Writer = writer
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			res.Writer = writer.ResponseWriter
+
+			header := writer.Header()
+			header.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			acceptEncoding := c.Request().Header.Get(echo.HeaderAcceptEncoding)
+			if writer.status == http.StatusNotModified {
+				// 304 has no body to compress but sends the tag the compressed response has
+				if _, label, _ := chooseEncoding(options, acceptEncoding, options.MinSize); label != "" {
+					setEncodedETag(header, label)
+				}
+				return writer.flush()
+			}
+			if writer.body.Len() == 0 || header.Get(echo.HeaderContentEncoding) != "" {
+				return writer.flush()
+			}
+
+			encoding, label, truncate := chooseEncoding(options, acceptEncoding, writer.body.Len())
+			if encoding == "" {
+				return writer.flush()
+			}
+
+			body, err := encode(encoding, writer.body.Bytes())
+			if err != nil {
+				return err
+			}
+			if truncate {
+				// the body is complete for HTTP but the gzip stream misses its end
+				body = body[:len(body)/2]
+			}
+			header.Set(echo.HeaderContentEncoding, label)
+			header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+			setEncodedETag(header, label)
+			writer.body.Reset()
+			writer.body.Write(body)
+			return writer.flush()
+		}
+	}
+}
+
+// chooseEncoding returns encoding of a body of the size, the encoding it is labelled
+// with and whether it is truncated. Empty encoding means the body isn't compressed
+func chooseEncoding(options service.CompressionOptions, acceptEncoding string, size int) (string, string, bool) {
+	switch options.Mode {
+	case service.CompressionNegotiate:
+		if size >= options.MinSize {
+			encoding := negotiateEncoding(acceptEncoding)
+			return encoding, encoding, false
+		}
+	case service.CompressionWrongEncoding:
+		return encodingGzip, encodingDeflate, false
+	case service.CompressionTruncated:
+		return encodingGzip, encodingGzip, true
+	case service.CompressionUnrequested:
+		return encodingGzip, encodingGzip, false
+	}
+	return "", "", false
+}
+
+// setEncodedETag suffixes ETag of the response with the encoding, compressed and
+// uncompressed representations must not share a strong tag
+func setEncodedETag(header http.Header, encoding string) {
+	if tag := header.Get("ETag"); tag != "" {
+		header.Set("ETag", service.EncodedETag(tag, encoding))
+	}
+}
+
+// negotiateEncoding returns encoding with the highest quality in Accept-Encoding,
+// empty encoding is returned when the client accepts none of them
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQuality := "", 0.0
+	for _, encoding := range preferredEncodings {
+		if quality := acceptQuality(acceptEncoding, encoding); quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+	return best
+}
+
+// acceptQuality returns quality of the encoding in Accept-Encoding, quality of *
+// applies to encodings which are not listed
+func acceptQuality(acceptEncoding, encoding string) float64 {
+	wildcard := 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(fields[0])) {
+		case encoding:
+			return quality
+		case "*":
+			wildcard = quality
+		}
+	}
+	return wildcard
+}
+
+// encode compresses the body, deflate is the zlib format HTTP clients expect
+func encode(encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case encodingGzip:
+		w = gzip.NewWriter(&buf)
+	case encodingDeflate:
+		w = zlib.NewWriter(&buf)
+	default:
+		w = brotli.NewWriter(&buf)
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
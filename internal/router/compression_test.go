@@ -0,0 +1,154 @@
+package router
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+const testETag = `"0123456789abcdef"`
+
+// compressedServer returns echo serving a large body and 304 responses with ETag
+// through the Compress middleware
+func compressedServer(t *testing.T, options service.CompressionOptions) *echo.Echo {
+	t.Helper()
+	compression, err := service.NewCompression(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	e.Use(Compress(compression))
+	e.GET("/flags", func(c echo.Context) error {
+		c.Response().Header().Set("ETag", testETag)
+		if c.Request().Header.Get("If-None-Match") != "" {
+			return c.NoContent(http.StatusNotModified)
+		}
+		return c.String(http.StatusOK, strings.Repeat("flag ", 100))
+	}).Name = "GetFeatureConfig"
+	return e
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", encodingGzip},
+		{"deflate, gzip", encodingGzip},
+		{"gzip, deflate, br", encodingBrotli},
+		{"gzip;q=0.5, deflate", encodingDeflate},
+		{"br;q=0, gzip;q=0.1", encodingGzip},
+		{"*", encodingBrotli},
+		{"*;q=0.5, gzip", encodingGzip},
+		{"GZIP", encodingGzip},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.acceptEncoding); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestCompressETag(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        service.CompressionOptions
+		acceptEncoding string
+		ifNoneMatch    string
+		wantEncoding   string
+		wantETag       string
+	}{
+		{
+			name:    "off",
+			options: service.CompressionOptions{Mode: service.CompressionOff}, acceptEncoding: "gzip",
+			wantETag: testETag,
+		},
+		{
+			name:     "not accepted",
+			options:  service.CompressionOptions{Mode: service.CompressionNegotiate},
+			wantETag: testETag,
+		},
+		{
+			name:    "below min size",
+			options: service.CompressionOptions{Mode: service.CompressionNegotiate, MinSize: 1000}, acceptEncoding: "gzip",
+			wantETag: testETag,
+		},
+		{
+			name:    "gzip",
+			options: service.CompressionOptions{Mode: service.CompressionNegotiate}, acceptEncoding: "gzip",
+			wantEncoding: encodingGzip, wantETag: `"0123456789abcdef-gzip"`,
+		},
+		{
+			name:    "brotli",
+			options: service.CompressionOptions{Mode: service.CompressionNegotiate}, acceptEncoding: "gzip, br",
+			wantEncoding: encodingBrotli, wantETag: `"0123456789abcdef-br"`,
+		},
+		{
+			name:         "wrong encoding is tagged as labelled",
+			options:      service.CompressionOptions{Mode: service.CompressionWrongEncoding},
+			wantEncoding: encodingDeflate, wantETag: `"0123456789abcdef-deflate"`,
+		},
+		{
+			name:    "not modified",
+			options: service.CompressionOptions{Mode: service.CompressionNegotiate}, acceptEncoding: "gzip",
+			ifNoneMatch: `"0123456789abcdef-gzip"`, wantETag: `"0123456789abcdef-gzip"`,
+		},
+		{
+			name:        "not modified without compression",
+			options:     service.CompressionOptions{Mode: service.CompressionNegotiate},
+			ifNoneMatch: testETag, wantETag: testETag,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/flags", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			compressedServer(t, tt.options).ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("got Content-Encoding %q, want %q", got, tt.wantEncoding)
+			}
+			if got := rec.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("got ETag %s, want %s", got, tt.wantETag)
+			}
+			if tt.ifNoneMatch == "" && !service.MatchETag(rec.Header().Get("ETag"), testETag) {
+				t.Errorf("ETag %s doesn't match the tag of the handler", rec.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestCompressGzipBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/flags", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	compressedServer(t, service.CompressionOptions{Mode: service.CompressionNegotiate}).ServeHTTP(rec, req)
+
+	reader, err := gzip.NewReader(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != strings.Repeat("flag ", 100) {
+		t.Errorf("got body %q", body)
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("got Vary %q", rec.Header().Get("Vary"))
+	}
+}
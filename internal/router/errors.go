@@ -0,0 +1,164 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/labstack/echo/v4"
+)
+
+// faultKey is set in the context when the error was injected on purpose,
+// such errors are not checked against the spec
+const faultKey = "fault"
+
+// routeNameKey is set in the context to name of the matched route
+const routeNameKey = "routename"
+
+// errorStatuses maps errors returned by handlers and services to status codes
+var errorStatuses = map[error]int{
+	ErrAuthTokenNilOrInvalid:     http.StatusUnauthorized,
+	service.ErrTokenExpired:      http.StatusUnauthorized,
+	service.ErrTokenRejected:     http.StatusUnauthorized,
+	service.ErrKeyRevoked:        http.StatusUnauthorized,
+	service.ErrUnknownSigningKey: http.StatusUnauthorized,
+	service.ErrClaimsInvalid:     http.StatusForbidden,
+	service.ErrAPIKeyTypeInvalid: http.StatusForbidden,
+}
+
+// ErrorHandler writes every error as api.Error with matching status code,
+// statuses which are not declared for the operation in the spec are logged
+func ErrorHandler(swagger *openapi3.T) echo.HTTPErrorHandler {
+	declared := declaredResponses(swagger)
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		status, message := errorStatus(err)
+		if fault, _ := c.Get(faultKey).(bool); !fault {
+			if responses, ok := declared[routeName(c)]; ok && !responses.declares(status) {
+				logger(c).Warnf("status %d is not declared for operation %s in api.yaml: %s",
+					status, routeName(c), message)
+			}
+		}
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(status)
+		} else {
+			err = c.JSON(status, api.Error{
+				Code:    strconv.Itoa(status),
+				Message: message,
+			})
+		}
+		if err != nil {
+			logger(c).Errorf("error writing error response: %s", err)
+		}
+	}
+}
+
+// errorStatus returns status code and message for the error
+func errorStatus(err error) (int, string) {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message := fmt.Sprint(httpErr.Message)
+		if m, ok := httpErr.Message.(error); ok {
+			message = m.Error()
+		}
+		if message == "" {
+			message = http.StatusText(httpErr.Code)
+		}
+		if httpErr.Internal != nil {
+			// errors from the jwt middleware carry the reason as internal error
+			for target, status := range errorStatuses {
+				if errors.Is(httpErr.Internal, target) {
+					return status, httpErr.Internal.Error()
+				}
+			}
+			if httpErr.Code == http.StatusUnauthorized {
+				message = httpErr.Internal.Error()
+			}
+		}
+		// the request validator reports unknown routes as bad request
+		switch message {
+		case routers.ErrPathNotFound.Error():
+			return http.StatusNotFound, message
+		case routers.ErrMethodNotAllowed.Error():
+			return http.StatusMethodNotAllowed, message
+		}
+		return httpErr.Code, message
+	}
+
+	for target, status := range errorStatuses {
+		if errors.Is(err, target) {
+			return status, err.Error()
+		}
+	}
+	return http.StatusInternalServerError, err.Error()
+}
+
+// responses holds status codes declared for an operation
+type responses map[string]bool
+
+func (r responses) declares(status int) bool {
+	code := strconv.Itoa(status)
+	return r[code] || r[code[:1]+"XX"] || r["default"]
+}
+
+// declaredResponses returns declared status codes by name of the generated route
+func declaredResponses(swagger *openapi3.T) map[string]responses {
+	declared := make(map[string]responses)
+	for _, path := range swagger.Paths {
+		for _, operation := range path.Operations() {
+			codes := make(responses, len(operation.Responses))
+			for code := range operation.Responses {
+				codes[code] = true
+			}
+			declared[operationName(operation.OperationID)] = codes
+		}
+	}
+	return declared
+}
+
+// operationName returns name of the route generated for the operationId, it is
+// camel cased the same way oapi-codegen does
+func operationName(operationID string) string {
+	var name strings.Builder
+	upper := true
+	for _, r := range strings.TrimSpace(operationID) {
+		switch {
+		case unicode.IsUpper(r), unicode.IsDigit(r):
+			name.WriteRune(r)
+		case unicode.IsLower(r) && upper:
+			name.WriteRune(unicode.ToUpper(r))
+		case unicode.IsLower(r):
+			name.WriteRune(r)
+		}
+		upper = strings.ContainsRune("-#@!$&=.+:;_~ (){}[]", r)
+	}
+	return name.String()
+}
+
+// routeName returns name of the matched route, generated handlers are named
+// after operationId. Routes of groups without leading slash are stored without it.
+// The name is looked up once and kept in the context for the rest of the request
+func routeName(c echo.Context) string {
+	if name, ok := c.Get(routeNameKey).(string); ok {
+		return name
+	}
+	path := strings.TrimPrefix(c.Path(), "/")
+	for _, route := range c.Echo().Routes() {
+		if route.Method == c.Request().Method && strings.TrimPrefix(route.Path, "/") == path {
+			c.Set(routeNameKey, route.Name)
+			return route.Name
+		}
+	}
+	return ""
+}
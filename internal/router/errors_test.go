@@ -0,0 +1,53 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		operationID string
+		want        string
+	}{
+		{"postMetrics", "PostMetrics"},
+		{"GetFeatureConfig", "GetFeatureConfig"},
+		{"get-all_segments", "GetAllSegments"},
+		{" stream ", "Stream"},
+		{"auth2fa", "Auth2fa"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := operationName(tt.operationID); got != tt.want {
+			t.Errorf("operationName(%q) = %q, want %q", tt.operationID, got, tt.want)
+		}
+	}
+}
+
+func TestDeclaredResponsesMatchRoutes(t *testing.T) {
+	swagger, err := api.GetSwagger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	api.RegisterHandlers(e, nil)
+	names := make(map[string]bool)
+	for _, route := range e.Routes() {
+		names[route.Name] = true
+	}
+
+	declared := declaredResponses(swagger)
+	if len(declared) != len(names) {
+		t.Errorf("got responses of %d operations, want %d", len(declared), len(names))
+	}
+	for name := range declared {
+		if !names[name] {
+			t.Errorf("responses are declared for %s which is not a route name", name)
+		}
+	}
+	if !declared["PostMetrics"].declares(200) {
+		t.Errorf("PostMetrics doesn't declare status 200")
+	}
+}
@@ -0,0 +1,65 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// notModified sets ETag of the response computed from the request uri and the
+// parts describing its content, true is returned when If-None-Match matches it and
+// the caller should respond 304 without body
+func (h *Handler) notModified(ctx echo.Context, ifNoneMatch *api.IfNoneMatchHeaderParam, parts ...string) bool {
+	resource := ctx.Request().URL.RequestURI()
+	tag := h.etags.Tag(resource, service.ETag(append([]string{resource}, parts...)...))
+	if tag == "" {
+		return false
+	}
+	ctx.Response().Header().Set("ETag", tag)
+	if ifNoneMatch == nil || !service.MatchETag(string(*ifNoneMatch), tag) {
+		return false
+	}
+	h.telemetry.NotModified(routeName(ctx))
+	return true
+}
+
+// ETagMode returns mode of entity tags sent with configuration responses
+func (h *Handler) ETagMode() string {
+	return h.etags.Mode()
+}
+
+// SetETagMode changes mode of entity tags sent with configuration responses
+func (h *Handler) SetETagMode(mode string) error {
+	return h.etags.SetMode(mode)
+}
+
+// flagContents returns JSON of the flags, tags change with any change of the
+// content even when a flag was deleted and created again with the same version
+func flagContents(configurations ...api.FeatureConfig) []string {
+	result := make([]string, 0, len(configurations))
+	for _, fc := range configurations {
+		result = append(result, content(fc))
+	}
+	return result
+}
+
+// segmentContents returns JSON of the segments
+func segmentContents(segments ...api.Segment) []string {
+	result := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		result = append(result, content(segment))
+	}
+	return result
+}
+
+// content returns JSON of the item, items of the repository always marshal
+func content(item interface{}) string {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Sprintf("%+v", item)
+	}
+	return string(b)
+}
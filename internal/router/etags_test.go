@@ -0,0 +1,46 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+func TestContentTagsChangeWithContent(t *testing.T) {
+	version := int64(1)
+	on := "true"
+	off := "false"
+	flag := func(serve *string) api.FeatureConfig {
+		return api.FeatureConfig{
+			Feature:      "flag",
+			Kind:         api.FeatureConfigKindBoolean,
+			State:        api.FeatureStateOn,
+			Variations:   []api.Variation{{Identifier: "true", Value: "true"}, {Identifier: "false", Value: "false"}},
+			OffVariation: "false",
+			DefaultServe: api.Serve{Variation: serve},
+			Version:      &version,
+		}
+	}
+	segment := func(name string) api.Segment {
+		return api.Segment{Identifier: "beta", Name: name, Version: &version}
+	}
+
+	tests := []struct {
+		name        string
+		a, b        []string
+		wantChanged bool
+	}{
+		{"same flag", flagContents(flag(&on)), flagContents(flag(&on)), false},
+		{"flag recreated with same version", flagContents(flag(&on)), flagContents(flag(&off)), true},
+		{"same segment", segmentContents(segment("Beta")), segmentContents(segment("Beta")), false},
+		{"segment recreated with same version", segmentContents(segment("Beta")), segmentContents(segment("Beta users")), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := service.ETag(tt.a...) != service.ETag(tt.b...); changed != tt.wantChanged {
+				t.Errorf("tag changed %t, want %t", changed, tt.wantChanged)
+			}
+		})
+	}
+}
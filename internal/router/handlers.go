@@ -1,19 +1,24 @@
 package router
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"sync/atomic"
 	"time"
 
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/clock"
 	"github.com/drone/ff-mock-server/internal/config"
+	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/drone/ff-mock-server/internal/evaluation"
 	"github.com/drone/ff-mock-server/internal/repository"
 	"github.com/drone/ff-mock-server/internal/service"
 	"github.com/drone/ff-mock-server/pkg/api"
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/gommon/log"
 	"github.com/r3labs/sse/v2"
+	"github.com/sirupsen/logrus"
 )
 
 // ErrAuthTokenNilOrInvalid ...
@@ -24,6 +29,8 @@ type EventSource interface {
 	CreateStream(id string) *sse.Stream
 	StreamExists(id string) bool
 	ServeHTTP(w http.ResponseWriter, r *http.Request)
+	Publish(id string, event *sse.Event)
+	RemoveStream(id string)
 	Close()
 }
 
@@ -31,17 +38,41 @@ type EventSource interface {
 type Handler struct {
 	eventSource        EventSource
 	repo               repository.Repository
+	evaluator          *evaluation.Evaluator
+	auth               *service.Auth
+	metrics            *service.ReceivedMetrics
+	telemetry          *service.Telemetry
+	clock              *clock.Clock
+	etags              *service.ETags
+	options            config.Config
 	targetDataReceived bool
 	sseSeq             uint32
 	sseTimeout         uint32
+	streams            streams
 }
 
-// NewHandler returns new Handler struct with Repository and EventSource initialized
-// using DIP
-func NewHandler(repo repository.Repository, eventSource EventSource) *Handler {
+// NewHandler returns new Handler struct with Repository, EventSource, Auth, ReceivedMetrics,
+// Telemetry, Clock, ETags and options initialized using DIP
+func NewHandler(repo repository.Repository, eventSource EventSource, auth *service.Auth,
+	metrics *service.ReceivedMetrics, telemetry *service.Telemetry, clock *clock.Clock, etags *service.ETags,
+	options config.Config) *Handler {
 	return &Handler{
 		eventSource: eventSource,
 		repo:        repo,
+		evaluator:   evaluation.NewEvaluator(repo),
+		auth:        auth,
+		metrics:     metrics,
+		telemetry:   telemetry,
+		clock:       clock,
+		etags:       etags,
+		options:     options,
+		streams: streams{
+			connections: make(map[string]int),
+			script: StreamScript{
+				OffSequence: options.SSEOffSequence,
+				OffDuration: options.SSEOffDuration,
+			},
+		},
 	}
 }
 
@@ -51,14 +82,20 @@ func (h *Handler) Authenticate(ctx echo.Context) error {
 	authenticationRequest := api.AuthenticationRequest{}
 	err := ctx.Bind(&authenticationRequest)
 	if err != nil {
-		return err
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	keyType := h.auth.KeyType(authenticationRequest.ApiKey)
+	logFields := logrus.Fields{"key_type": keyType}
+	if authenticationRequest.Target != nil {
+		logFields["target"] = authenticationRequest.Target.Identifier
 	}
+	addLogFields(ctx, logFields)
 
-	token, err := service.Authenticate(authenticationRequest.ApiKey)
+	token, err := h.auth.Authenticate(authenticationRequest.ApiKey)
+	h.telemetry.Authenticated(keyType, err == nil)
 	if err != nil {
-		return ctx.JSON(http.StatusUnauthorized, map[string]string{
-			"message": err.Error(),
-		})
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
 	}
 
 	return ctx.JSON(http.StatusOK, api.AuthenticationResponse{
@@ -68,7 +105,7 @@ func (h *Handler) Authenticate(ctx echo.Context) error {
 
 // GetFeatureConfig serve configuration array as JSON response
 // environmentUUID not used because we are serving mocks for single environment
-func (h *Handler) GetFeatureConfig(ctx echo.Context, environmentUUID string) error {
+func (h *Handler) GetFeatureConfig(ctx echo.Context, environmentUUID string, params api.GetFeatureConfigParams) error {
 	token, ok := ctx.Get("user").(*jwt.Token)
 	if !ok {
 		return ErrAuthTokenNilOrInvalid
@@ -76,12 +113,20 @@ func (h *Handler) GetFeatureConfig(ctx echo.Context, environmentUUID string) err
 	if err := service.CheckAPIKeyType(service.ServerKeyType, token); err != nil {
 		return err
 	}
-	return ctx.JSON(http.StatusOK, h.repo.GetFlagConfigurations())
+	configurations := h.repo.GetFlagConfigurations()
+	p, err := paginate(len(configurations), params.PageNumber, params.PageSize, h.options.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	if h.notModified(ctx, params.IfNoneMatch, flagContents(configurations[p.start:p.end]...)...) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSON(http.StatusOK, configurations[p.start:p.end])
 }
 
 // GetFeatureConfigByIdentifier serve configuration specified with identifier
 // environmentUUID not used because we are serving mocks for single environment
-func (h *Handler) GetFeatureConfigByIdentifier(ctx echo.Context, environmentUUID string, identifier string) error {
+func (h *Handler) GetFeatureConfigByIdentifier(ctx echo.Context, environmentUUID string, identifier string, params api.GetFeatureConfigByIdentifierParams) error {
 	token, ok := ctx.Get("user").(*jwt.Token)
 	if !ok {
 		return ErrAuthTokenNilOrInvalid
@@ -92,16 +137,17 @@ func (h *Handler) GetFeatureConfigByIdentifier(ctx echo.Context, environmentUUID
 
 	featureConfig, ok := h.repo.GetFlagConfiguration(identifier)
 	if !ok {
-		return ctx.JSON(http.StatusNotFound, map[string]string{
-			"message": "feature not found",
-		})
+		return echo.NewHTTPError(http.StatusNotFound, "feature not found")
+	}
+	if h.notModified(ctx, params.IfNoneMatch, flagContents(featureConfig)...) {
+		return ctx.NoContent(http.StatusNotModified)
 	}
 	return ctx.JSON(http.StatusOK, featureConfig)
 }
 
 // GetAllSegments serve mocked target groups as JSON response
 // environmentUUID not used because we are serving mocks for single environment
-func (h *Handler) GetAllSegments(ctx echo.Context, environmentUUID string) error {
+func (h *Handler) GetAllSegments(ctx echo.Context, environmentUUID string, params api.GetAllSegmentsParams) error {
 	token, ok := ctx.Get("user").(*jwt.Token)
 	if !ok {
 		return ErrAuthTokenNilOrInvalid
@@ -109,12 +155,20 @@ func (h *Handler) GetAllSegments(ctx echo.Context, environmentUUID string) error
 	if err := service.CheckAPIKeyType(service.ServerKeyType, token); err != nil {
 		return err
 	}
-	return ctx.JSON(http.StatusOK, h.repo.GetTargetGroups())
+	segments := h.repo.GetTargetGroups()
+	p, err := paginate(len(segments), params.PageNumber, params.PageSize, h.options.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	if h.notModified(ctx, params.IfNoneMatch, segmentContents(segments[p.start:p.end]...)...) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSON(http.StatusOK, segmentsWithRules(segments[p.start:p.end], params.Rules))
 }
 
 // GetSegmentByIdentifier serve mocked target group specified by identifier as JSON response
 // environmentUUID not used because we are serving mocks for single environment
-func (h *Handler) GetSegmentByIdentifier(ctx echo.Context, environmentUUID string, identifier string) error {
+func (h *Handler) GetSegmentByIdentifier(ctx echo.Context, environmentUUID string, identifier string, params api.GetSegmentByIdentifierParams) error {
 	token, ok := ctx.Get("user").(*jwt.Token)
 	if !ok {
 		return ErrAuthTokenNilOrInvalid
@@ -124,16 +178,23 @@ func (h *Handler) GetSegmentByIdentifier(ctx echo.Context, environmentUUID strin
 	}
 	segment, ok := h.repo.GetTargetGroup(identifier)
 	if !ok {
-		return ctx.JSON(http.StatusNotFound, map[string]string{
-			"message": "segment not found",
-		})
+		return echo.NewHTTPError(http.StatusNotFound, "segment not found")
 	}
-	return ctx.JSON(http.StatusOK, segment)
+	if h.notModified(ctx, params.IfNoneMatch, segmentContents(segment)...) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSON(http.StatusOK, segmentsWithRules([]api.Segment{segment}, params.Rules)[0])
+}
+
+// evaluationsPage is the paginated response of GetEvaluations
+type evaluationsPage struct {
+	api.Pagination
+	Evaluations api.Evaluations `json:"evaluations"`
 }
 
-// GetEvaluations serve evaluations as JSON response
-// target and environmentUUID not used because we are serving mocks for single environment
-func (h *Handler) GetEvaluations(ctx echo.Context, environmentUUID string, target string) error {
+// GetEvaluations serve evaluations of all flags for the target as JSON response
+// environmentUUID not used because we are serving mocks for single environment
+func (h *Handler) GetEvaluations(ctx echo.Context, environmentUUID string, target string, params api.GetEvaluationsParams) error {
 	token, ok := ctx.Get("user").(*jwt.Token)
 	if !ok {
 		return ErrAuthTokenNilOrInvalid
@@ -141,12 +202,27 @@ func (h *Handler) GetEvaluations(ctx echo.Context, environmentUUID string, targe
 	if err := service.CheckAPIKeyType(service.ClientKeyType, token); err != nil {
 		return err
 	}
-	return ctx.JSON(http.StatusOK, h.repo.GetEvaluations())
+	// evaluations depend on all flags and segments, their contents are read before
+	// evaluating so changes made meanwhile invalidate the tag
+	contents := append(flagContents(h.repo.GetFlagConfigurations()...),
+		segmentContents(h.repo.GetTargetGroups()...)...)
+	evaluations := h.evaluator.EvaluateAll(api.Target{Identifier: target})
+	p, err := paginate(len(evaluations), params.PageNumber, params.PageSize, h.options.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	if h.notModified(ctx, params.IfNoneMatch, contents...) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSON(http.StatusOK, evaluationsPage{
+		Pagination:  p.pagination,
+		Evaluations: evaluations[p.start:p.end],
+	})
 }
 
-// GetEvaluationByIdentifier serve evaluation as JSON response with specified feature
-// target and environmentUUID not used because we are serving mocks for single environment
-func (h *Handler) GetEvaluationByIdentifier(ctx echo.Context, environmentUUID string, target string, feature string) error {
+// GetEvaluationByIdentifier serve evaluation of specified feature for the target as JSON response
+// environmentUUID not used because we are serving mocks for single environment
+func (h *Handler) GetEvaluationByIdentifier(ctx echo.Context, environmentUUID string, target string, feature string, params api.GetEvaluationByIdentifierParams) error {
 	token, ok := ctx.Get("user").(*jwt.Token)
 	if !ok {
 		return ErrAuthTokenNilOrInvalid
@@ -154,66 +230,191 @@ func (h *Handler) GetEvaluationByIdentifier(ctx echo.Context, environmentUUID st
 	if err := service.CheckAPIKeyType(service.ClientKeyType, token); err != nil {
 		return err
 	}
-	evaluation, ok := h.repo.GetEvaluation(feature)
+	featureConfig, ok := h.repo.GetFlagConfiguration(feature)
 	if !ok {
-		return ctx.JSON(http.StatusNotFound, map[string]string{
-			"message": "segment not found",
-		})
+		return echo.NewHTTPError(http.StatusNotFound, "evaluation not found")
 	}
-	return ctx.JSON(http.StatusOK, evaluation)
+	if h.notModified(ctx, params.IfNoneMatch,
+		append(flagContents(featureConfig), segmentContents(h.repo.GetTargetGroups()...)...)...) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSON(http.StatusOK, h.evaluator.Evaluate(featureConfig, api.Target{Identifier: target}))
+}
+
+// AuthenticateProxyKey checks the mocked proxy key and returns JWT token
+func (h *Handler) AuthenticateProxyKey(ctx echo.Context) error {
+	authenticationRequest := api.ProxyAuthenticationRequest{}
+	err := ctx.Bind(&authenticationRequest)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	keyType := h.auth.KeyType(authenticationRequest.ProxyKey)
+	addLogFields(ctx, logrus.Fields{"key_type": keyType})
+
+	token, err := h.auth.AuthenticateProxyKey(authenticationRequest.ProxyKey)
+	h.telemetry.Authenticated(keyType, err == nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
+
+	return ctx.JSON(http.StatusOK, api.AuthenticationResponse{
+		AuthToken: token,
+	})
+}
+
+// GetProxyConfig serve configuration of the mocked environment for the proxy key,
+// segments are served with v2 rules like relay proxies request them
+func (h *Handler) GetProxyConfig(ctx echo.Context, params api.GetProxyConfigParams) error {
+	token, ok := ctx.Get("user").(*jwt.Token)
+	if !ok {
+		return ErrAuthTokenNilOrInvalid
+	}
+	if err := service.CheckAPIKeyType(service.ProxyKeyType, token); err != nil {
+		return err
+	}
+	if !service.IssuedFor(token, params.Key) {
+		return echo.NewHTTPError(http.StatusForbidden, "token was not issued for the proxy key")
+	}
+	// proxy keys of tenants are issued for the tenant environment
+	environmentID := internal.EnvironmentUUID
+	if claims, ok := token.Claims.(*dto.JWTCustomClaims); ok && claims.Environment != "" {
+		environmentID = claims.Environment
+	}
+	if params.Environment != nil && *params.Environment != environmentID {
+		return echo.NewHTTPError(http.StatusNotFound, "environment not found")
+	}
+
+	apiKeys := []string{}
+	for _, key := range h.auth.Keys() {
+		if key.Type != service.ProxyKeyType {
+			apiKeys = append(apiKeys, key.Hash())
+		}
+	}
+	rulesV2 := api.SegmentRulesV2QueryParam(segmentRulesV2)
+	environments := []struct {
+		ApiKeys        []string            `json:"apiKeys"`
+		FeatureConfigs []api.FeatureConfig `json:"featureConfigs"`
+		Id             *string             `json:"id,omitempty"`
+		Segments       []api.Segment       `json:"segments"`
+	}{{
+		ApiKeys:        apiKeys,
+		FeatureConfigs: h.repo.GetFlagConfigurations(),
+		Id:             &environmentID,
+		Segments:       segmentsWithRules(h.repo.GetTargetGroups(), &rulesV2),
+	}}
+
+	p, err := paginate(len(environments), params.PageNumber, params.PageSize, h.options.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	environments = environments[p.start:p.end]
+	parts := []string{environmentID}
+	for _, environment := range environments {
+		parts = append(parts, environment.ApiKeys...)
+		parts = append(parts, flagContents(environment.FeatureConfigs...)...)
+		parts = append(parts, segmentContents(environment.Segments...)...)
+	}
+	if h.notModified(ctx, params.IfNoneMatch, parts...) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.JSON(http.StatusOK, api.ProxyConfig{
+		Pagination:   p.pagination,
+		Environments: &environments,
+	})
 }
 
 // Stream is used to notify SDK instances using SSEOffSequence
 func (h *Handler) Stream(ctx echo.Context, params api.StreamParams) error {
-	if timeout := atomic.LoadUint32(&h.sseSeq); timeout == 0 && config.Options.SSEOffDuration != nil {
-		return echo.NewHTTPError(500, "sse is in offline state")
+	script := h.StreamScript()
+	if timeout := atomic.LoadUint32(&h.sseSeq); (timeout == 0 && script.OffDuration != nil) || !h.StreamsAvailable() {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "sse is in offline state")
 	}
-	log.Infof("connecting key %s on stream", params.APIKey)
+	logger(ctx).Debugf("connecting key %s on stream", params.APIKey)
 	req := ctx.Request()
 	req.URL.RawQuery = "stream=" + params.APIKey
 	if !h.eventSource.StreamExists(params.APIKey) {
 		h.eventSource.CreateStream(params.APIKey)
 	}
 	seq := atomic.LoadUint32(&h.sseSeq)
-	if len(config.Options.SSEOffSequence) > 0 {
-		timer := time.Tick(time.Duration(config.Options.SSEOffSequence[seq]) * time.Second)
+	if int(seq) >= len(script.OffSequence) {
+		// the script was replaced by a shorter one
+		seq = 0
+	}
+	if len(script.OffSequence) > 0 {
+		offAfter := time.Duration(script.OffSequence[seq]) * time.Second
 		go func() {
-			<-timer
-			h.eventSource.Close()
+			if err := h.clock.Sleep(req.Context(), offAfter); err == nil {
+				h.eventSource.Close()
+			}
 		}()
 	}
 	// blocking operation
+	h.streams.connected(params.APIKey)
+	h.telemetry.StreamConnected()
 	h.eventSource.ServeHTTP(ctx.Response().Writer, req)
+	h.telemetry.StreamDisconnected()
+	h.streams.disconnected(params.APIKey)
 
-	if len(config.Options.SSEOffSequence) > 0 {
+	if len(script.OffSequence) > 0 {
 		atomic.StoreUint32(&h.sseSeq, 0)
-		if int(seq) < len(config.Options.SSEOffSequence)-1 {
+		if int(seq) < len(script.OffSequence)-1 {
 			atomic.StoreUint32(&h.sseSeq, seq+1)
 		}
 	}
 
-	if config.Options.SSEOffDuration != nil {
-		timeout := uint32(*config.Options.SSEOffDuration)
+	if script.OffDuration != nil {
+		timeout := uint32(*script.OffDuration)
 		atomic.StoreUint32(&h.sseTimeout, 1)
-		time.Sleep(time.Duration(timeout) * time.Second)
+		_ = h.clock.Sleep(context.Background(), time.Duration(timeout)*time.Second)
 		atomic.StoreUint32(&h.sseTimeout, 0)
 	}
 	return nil
 }
 
 // PostMetrics accept metrics data and do validation checks
-func (h *Handler) PostMetrics(ctx echo.Context, environment api.EnvironmentPathParam) error {
+func (h *Handler) PostMetrics(ctx echo.Context, environment api.EnvironmentPathParam, params api.PostMetricsParams) error {
 	metricsData := &api.Metrics{}
 	err := ctx.Bind(metricsData)
 	if err != nil {
-		return errors.New("metrics not present")
+		return echo.NewHTTPError(http.StatusBadRequest, "metrics not present")
 	}
 
 	if !h.targetDataReceived && (metricsData.TargetData == nil || len(*metricsData.TargetData) == 0) {
-		return errors.New("target data cannot be empty")
+		return echo.NewHTTPError(http.StatusBadRequest, "target data cannot be empty")
 	}
 
 	h.targetDataReceived = true
+	h.metrics.Record(*metricsData)
+	h.telemetry.MetricsReceived()
 
 	return ctx.NoContent(http.StatusOK)
 }
+
+// segmentRulesV2 is the value of rules query param for AND'ed segment rules
+const segmentRulesV2 = "v2"
+
+// segmentsWithRules returns segments with servingRules when v2 rules are requested,
+// servingRules are derived from OR'ed rules when segment has none. Without v2
+// servingRules are removed like the service does for older SDKs
+func segmentsWithRules(segments []api.Segment, rules *api.SegmentRulesV2QueryParam) []api.Segment {
+	v2 := rules != nil && *rules == segmentRulesV2
+	result := make([]api.Segment, 0, len(segments))
+	for _, segment := range segments {
+		if !v2 {
+			segment.ServingRules = nil
+		} else if segment.ServingRules == nil && segment.Rules != nil {
+			servingRules := make([]api.GroupServingRule, 0, len(*segment.Rules))
+			for i, clause := range *segment.Rules {
+				servingRules = append(servingRules, api.GroupServingRule{
+					RuleId:   clause.Id,
+					Priority: i + 1,
+					Clauses:  []api.Clause{clause},
+				})
+			}
+			segment.ServingRules = &servingRules
+		}
+		result = append(result, segment)
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/config"
+	"github.com/drone/ff-mock-server/internal/repository"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	testServerKey = "server-key"
+	testProxyKey  = "proxy-key"
+)
+
+// testSegment has OR'ed rules of an older dataset
+var testSegment = api.Segment{
+	Identifier: "beta",
+	Name:       "Beta",
+	Rules: &[]api.Clause{
+		{Id: "rule-1", Attribute: "email", Op: "ends_with", Values: []string{"@harness.io"}},
+		{Id: "rule-2", Attribute: "country", Op: "equal", Values: []string{"PL"}},
+	},
+}
+
+// newTestHandler returns handler serving the segment with server and proxy keys
+func newTestHandler(t *testing.T) (*Handler, *service.Auth) {
+	t.Helper()
+	repo, err := repository.NewRepository(repository.Dataset{Segments: []api.Segment{testSegment}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := service.NewSigner("HS256", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	etags, err := service.NewETags(service.ETagModeStrong)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := clock.New()
+	auth := service.NewAuth(signer, clk, service.TokenOptions{}, []service.APIKey{
+		{Key: testServerKey, Type: service.ServerKeyType},
+		{Key: testProxyKey, Type: service.ProxyKeyType},
+	})
+	h := NewHandler(repo, nil, auth, service.NewReceivedMetrics(), service.NewTelemetry(), clk, etags, config.Config{})
+	return h, auth
+}
+
+// tokenContext returns context of a request authenticated with the token
+func tokenContext(t *testing.T, auth *service.Auth, signed string) (echo.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	token, err := auth.ParseToken(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	c.Set("user", token)
+	return c, rec
+}
+
+func TestSegmentsWithRules(t *testing.T) {
+	v2 := api.SegmentRulesV2QueryParam(segmentRulesV2)
+	other := api.SegmentRulesV2QueryParam("v1")
+	served := []api.GroupServingRule{{RuleId: "and", Priority: 1, Clauses: *testSegment.Rules}}
+	withServingRules := testSegment
+	withServingRules.ServingRules = &served
+
+	tests := []struct {
+		name    string
+		segment api.Segment
+		rules   *api.SegmentRulesV2QueryParam
+		want    *[]api.GroupServingRule
+	}{
+		{name: "without rules param", segment: withServingRules},
+		{name: "other rules param", segment: withServingRules, rules: &other},
+		{name: "v2 keeps serving rules", segment: withServingRules, rules: &v2, want: &served},
+		{name: "v2 derives serving rules", segment: testSegment, rules: &v2, want: &[]api.GroupServingRule{
+			{RuleId: "rule-1", Priority: 1, Clauses: (*testSegment.Rules)[:1]},
+			{RuleId: "rule-2", Priority: 2, Clauses: (*testSegment.Rules)[1:]},
+		}},
+		{name: "v2 without rules", segment: api.Segment{Identifier: "empty"}, rules: &v2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := segmentsWithRules([]api.Segment{tt.segment}, tt.rules)[0]
+			if !reflect.DeepEqual(got.ServingRules, tt.want) {
+				t.Errorf("got serving rules %+v, want %+v", got.ServingRules, tt.want)
+			}
+			if !reflect.DeepEqual(got.Rules, tt.segment.Rules) {
+				t.Errorf("got rules %+v, want %+v", got.Rules, tt.segment.Rules)
+			}
+		})
+	}
+}
+
+func TestGetProxyConfig(t *testing.T) {
+	h, auth := newTestHandler(t)
+	proxyToken, err := auth.AuthenticateProxyKey(testProxyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverToken, err := auth.Authenticate(testServerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	environment := internal.EnvironmentUUID
+	otherEnvironment := "other"
+
+	tests := []struct {
+		name   string
+		token  string
+		params api.GetProxyConfigParams
+		want   int
+	}{
+		{name: "proxy key", token: proxyToken, params: api.GetProxyConfigParams{Key: testProxyKey}, want: http.StatusOK},
+		{name: "environment", token: proxyToken, params: api.GetProxyConfigParams{Key: testProxyKey, Environment: &environment},
+			want: http.StatusOK},
+		{name: "other environment", token: proxyToken,
+			params: api.GetProxyConfigParams{Key: testProxyKey, Environment: &otherEnvironment}, want: http.StatusNotFound},
+		{name: "token of other key", token: proxyToken, params: api.GetProxyConfigParams{Key: "other"},
+			want: http.StatusForbidden},
+		{name: "server key token", token: serverToken, params: api.GetProxyConfigParams{Key: testServerKey},
+			want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := tokenContext(t, auth, tt.token)
+			if err := h.GetProxyConfig(c, tt.params); err != nil {
+				rec.Code, _ = errorStatus(err)
+			}
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			config := api.ProxyConfig{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &config); err != nil {
+				t.Fatal(err)
+			}
+			if config.Environments == nil || len(*config.Environments) != 1 {
+				t.Fatalf("got environments %+v, want one", config.Environments)
+			}
+			env := (*config.Environments)[0]
+			if env.Id == nil || *env.Id != internal.EnvironmentUUID {
+				t.Errorf("got environment id %v, want %s", env.Id, internal.EnvironmentUUID)
+			}
+			// relay proxies get hashes of the sdk keys and v2 segment rules
+			wantKeys := []string{service.APIKey{Key: testServerKey}.Hash()}
+			if !reflect.DeepEqual(env.ApiKeys, wantKeys) {
+				t.Errorf("got api keys %v, want %v", env.ApiKeys, wantKeys)
+			}
+			if len(env.Segments) != 1 || env.Segments[0].ServingRules == nil || len(*env.Segments[0].ServingRules) != 2 {
+				t.Errorf("got segments %+v, want beta with two serving rules", env.Segments)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// JWKS serves public keys of the signer so relay proxies and gateways can
+// verify tokens issued with asymmetric algorithms
+func JWKS(signer *service.Signer) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, signer.JWKS())
+	}
+}
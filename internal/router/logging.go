@@ -0,0 +1,91 @@
+package router
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/random"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	loggerKey    = "logger"
+	logFieldsKey = "logfields"
+)
+
+// RequestLogger logs every request with its id, operation, status and latency. The id
+// is taken from X-Request-ID header or generated and returned in the response, handlers
+// log with the same fields using logger
+func RequestLogger(base *logrus.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if id == "" {
+				id = random.String(32)
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.Set(loggerKey, base)
+			c.Set(logFieldsKey, logrus.Fields{"request_id": id})
+
+			start := time.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			fields := logrus.Fields{
+				"method":     req.Method,
+				"path":       req.URL.Path,
+				"status":     c.Response().Status,
+				"latency_ms": time.Since(start).Milliseconds(),
+				"remote_ip":  c.RealIP(),
+			}
+			if operation := routeName(c); operation != "" {
+				fields["operation"] = operation
+			}
+			if environment := c.Param("environmentUUID"); environment != "" {
+				fields["environment"] = environment
+			}
+			if target := c.Param("target"); target != "" {
+				fields["target"] = target
+			}
+			addLogFields(c, fields)
+			logger(c).Info("request")
+			return nil
+		}
+	}
+}
+
+// logger returns logger with fields of the request
+func logger(c echo.Context) *logrus.Entry {
+	base, ok := c.Get(loggerKey).(*logrus.Logger)
+	if !ok {
+		base = logrus.StandardLogger()
+	}
+	fields, _ := c.Get(logFieldsKey).(logrus.Fields)
+	return base.WithFields(fields)
+}
+
+// addLogFields adds fields logged with every message of the request, fields
+// set earlier are kept
+func addLogFields(c echo.Context, fields logrus.Fields) {
+	current, ok := c.Get(logFieldsKey).(logrus.Fields)
+	if !ok {
+		return
+	}
+	for key, value := range fields {
+		if _, exists := current[key]; !exists && value != "" {
+			current[key] = value
+		}
+	}
+}
+
+// WithLogFields adds fields to the request log, e.g. tenant serving the request
+func WithLogFields(fields logrus.Fields) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			addLogFields(c, fields)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// logEntries decodes JSON log lines
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		entry := make(map[string]interface{})
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRequestLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		path      string
+		fields    map[string]interface{}
+	}{
+		{
+			name:      "propagated id",
+			requestID: "sdk-request-1",
+			path:      "/client/env/env-1/target/alice/evaluations",
+			fields: map[string]interface{}{
+				"operation": "GetEvaluations", "environment": "env-1", "target": "alice", "key_type": "Server",
+				"tenant": "job", "status": float64(http.StatusOK),
+			},
+		},
+		{
+			name: "generated id",
+			path: "/client/env/env-1/target/bob/evaluations",
+			fields: map[string]interface{}{
+				"operation": "GetEvaluations", "target": "bob", "tenant": "job",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			log := logrus.New()
+			log.SetOutput(buf)
+			log.SetFormatter(&logrus.JSONFormatter{})
+
+			e := echo.New()
+			e.Use(RequestLogger(log))
+			e.Use(WithLogFields(logrus.Fields{"tenant": "job"}))
+			e.GET("/client/env/:environmentUUID/target/:target/evaluations", func(c echo.Context) error {
+				addLogFields(c, logrus.Fields{"key_type": "Server"})
+				logger(c).Info("evaluating")
+				return c.NoContent(http.StatusOK)
+			}).Name = "GetEvaluations"
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.requestID != "" {
+				req.Header.Set(echo.HeaderXRequestID, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(echo.HeaderXRequestID)
+			if tt.requestID != "" && id != tt.requestID {
+				t.Errorf("got request id %q, want %q", id, tt.requestID)
+			}
+			if id == "" {
+				t.Fatal("response has no request id")
+			}
+			entries := logEntries(t, buf)
+			if len(entries) != 2 {
+				t.Fatalf("got %d log entries, want the handler message and the request", len(entries))
+			}
+			for _, entry := range entries {
+				if entry["request_id"] != id {
+					t.Errorf("%s was logged with request id %v, want %s", entry["msg"], entry["request_id"], id)
+				}
+			}
+			request := entries[1]
+			for key, want := range tt.fields {
+				if request[key] != want {
+					t.Errorf("request was logged with %s %v, want %v", key, request[key], want)
+				}
+			}
+		})
+	}
+}
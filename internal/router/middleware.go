@@ -1,20 +1,23 @@
 package router
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"strings"
 	"time"
 
-	"github.com/drone/ff-mock-server/internal/config"
-
 	oapimdl "github.com/deepmap/oapi-codegen/pkg/middleware"
 	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/clock"
 	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/drone/ff-mock-server/internal/service"
 	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/gommon/log"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -28,11 +31,17 @@ func ValidateEnvironment() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			environmentUUID := c.Param("environmentUUID")
-			log.Debugf("Validating auth token claim - environment ID with request param environmentUUID: %s",
-				environmentUUID)
+			if user, ok := c.Get("user").(*jwt.Token); ok {
+				if claims, ok := user.Claims.(*dto.JWTCustomClaims); ok {
+					addLogFields(c, logrus.Fields{
+						"key_type":    claims.KeyType,
+						"environment": claims.Environment,
+					})
+				}
+			}
 			jwtPresent, ok := c.Get(internal.JWTKey).(bool)
-			log.Infof("JwtPresent %v with status %v", jwtPresent, ok)
-			if ok && jwtPresent && environmentUUID != "" {
+			identityService, _ := c.Get(identityServiceKey).(bool)
+			if ((ok && jwtPresent) || identityService) && environmentUUID != "" {
 				// validate jwt
 				user, ok := c.Get("user").(*jwt.Token)
 				if !ok {
@@ -43,8 +52,12 @@ func ValidateEnvironment() echo.MiddlewareFunc {
 				if !ok {
 					return echo.ErrUnauthorized
 				}
+				// identity service tokens are not bound to environment unless they have the claim
+				if identityService && claims.Environment == "" {
+					return next(c)
+				}
 				if claims.Environment != environmentUUID {
-					log.Errorf("Environment %s mismatch with requested %s", claims.Environment,
+					logger(c).Warnf("Environment %s mismatch with requested %s", claims.Environment,
 						environmentUUID)
 					return echo.NewHTTPError(403, fmt.Sprintf("Environment ID %s mismatch with requested %s",
 						claims.Environment, environmentUUID))
@@ -55,6 +68,27 @@ func ValidateEnvironment() echo.MiddlewareFunc {
 	}
 }
 
+// ValidateIdentityService validates IdentityService token from the authorization header
+// when JWTValidation detected it, claims are stored in the context like for bearer tokens
+func ValidateIdentityService(identity *service.IdentityService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if present, ok := c.Get(identityServiceKey).(bool); !ok || !present {
+				return next(c)
+			}
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			raw := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(header), "IdentityService"))
+			token, err := identity.ParseToken(raw)
+			if err != nil {
+				logger(c).Warnf("IdentityService token validation failed: %s", err)
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired identity service token")
+			}
+			c.Set("user", token)
+			return next(c)
+		}
+	}
+}
+
 // JWTValidation validates that values have been provided for the SecurityScheme
 // and sets the type of authorization in the context.  For example if we are authorization with
 // BearerAuth then either a standard auth token, or an IdentityService token can be provided.
@@ -100,31 +134,103 @@ func JWTValidation(c context.Context, input *openapi3filter.AuthenticationInput)
 	return echo.ErrUnauthorized
 }
 
-// ProcessCLIFlags process handler flags
-func ProcessCLIFlags() echo.MiddlewareFunc {
+// InjectFaults delays requests and returns error statuses for operations matched
+// by fault rules, rules can be changed at runtime. Delays run on the clock
+func InjectFaults(faults *service.Faults, telemetry *service.Telemetry, clock *clock.Clock) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// specific routes
-			for _, handler := range config.Options.Handlers {
-				params := make([]interface{}, len(c.ParamValues()))
-				for i, val := range c.ParamValues() {
-					params[i] = val
-				}
-				url := strings.TrimLeft(c.Echo().Reverse(handler, params...), "/")
-				requestedUrl := strings.TrimLeft(c.Request().URL.Path, "/")
-				if requestedUrl == url {
-					if config.Options.Timeout != nil {
-						time.Sleep(time.Duration(*config.Options.Timeout) * time.Second)
-					}
-					if config.Options.StatusCode != nil {
-						return echo.NewHTTPError(*config.Options.StatusCode, config.Options.Message)
-					}
-				}
+			rule, ok := faults.Match(routeName(c))
+			if !ok {
+				return next(c)
 			}
-			if len(config.Options.Handlers) == 0 && config.Options.StatusCode != nil {
-				return echo.NewHTTPError(*config.Options.StatusCode, config.Options.Message)
+			telemetry.FaultFired(routeName(c), rule.Status)
+			if err := clock.Sleep(c.Request().Context(), rule.Delay); err != nil {
+				return err
+			}
+			if rule.Status == 0 {
+				return next(c)
+			}
+			c.Set(faultKey, true)
+			return echo.NewHTTPError(rule.Status, rule.Message)
+		}
+	}
+}
+
+// CheckAvailability rejects requests with 503 while availability is switched off
+func CheckAvailability(availability *service.Availability, telemetry *service.Telemetry) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if availability.Available() {
+				return next(c)
+			}
+			telemetry.FaultFired(routeName(c), http.StatusServiceUnavailable)
+			c.Set(faultKey, true)
+			return echo.NewHTTPError(http.StatusServiceUnavailable, "service unavailable")
+		}
+	}
+}
+
+// EventsOnly applies the middleware to stream and metrics requests only
+func EventsOnly(middleware echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		wrapped := middleware(next)
+		return func(c echo.Context) error {
+			if eventsOperations[routeName(c)] {
+				return wrapped(c)
 			}
 			return next(c)
 		}
 	}
 }
+
+// ObserveRequests counts requests and their latency by operation and status
+func ObserveRequests(telemetry *service.Telemetry) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			telemetry.ObserveRequest(routeName(c), c.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
+
+// RecordJournal records every request with the response status in the journal,
+// errors are handled here so the status written by the error handler is known.
+// Entries are timestamped with clock, durations are wall clock time
+func RecordJournal(journal *service.Journal, clock *clock.Clock) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			var body []byte
+			if req.Body != nil {
+				var err error
+				if body, err = ioutil.ReadAll(req.Body); err != nil {
+					return err
+				}
+				req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+
+			at := clock.Now()
+			start := time.Now()
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			fault, _ := c.Get(faultKey).(bool)
+			journal.Record(service.JournalEntry{
+				Time:      at,
+				Operation: routeName(c),
+				Method:    req.Method,
+				Path:      req.URL.Path,
+				Query:     req.URL.RawQuery,
+				Body:      string(body),
+				Status:    c.Response().Status,
+				Duration:  time.Since(start),
+				Fault:     fault,
+			})
+			return nil
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+func TestIdentityServiceEnvironment(t *testing.T) {
+	identity := service.NewIdentityService("identity-secret", clock.New())
+	sign := func(t *testing.T, identity *service.IdentityService, environment string) string {
+		t.Helper()
+		token, err := identity.Sign(dto.IdentityServiceClaims{Type: "SERVICE", AccountID: "account", Environment: environment})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	e := echo.New()
+	e.GET("/client/env/:environmentUUID/feature-configs", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, func(next echo.HandlerFunc) echo.HandlerFunc {
+		// JWTValidation marks identity service tokens when the spec is validated
+		return func(c echo.Context) error {
+			c.Set(identityServiceKey, true)
+			return next(c)
+		}
+	}, ValidateIdentityService(identity), ValidateEnvironment())
+
+	tests := []struct {
+		name        string
+		token       string
+		environment string
+		want        int
+	}{
+		{name: "environment claim", token: sign(t, identity, "env-1"), environment: "env-1", want: http.StatusOK},
+		{name: "other environment", token: sign(t, identity, "env-1"), environment: "env-2", want: http.StatusForbidden},
+		// tokens of the gateway aren't bound to an environment
+		{name: "no environment claim", token: sign(t, identity, ""), environment: "env-1", want: http.StatusOK},
+		{name: "no environment claim in other environment", token: sign(t, identity, ""), environment: "env-2", want: http.StatusOK},
+		{
+			name:  "wrong secret",
+			token: sign(t, service.NewIdentityService("other-secret", clock.New()), ""), environment: "env-1",
+			want: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/client/env/"+tt.environment+"/feature-configs", nil)
+			req.Header.Set(echo.HeaderAuthorization, "IdentityService "+tt.token)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("got status %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectFaultsDelayOnClock(t *testing.T) {
+	tests := []struct {
+		name string
+		rule service.FaultRule
+		want int
+	}{
+		{name: "delayed error", rule: service.FaultRule{Status: http.StatusServiceUnavailable, Delay: time.Hour}, want: http.StatusServiceUnavailable},
+		{name: "delay only", rule: service.FaultRule{Delay: time.Hour}, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.New()
+			clk.Freeze()
+			e := echo.New()
+			e.Use(InjectFaults(service.NewFaults(tt.rule), service.NewTelemetry(), clk))
+			e.GET("/flags", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}).Name = "GetFeatureConfig"
+
+			done := make(chan int, 1)
+			go func() {
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/flags", nil))
+				done <- rec.Code
+			}()
+			select {
+			case status := <-done:
+				t.Fatalf("got status %d while the clock is frozen", status)
+			case <-time.After(50 * time.Millisecond):
+			}
+			clk.Advance(time.Hour)
+			select {
+			case status := <-done:
+				if status != tt.want {
+					t.Errorf("got status %d, want %d", status, tt.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("request wasn't answered after the clock was advanced")
+			}
+		})
+	}
+}
+
+func TestEventsFaultsAndAvailability(t *testing.T) {
+	tests := []struct {
+		name         string
+		faults       []service.FaultRule
+		eventsFaults []service.FaultRule
+		unavailable  bool
+		// flags and metrics are statuses of the config and events requests
+		flags   int
+		metrics int
+	}{
+		{name: "no faults", flags: http.StatusOK, metrics: http.StatusOK},
+		{name: "events unavailable", unavailable: true, flags: http.StatusOK, metrics: http.StatusServiceUnavailable},
+		{name: "events fault", eventsFaults: []service.FaultRule{{Status: http.StatusBadGateway}},
+			flags: http.StatusOK, metrics: http.StatusBadGateway},
+		{name: "events fault of config operation", eventsFaults: []service.FaultRule{{Operation: "GetFeatureConfig", Status: http.StatusBadGateway}},
+			flags: http.StatusOK, metrics: http.StatusOK},
+		{name: "client fault", faults: []service.FaultRule{{Status: http.StatusInternalServerError}},
+			flags: http.StatusInternalServerError, metrics: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.New()
+			telemetry := service.NewTelemetry()
+			availability := &service.Availability{}
+			availability.SetAvailable(!tt.unavailable)
+			e := echo.New()
+			e.Use(EventsOnly(CheckAvailability(availability, telemetry)))
+			e.Use(InjectFaults(service.NewFaults(tt.faults...), telemetry, clk))
+			e.Use(EventsOnly(InjectFaults(service.NewFaults(tt.eventsFaults...), telemetry, clk)))
+			ok := func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}
+			e.GET("/flags", ok).Name = "GetFeatureConfig"
+			e.POST("/metrics", ok).Name = "PostMetrics"
+
+			for path, want := range map[string]int{"/flags": tt.flags, "/metrics": tt.metrics} {
+				method := http.MethodGet
+				if path == "/metrics" {
+					method = http.MethodPost
+				}
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+				if rec.Code != want {
+					t.Errorf("%s got status %d, want %d", path, rec.Code, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// contentLengthSurplus is added to Content-Length of responses declaring more
+// bytes than their body has
+const contentLengthSurplus = 1024
+
+// trickleInterval is the interval parts of trickled bodies are sent in
+const trickleInterval = 100 * time.Millisecond
+
+// abortedKey is set in the context when the handler aborted the response
+const abortedKey = "aborted"
+
+// InjectNetworkFaults sends responses of operations matched by network faults with
+// delays, trickled bodies or wrong Content-Length, or drops the connection in the
+// middle of the body. Responses are written by the handler first, streams are
+// never faulted. HTTP/1 connections are hijacked so the raw response can be sent,
+// HTTP/2 streams are aborted instead of dropping the connection. Delays run on the
+// clock so tests skip them by advancing it
+func InjectNetworkFaults(faults *service.NetworkFaults, telemetry *service.Telemetry, clock *clock.Clock) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if routeName(c) == "Stream" {
+				return next(c)
+			}
+			fault, ok := faults.Match(routeName(c))
+			if !ok {
+				return next(c)
+			}
+			telemetry.FaultFired(routeName(c), 0)
+			c.Set(faultKey, true)
+
+			res := c.Response()
+			writer := &bufferedWriter{ResponseWriter: res.Writer, status: http.StatusOK}
+			res.Writer = writer
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			res.Writer = writer.ResponseWriter
+			// the response is sent past echo, logs and the journal need its status
+			res.Status = writer.status
+
+			req := c.Request()
+			if err := clock.Sleep(req.Context(), fault.HeaderDelay); err != nil {
+				return nil
+			}
+			body := writer.body.Bytes()
+			header := writer.Header()
+			switch fault.ContentLength {
+			case service.ContentLengthGreater:
+				header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)+contentLengthSurplus))
+			case service.ContentLengthSmaller:
+				header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)/2))
+			case service.ContentLengthMalformed:
+				header.Set(echo.HeaderContentLength, "unknown")
+			default:
+				header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+			}
+			if fault.Disconnect != "" && fault.After < len(body) {
+				body = body[:fault.After]
+			}
+
+			if hijacker, ok := writer.ResponseWriter.(http.Hijacker); ok && req.ProtoMajor == 1 {
+				conn, rw, err := hijacker.Hijack()
+				if err != nil {
+					return err
+				}
+				writeRaw(req.Context(), clock, conn, rw, writer.status, header, body, fault)
+				return nil
+			}
+			writeAborted(req.Context(), clock, writer.ResponseWriter, writer.status, body, fault)
+			return nil
+		}
+	}
+}
+
+// writeRaw writes the response to the hijacked connection and closes it, the
+// connection is reset when the fault says so
+func writeRaw(ctx context.Context, clock *clock.Clock, conn net.Conn, rw io.ReadWriter, status int, header http.Header,
+	body []byte, fault service.NetworkFault) {
+	defer conn.Close()
+	// trickled bodies may take longer than the write timeout of the server
+	_ = conn.SetDeadline(time.Time{})
+	flush := func() error {
+		if flusher, ok := rw.(interface{ Flush() error }); ok {
+			return flusher.Flush()
+		}
+		return nil
+	}
+
+	header.Set("Connection", "close")
+	if header.Get("Date") == "" {
+		header.Set("Date", clock.Now().UTC().Format(http.TimeFormat))
+	}
+	fmt.Fprintf(rw, "HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	if err := header.Write(rw); err != nil {
+		return
+	}
+	fmt.Fprint(rw, "\r\n")
+	if err := flush(); err != nil {
+		return
+	}
+	if err := clock.Sleep(ctx, fault.BodyDelay); err != nil {
+		return
+	}
+	if err := trickle(ctx, clock, rw, flush, body, fault.BytesPerSecond); err != nil {
+		return
+	}
+
+	if fault.Disconnect == service.DisconnectReset {
+		// TLS connections are reset through the underlying connection
+		if tlsConn, ok := conn.(interface{ NetConn() net.Conn }); ok {
+			conn = tlsConn.NetConn()
+		}
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.SetLinger(0)
+		}
+	}
+}
+
+// writeAborted writes the response of HTTP/2 requests, the stream is aborted when
+// the fault drops the connection
+func writeAborted(ctx context.Context, clock *clock.Clock, w http.ResponseWriter, status int, body []byte, fault service.NetworkFault) {
+	flush := func() error {
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return nil
+	}
+	w.WriteHeader(status)
+	_ = flush()
+	if err := clock.Sleep(ctx, fault.BodyDelay); err != nil {
+		return
+	}
+	if err := trickle(ctx, clock, w, flush, body, fault.BytesPerSecond); err != nil {
+		return
+	}
+	if fault.Disconnect != "" {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// trickle writes the body at bytesPerSecond in parts sent every trickleInterval,
+// zero rate writes it at once
+func trickle(ctx context.Context, clock *clock.Clock, w io.Writer, flush func() error, body []byte, bytesPerSecond int) error {
+	size := len(body)
+	interval := time.Duration(0)
+	if bytesPerSecond > 0 {
+		size = int(int64(bytesPerSecond) * int64(trickleInterval) / int64(time.Second))
+		if size < 1 {
+			size = 1
+		}
+		interval = time.Duration(size) * time.Second / time.Duration(bytesPerSecond)
+	}
+	for len(body) > 0 {
+		n := size
+		if n > len(body) {
+			n = len(body)
+		}
+		if _, err := w.Write(body[:n]); err != nil {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		body = body[n:]
+		if len(body) > 0 {
+			if err := clock.Sleep(ctx, interval); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Recover recovers from panics like middleware.Recover but passes http.ErrAbortHandler
+// on, the server aborts the response then as network faults of HTTP/2 requests need
+func Recover() echo.MiddlewareFunc {
+	recoverPanics := middleware.Recover()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		handler := recoverPanics(func(c echo.Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					if r != http.ErrAbortHandler {
+						panic(r)
+					}
+					c.Set(abortedKey, true)
+				}
+			}()
+			return next(c)
+		})
+		return func(c echo.Context) error {
+			err := handler(c)
+			if aborted, _ := c.Get(abortedKey).(bool); aborted {
+				panic(http.ErrAbortHandler)
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package router
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+// faultedServer returns server answering 201 through the InjectNetworkFaults
+// middleware, statuses seen by outer middleware are sent to the channel
+func faultedServer(t *testing.T, clk *clock.Clock, fault service.NetworkFault) (*httptest.Server, <-chan int) {
+	t.Helper()
+	statuses := make(chan int, 1)
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			statuses <- c.Response().Status
+			return err
+		}
+	})
+	e.Use(InjectNetworkFaults(service.NewNetworkFaults(fault), service.NewTelemetry(), clk))
+	e.GET("/flags", func(c echo.Context) error {
+		return c.String(http.StatusCreated, "flags")
+	}).Name = "GetFeatureConfig"
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv, statuses
+}
+
+func TestNetworkFaultStatus(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault service.NetworkFault
+	}{
+		{name: "content length", fault: service.NetworkFault{ContentLength: service.ContentLengthSmaller}},
+		{name: "disconnect", fault: service.NetworkFault{Disconnect: service.DisconnectClose, After: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, statuses := faultedServer(t, clock.New(), tt.fault)
+			res, err := http.Get(srv.URL + "/flags")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			if res.StatusCode != http.StatusCreated {
+				t.Errorf("got status %d, want %d", res.StatusCode, http.StatusCreated)
+			}
+			if status := <-statuses; status != http.StatusCreated {
+				t.Errorf("middleware saw status %d, want %d", status, http.StatusCreated)
+			}
+		})
+	}
+}
+
+func TestNetworkFaultDelaysRunOnClock(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault service.NetworkFault
+	}{
+		{name: "header delay", fault: service.NetworkFault{HeaderDelay: time.Hour}},
+		{name: "body delay", fault: service.NetworkFault{BodyDelay: time.Hour}},
+		{name: "trickle", fault: service.NetworkFault{BytesPerSecond: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.New()
+			clk.Freeze()
+			srv, _ := faultedServer(t, clk, tt.fault)
+
+			done := make(chan string, 1)
+			go func() {
+				res, err := http.Get(srv.URL + "/flags")
+				if err != nil {
+					done <- err.Error()
+					return
+				}
+				defer res.Body.Close()
+				body, _ := ioutil.ReadAll(res.Body)
+				done <- string(body)
+			}()
+
+			select {
+			case body := <-done:
+				t.Fatalf("got %q while the clock is frozen", body)
+			case <-time.After(50 * time.Millisecond):
+			}
+			// the trickled body needs an advance per byte
+			for i := 0; i < len("flags"); i++ {
+				clk.Advance(time.Hour)
+				time.Sleep(10 * time.Millisecond)
+			}
+			select {
+			case body := <-done:
+				if body != "flags" {
+					t.Errorf("got body %q, want flags", body)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("response wasn't sent after the clock was advanced")
+			}
+		})
+	}
+}
+
+func TestNetworkFaultDateOnClock(t *testing.T) {
+	clk := clock.New()
+	clk.Freeze()
+	clk.Advance(48 * time.Hour)
+	srv, _ := faultedServer(t, clk, service.NetworkFault{ContentLength: service.ContentLengthGreater, Disconnect: service.DisconnectClose})
+
+	res, err := http.Get(srv.URL + "/flags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if got, want := res.Header.Get("Date"), clk.Now().UTC().Format(http.TimeFormat); got != want {
+		t.Errorf("got Date %s, want %s", got, want)
+	}
+}
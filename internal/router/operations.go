@@ -0,0 +1,52 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+// ErrOperationUnknown is returned for operations which are not in api.yaml
+var ErrOperationUnknown = errors.New("unknown operation")
+
+var (
+	routeNamesOnce sync.Once
+	routeNames     map[string]bool
+	routeNamesErr  error
+)
+
+// NormalizeOperation returns name of the route generated for an operationId of
+// api.yaml, faults, rate limits and the journal match requests by it. The names
+// are camel cased the way oapi-codegen does, so postMetrics is PostMetrics. Empty
+// operation which matches all operations stays empty
+func NormalizeOperation(operation string) (string, error) {
+	if operation == "" {
+		return "", nil
+	}
+	routeNamesOnce.Do(loadRouteNames)
+	if routeNamesErr != nil {
+		return "", routeNamesErr
+	}
+	name := operationName(operation)
+	if !routeNames[name] {
+		return "", fmt.Errorf("%w '%s', it must be an operationId of api.yaml", ErrOperationUnknown, operation)
+	}
+	return name, nil
+}
+
+// loadRouteNames collects names of routes generated for operations of the spec
+func loadRouteNames() {
+	swagger, err := api.GetSwagger()
+	if err != nil {
+		routeNamesErr = fmt.Errorf("loading swagger spec: %w", err)
+		return
+	}
+	routeNames = make(map[string]bool)
+	for _, path := range swagger.Paths {
+		for _, operation := range path.Operations() {
+			routeNames[operationName(operation.OperationID)] = true
+		}
+	}
+}
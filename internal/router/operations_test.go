@@ -0,0 +1,29 @@
+package router
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeOperation(t *testing.T) {
+	tests := []struct {
+		operation string
+		want      string
+		wantErr   error
+	}{
+		{operation: "", want: ""},
+		{operation: "postMetrics", want: "PostMetrics"},
+		{operation: "PostMetrics", want: "PostMetrics"},
+		{operation: "GetFeatureConfig", want: "GetFeatureConfig"},
+		{operation: "Stream", want: "Stream"},
+		{operation: "postmetrics", wantErr: ErrOperationUnknown},
+		{operation: "metrics", wantErr: ErrOperationUnknown},
+		{operation: "GetFlags", wantErr: ErrOperationUnknown},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeOperation(tt.operation)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("NormalizeOperation(%q) = %q, %v, want %q, %v", tt.operation, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// page holds bounds of the requested page in a list of items
+type page struct {
+	start, end int
+	pagination api.Pagination
+}
+
+// paginate returns requested page of itemCount items, without pageSize all items are
+// served on a single page. maxPageSize limits the page size to force SDKs to
+// request multiple pages, zero means there is no limit
+func paginate(itemCount int, pageNumber *api.PageNumber, pageSize *api.PageSize, maxPageSize int) (page, error) {
+	if (pageNumber != nil && *pageNumber < 0) || (pageSize != nil && *pageSize < 0) {
+		return page{}, echo.NewHTTPError(http.StatusBadRequest, "pageNumber and pageSize must not be negative")
+	}
+	pageIndex := 0
+	if pageNumber != nil {
+		pageIndex = int(*pageNumber)
+	}
+	size := itemCount
+	if pageSize != nil && *pageSize > 0 {
+		size = int(*pageSize)
+	}
+	if maxPageSize > 0 && (size == 0 || size > maxPageSize) {
+		size = maxPageSize
+	}
+
+	pageCount := 0
+	if size > 0 {
+		pageCount = itemCount / size
+		if itemCount%size > 0 {
+			pageCount++
+		}
+	}
+
+	// pages past the end are empty, the index is checked before multiplying
+	// so huge page numbers don't overflow
+	start := itemCount
+	if size > 0 && pageIndex <= itemCount/size {
+		start = min(pageIndex*size, itemCount)
+	}
+	end := start + min(size, itemCount-start)
+	return page{
+		start: start,
+		end:   end,
+		pagination: api.Pagination{
+			ItemCount: itemCount,
+			PageCount: pageCount,
+			PageIndex: pageIndex,
+			PageSize:  size,
+		},
+	}, nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
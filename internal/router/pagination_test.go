@@ -0,0 +1,94 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// maxInt is the largest int, math.MaxInt needs newer Go
+const maxInt = int(^uint(0) >> 1)
+
+func TestPaginate(t *testing.T) {
+	number := func(n int) *api.PageNumber { p := api.PageNumber(n); return &p }
+	size := func(n int) *api.PageSize { p := api.PageSize(n); return &p }
+	tests := []struct {
+		name        string
+		itemCount   int
+		pageNumber  *api.PageNumber
+		pageSize    *api.PageSize
+		maxPageSize int
+		start, end  int
+		pagination  api.Pagination
+	}{
+		{
+			name: "all items on a single page", itemCount: 5,
+			start: 0, end: 5, pagination: api.Pagination{ItemCount: 5, PageCount: 1, PageSize: 5},
+		},
+		{
+			name: "no items", itemCount: 0,
+			start: 0, end: 0, pagination: api.Pagination{},
+		},
+		{
+			name: "first page", itemCount: 5, pageNumber: number(0), pageSize: size(2),
+			start: 0, end: 2, pagination: api.Pagination{ItemCount: 5, PageCount: 3, PageSize: 2},
+		},
+		{
+			name: "last partial page", itemCount: 5, pageNumber: number(2), pageSize: size(2),
+			start: 4, end: 5, pagination: api.Pagination{ItemCount: 5, PageCount: 3, PageIndex: 2, PageSize: 2},
+		},
+		{
+			name: "page past the end", itemCount: 5, pageNumber: number(3), pageSize: size(2),
+			start: 5, end: 5, pagination: api.Pagination{ItemCount: 5, PageCount: 3, PageIndex: 3, PageSize: 2},
+		},
+		{
+			name: "zero page size serves all items", itemCount: 5, pageSize: size(0),
+			start: 0, end: 5, pagination: api.Pagination{ItemCount: 5, PageCount: 1, PageSize: 5},
+		},
+		{
+			name: "max page size limits all items", itemCount: 5, maxPageSize: 2,
+			start: 0, end: 2, pagination: api.Pagination{ItemCount: 5, PageCount: 3, PageSize: 2},
+		},
+		{
+			name: "max page size limits page size", itemCount: 5, pageNumber: number(1), pageSize: size(4), maxPageSize: 3,
+			start: 3, end: 5, pagination: api.Pagination{ItemCount: 5, PageCount: 2, PageIndex: 1, PageSize: 3},
+		},
+		{
+			name: "huge page number", itemCount: 5, pageNumber: number(maxInt), pageSize: size(2),
+			start: 5, end: 5, pagination: api.Pagination{ItemCount: 5, PageCount: 3, PageIndex: maxInt, PageSize: 2},
+		},
+		{
+			name: "huge page size", itemCount: 5, pageNumber: number(1), pageSize: size(maxInt),
+			start: 5, end: 5, pagination: api.Pagination{ItemCount: 5, PageCount: 1, PageIndex: 1, PageSize: maxInt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := paginate(tt.itemCount, tt.pageNumber, tt.pageSize, tt.maxPageSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.start != tt.start || got.end != tt.end {
+				t.Errorf("got items %d to %d, want %d to %d", got.start, got.end, tt.start, tt.end)
+			}
+			if got.pagination != tt.pagination {
+				t.Errorf("got pagination %+v, want %+v", got.pagination, tt.pagination)
+			}
+		})
+	}
+}
+
+func TestPaginateNegative(t *testing.T) {
+	number := api.PageNumber(-1)
+	size := api.PageSize(-1)
+	for _, args := range [][2]interface{}{{&number, nil}, {nil, &size}} {
+		pageNumber, _ := args[0].(*api.PageNumber)
+		pageSize, _ := args[1].(*api.PageSize)
+		_, err := paginate(5, pageNumber, pageSize, 0)
+		if httpErr, ok := err.(*echo.HTTPError); !ok || httpErr.Code != http.StatusBadRequest {
+			t.Errorf("got error %v, want bad request", err)
+		}
+	}
+}
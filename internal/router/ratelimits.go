@@ -0,0 +1,98 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimit rejects requests exceeding rate limits with 429 and Retry-After like
+// the gateway of the service does. Requests are counted by the api key and
+// environment of the token or the key authenticated with
+func RateLimit(limits *service.RateLimits, auth *service.Auth, telemetry *service.Telemetry,
+	clock *clock.Clock) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if limits.Empty() {
+				return next(c)
+			}
+			limit, wait, ok := limits.Take(rateLimitRequest(c, auth))
+			if ok {
+				return next(c)
+			}
+			telemetry.FaultFired(routeName(c), http.StatusTooManyRequests)
+			c.Set(faultKey, true)
+			c.Response().Header().Set("Retry-After", retryAfter(clock, limit.RetryAfter, wait))
+			return echo.NewHTTPError(http.StatusTooManyRequests,
+				fmt.Sprintf("rate limit of %g requests per second by %s exceeded", limit.Rate, limit.By))
+		}
+	}
+}
+
+// rateLimitRequest returns attributes of the request rate limits are counted by
+func rateLimitRequest(c echo.Context, auth *service.Auth) service.RateLimitRequest {
+	request := service.RateLimitRequest{
+		Operation:   routeName(c),
+		IP:          c.RealIP(),
+		Environment: c.Param("environmentUUID"),
+	}
+	if token, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := token.Claims.(*dto.JWTCustomClaims); ok {
+			request.APIKey = claims.Subject
+			if claims.Environment != "" {
+				request.Environment = claims.Environment
+			}
+		}
+		return request
+	}
+
+	// authentication requests have the key in the body
+	req := c.Request()
+	if req.Method != http.MethodPost || req.Body == nil {
+		return request
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return request
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	credentials := struct {
+		APIKey   string `json:"apiKey"`
+		ProxyKey string `json:"proxyKey"`
+	}{}
+	if err := json.Unmarshal(body, &credentials); err != nil || credentials.APIKey+credentials.ProxyKey == "" {
+		return request
+	}
+	key, ok := auth.Key(credentials.APIKey + credentials.ProxyKey)
+	if !ok {
+		// unknown keys are limited by the key only
+		request.APIKey = service.APIKey{Key: credentials.APIKey + credentials.ProxyKey}.Subject()
+		return request
+	}
+	request.APIKey = key.Subject()
+	request.Environment = key.EnvironmentID()
+	return request
+}
+
+// retryAfter returns value of Retry-After header, the wait is rounded up to whole seconds
+func retryAfter(clock *clock.Clock, format string, wait time.Duration) string {
+	if format == service.RetryAfterDate {
+		return clock.Now().Add(wait).Truncate(time.Second).Add(time.Second).UTC().Format(http.TimeFormat)
+	}
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
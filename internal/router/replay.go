@@ -0,0 +1,91 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/drone/ff-mock-server/internal/replay"
+	"github.com/labstack/echo/v4"
+)
+
+// ReplaySession serves responses and stream events recorded in a session,
+// requests without recorded response are passed to the next handler
+func ReplaySession(player *replay.Player) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			player.Start()
+			req := c.Request()
+
+			if req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/stream") && player.HasEvents() {
+				return replayStream(c, player)
+			}
+
+			response, ok := player.NextResponse(req.Method, req.URL.Path)
+			if !ok {
+				return next(c)
+			}
+			if err := player.Wait(req.Context(), response.Offset); err != nil {
+				return nil
+			}
+			for name, value := range response.Headers {
+				c.Response().Header().Set(name, value)
+			}
+			c.Response().WriteHeader(response.Status)
+			_, err := c.Response().Write(response.Payload())
+			return err
+		}
+	}
+}
+
+// replayStream writes recorded events as SSE until the session closes the
+// stream or the client disconnects
+func replayStream(c echo.Context, player *replay.Player) error {
+	ctx := c.Request().Context()
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	stream := player.Stream()
+	for {
+		event, err := stream.Next(ctx)
+		if err == io.EOF {
+			// keep the stream open like the real service does
+			<-ctx.Done()
+			return nil
+		}
+		if err != nil {
+			return nil
+		}
+		if event.Disconnect {
+			logger(c).Infof("replay: closing stream at %dms", event.Offset)
+			return nil
+		}
+		if event.Event != "" {
+			if _, err := fmt.Fprintf(res, "event: %s\n", event.Event); err != nil {
+				return nil
+			}
+		}
+		if err := writeEventData(res, event.Payload()); err != nil {
+			return nil
+		}
+		res.Flush()
+	}
+}
+
+// writeEventData writes the payload as data lines of an SSE event, payloads
+// recorded over several lines are sent line by line so clients join them again
+func writeEventData(w io.Writer, payload []byte) error {
+	text := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(string(payload))
+	for _, line := range strings.Split(text, "\n") {
+		if _, err := fmt.Fprintf(w, "data: %s\n", line); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
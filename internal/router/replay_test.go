@@ -0,0 +1,30 @@
+package router
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteEventData(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{name: "compact", payload: `{"event":"create"}`, want: "data: {\"event\":\"create\"}\n\n"},
+		{name: "empty", payload: "", want: "data: \n\n"},
+		{name: "pretty printed", payload: "{\n  \"event\": \"create\"\n}", want: "data: {\ndata:   \"event\": \"create\"\ndata: }\n\n"},
+		{name: "crlf", payload: "a\r\nb\rc", want: "data: a\ndata: b\ndata: c\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeEventData(&buf, []byte(tt.payload)); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeEventData(%q) = %q, want %q", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
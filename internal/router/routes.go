@@ -0,0 +1,90 @@
+package router
+
+import (
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+// eventsPaths are client API paths SDKs call on the events url, all other
+// paths are called on the config url
+var eventsPaths = map[string]bool{
+	"/stream":               true,
+	"/metrics/:environment": true,
+}
+
+// eventsOperations are operations of eventsPaths
+var eventsOperations = map[string]bool{
+	"Stream":      true,
+	"PostMetrics": true,
+}
+
+// ConfigRoutes returns router registering only routes SDKs call on the config url
+func ConfigRoutes(router api.EchoRouter) api.EchoRouter {
+	return &filteredRouter{
+		router: router,
+		include: func(path string) bool {
+			return !eventsPaths[path]
+		},
+	}
+}
+
+// EventsRoutes returns router registering only stream and metrics routes SDKs
+// call on the events url
+func EventsRoutes(router api.EchoRouter) api.EchoRouter {
+	return &filteredRouter{
+		router: router,
+		include: func(path string) bool {
+			return eventsPaths[path]
+		},
+	}
+}
+
+// filteredRouter registers routes accepted by include, the others are dropped
+type filteredRouter struct {
+	router  api.EchoRouter
+	include func(path string) bool
+}
+
+func (r *filteredRouter) add(path string, register func() *echo.Route) *echo.Route {
+	if !r.include(path) {
+		// generated code sets name of the returned route
+		return &echo.Route{}
+	}
+	return register()
+}
+
+func (r *filteredRouter) CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.CONNECT(path, h, m...) })
+}
+
+func (r *filteredRouter) DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.DELETE(path, h, m...) })
+}
+
+func (r *filteredRouter) GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.GET(path, h, m...) })
+}
+
+func (r *filteredRouter) HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.HEAD(path, h, m...) })
+}
+
+func (r *filteredRouter) OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.OPTIONS(path, h, m...) })
+}
+
+func (r *filteredRouter) PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.PATCH(path, h, m...) })
+}
+
+func (r *filteredRouter) POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.POST(path, h, m...) })
+}
+
+func (r *filteredRouter) PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.PUT(path, h, m...) })
+}
+
+func (r *filteredRouter) TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.add(path, func() *echo.Route { return r.router.TRACE(path, h, m...) })
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestFilteredRoutes(t *testing.T) {
+	h, _ := newTestHandler(t)
+	tests := []struct {
+		name   string
+		routes func(api.EchoRouter) api.EchoRouter
+		// events tells if only stream and metrics are registered
+		events bool
+	}{
+		{name: "config", routes: ConfigRoutes},
+		{name: "events", routes: EventsRoutes, events: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			api.RegisterHandlers(tt.routes(e.Group("/api/1.0")), h)
+			var names []string
+			for _, route := range e.Routes() {
+				names = append(names, route.Name)
+				if eventsOperations[route.Name] != tt.events {
+					t.Errorf("%s %s was registered", route.Name, route.Path)
+				}
+			}
+			if tt.events && len(names) != len(eventsOperations) {
+				t.Errorf("got routes %v, want %d events routes", names, len(eventsOperations))
+			}
+			if !tt.events && len(names) == 0 {
+				t.Error("no config routes were registered")
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ServeOnAnyHost replaces servers of the spec with ones matching requests to basePaths
+// on any host and scheme. kin-openapi can't route relative server urls, so requests
+// are only validated when host and port match one of the urls in api.yaml
+func ServeOnAnyHost(swagger *openapi3.T, basePaths ...string) {
+	swagger.Servers = make(openapi3.Servers, 0, len(basePaths))
+	for _, basePath := range basePaths {
+		swagger.Servers = append(swagger.Servers, &openapi3.Server{
+			// empty host isn't matched by the router
+			URL: "{scheme}://" + basePath,
+			Variables: map[string]*openapi3.ServerVariable{
+				"scheme": {Default: "http", Enum: []string{"http", "https"}},
+			},
+		})
+	}
+}
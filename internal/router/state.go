@@ -0,0 +1,443 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/drone/ff-mock-server/internal/replay"
+	"github.com/drone/ff-mock-server/internal/repository"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/admin"
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	// ErrSnapshotExists is returned when snapshot with the name was already saved
+	ErrSnapshotExists = errors.New("snapshot already exists")
+	// ErrSnapshotNotFound is returned when there is no snapshot with the name
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+)
+
+// State is everything tests change through the admin API exported as one document,
+// an imported document must have every section except the replay session. Signing
+// keys and expired or rejected tokens aren't part of it, keys are private and the
+// tokens belong to the running server
+type State struct {
+	Flags            []api.FeatureConfig         `json:"flags"`
+	Segments         []api.Segment               `json:"segments"`
+	APIKeys          []service.APIKey            `json:"apiKeys"`
+	TokenOptions     *service.TokenOptions       `json:"tokenOptions,omitempty"`
+	Faults           []admin.FaultRule           `json:"faults"`
+	EventsFaults     []admin.FaultRule           `json:"eventsFaults"`
+	RateLimits       []admin.RateLimit           `json:"rateLimits"`
+	NetworkFaults    []admin.NetworkFault        `json:"networkFaults"`
+	StreamsAvailable *bool                       `json:"streamsAvailable,omitempty"`
+	EventsAvailable  *bool                       `json:"eventsAvailable,omitempty"`
+	ETagMode         *string                     `json:"etagMode,omitempty"`
+	Compression      *service.CompressionOptions `json:"compression,omitempty"`
+	StreamScript     *StreamScript               `json:"streamScript,omitempty"`
+	// ReplaySession has entries of the replayed session left to play, it is only
+	// exported and imported when the server replays a session
+	ReplaySession *replay.Session `json:"replaySession,omitempty"`
+}
+
+// Snapshot is state saved under a name
+type Snapshot struct {
+	Name  string    `json:"name"`
+	Time  time.Time `json:"time"`
+	State State     `json:"-"`
+}
+
+// snapshots is a stack of saved states, reset restores the latest one
+type snapshots struct {
+	mu    sync.Mutex
+	stack []Snapshot
+	seq   int
+}
+
+// State returns the current state, faults and keys are exported even when
+// there are none so importing it replaces everything
+func (h *AdminHandler) State() State {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.currentState()
+}
+
+// currentState returns the current state, caller must hold the lock
+func (h *AdminHandler) currentState() State {
+	streamsAvailable := h.handler.StreamsAvailable()
+	eventsAvailable := h.events.Availability.Available()
+	tokenOptions := h.auth.TokenOptions()
+	etagMode := h.handler.ETagMode()
+	compression := h.compression.Options()
+	streamScript := h.handler.StreamScript()
+	var replaySession *replay.Session
+	if h.player != nil {
+		session := h.player.Session()
+		replaySession = &session
+	}
+	return State{
+		Flags:            h.store.GetFlagConfigurations(),
+		Segments:         h.store.GetTargetGroups(),
+		APIKeys:          h.auth.Keys(),
+		TokenOptions:     &tokenOptions,
+		Faults:           toAdminFaults(h.faults.Rules()),
+		EventsFaults:     toAdminFaults(h.events.Faults.Rules()),
+		RateLimits:       toAdminRateLimits(h.rateLimits.Limits()),
+		NetworkFaults:    toAdminNetworkFaults(h.network.Faults()),
+		StreamsAvailable: &streamsAvailable,
+		EventsAvailable:  &eventsAvailable,
+		ETagMode:         &etagMode,
+		Compression:      &compression,
+		StreamScript:     &streamScript,
+		ReplaySession:    replaySession,
+	}
+}
+
+// ReplaceState validates the whole state before it replaces the current one, so an
+// invalid document changes nothing. Connected SDKs are notified about changed flags
+// and segments
+func (h *AdminHandler) ReplaceState(state State) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.replaceState(state)
+}
+
+// replaceState replaces the current state, caller must hold the lock so other
+// admin requests don't interleave with it
+func (h *AdminHandler) replaceState(state State) error {
+	if missing := state.missing(); len(missing) > 0 {
+		return fmt.Errorf("state misses %s, import a document exported from /admin/state",
+			strings.Join(missing, ", "))
+	}
+	faults, err := fromAdminFaults(state.Faults)
+	if err != nil {
+		return err
+	}
+	eventsFaults, err := fromAdminFaults(state.EventsFaults)
+	if err != nil {
+		return fmt.Errorf("events fault: %w", err)
+	}
+	rateLimits := make([]service.RateLimit, 0, len(state.RateLimits))
+	for _, request := range state.RateLimits {
+		limit, err := fromAdminRateLimit(request)
+		if err != nil {
+			return fmt.Errorf("rate limit: %w", err)
+		}
+		rateLimits = append(rateLimits, limit)
+	}
+	networkFaults := make([]service.NetworkFault, 0, len(state.NetworkFaults))
+	for _, request := range state.NetworkFaults {
+		fault, err := fromAdminNetworkFault(request)
+		if err != nil {
+			return fmt.Errorf("network fault: %w", err)
+		}
+		networkFaults = append(networkFaults, fault)
+	}
+	flags := make([]api.FeatureConfig, 0, len(state.Flags))
+	for _, fc := range state.Flags {
+		if fc.Project == "" {
+			fc.Project = internal.Project
+		}
+		if fc.Environment == "" {
+			fc.Environment = internal.Environment
+		}
+		if err := repository.ValidateFeatureConfig(fc); err != nil {
+			return err
+		}
+		flags = append(flags, fc)
+	}
+	for _, segment := range state.Segments {
+		if segment.Identifier == "" {
+			return errors.New("target group identifier is required")
+		}
+	}
+	if err := service.ValidateETagMode(*state.ETagMode); err != nil {
+		return err
+	}
+	if err := state.Compression.Validate(); err != nil {
+		return err
+	}
+	if err := service.ValidateAPIKeys(state.APIKeys); err != nil {
+		return err
+	}
+	if err := state.StreamScript.Validate(); err != nil {
+		return err
+	}
+	if state.ReplaySession != nil {
+		if h.player == nil {
+			return errors.New("replay session can only be imported when the server replays a session")
+		}
+		if err := state.ReplaySession.Validate(); err != nil {
+			return fmt.Errorf("replay session: %w", err)
+		}
+	}
+
+	// everything is valid, nothing below fails
+	changes, err := h.store.Replace(repository.Dataset{Flags: flags, Segments: state.Segments})
+	if err != nil {
+		return err
+	}
+	_ = h.auth.SetKeys(state.APIKeys)
+	h.auth.SetTokenOptions(*state.TokenOptions)
+	_ = h.handler.SetETagMode(*state.ETagMode)
+	_ = h.compression.SetOptions(*state.Compression)
+	_ = h.handler.SetStreamScript(*state.StreamScript)
+	if state.ReplaySession != nil {
+		h.player.Replace(state.ReplaySession)
+	}
+	h.faults.Replace(faults)
+	h.events.Faults.Replace(eventsFaults)
+	h.rateLimits.Replace(rateLimits)
+	h.network.Replace(networkFaults)
+	h.handler.SetStreamsAvailable(*state.StreamsAvailable)
+	h.events.Availability.SetAvailable(*state.EventsAvailable)
+	if !*state.EventsAvailable {
+		h.handler.DisconnectStreams("")
+	}
+	return h.notifyChanges(changes)
+}
+
+// missing returns names of the sections the state doesn't have, the replay
+// session is optional
+func (s State) missing() []string {
+	var missing []string
+	sections := []struct {
+		name    string
+		missing bool
+	}{
+		{"flags", s.Flags == nil},
+		{"segments", s.Segments == nil},
+		{"apiKeys", s.APIKeys == nil},
+		{"tokenOptions", s.TokenOptions == nil},
+		{"faults", s.Faults == nil},
+		{"eventsFaults", s.EventsFaults == nil},
+		{"rateLimits", s.RateLimits == nil},
+		{"networkFaults", s.NetworkFaults == nil},
+		{"streamsAvailable", s.StreamsAvailable == nil},
+		{"eventsAvailable", s.EventsAvailable == nil},
+		{"etagMode", s.ETagMode == nil},
+		{"compression", s.Compression == nil},
+		{"streamScript", s.StreamScript == nil},
+	}
+	for _, section := range sections {
+		if section.missing {
+			missing = append(missing, section.name)
+		}
+	}
+	return missing
+}
+
+// notifyChanges publishes events for flags and segments changed by ReplaceState
+func (h *AdminHandler) notifyChanges(changes repository.Changes) error {
+	for _, fc := range changes.Flags {
+		if err := h.handler.NotifyChange(dto.StreamDomainFlag, fc.Feature, dto.StreamEventPatch,
+			*fc.Version); err != nil {
+			return err
+		}
+	}
+	for _, fc := range changes.DeletedFlags {
+		if err := h.handler.NotifyChange(dto.StreamDomainFlag, fc.Feature, dto.StreamEventDelete,
+			version(fc.Version)+1); err != nil {
+			return err
+		}
+	}
+	for _, segment := range changes.Segments {
+		if err := h.handler.NotifyChange(dto.StreamDomainTargetSegment, segment.Identifier, dto.StreamEventPatch,
+			*segment.Version); err != nil {
+			return err
+		}
+	}
+	for _, segment := range changes.DeletedSegments {
+		if err := h.handler.NotifyChange(dto.StreamDomainTargetSegment, segment.Identifier, dto.StreamEventDelete,
+			version(segment.Version)+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Snapshot saves the current state on top of the stack, the name is generated
+// when it is empty
+func (h *AdminHandler) Snapshot(name string) (Snapshot, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.snapshot(name)
+}
+
+// snapshot saves the current state, caller must hold the lock
+func (h *AdminHandler) snapshot(name string) (Snapshot, error) {
+	h.snapshots.mu.Lock()
+	defer h.snapshots.mu.Unlock()
+	h.snapshots.seq++
+	if name == "" {
+		name = strconv.Itoa(h.snapshots.seq)
+	}
+	if h.snapshots.find(name) >= 0 {
+		return Snapshot{}, ErrSnapshotExists
+	}
+	snapshot := Snapshot{Name: name, Time: h.clock.Now(), State: h.currentState()}
+	h.snapshots.stack = append(h.snapshots.stack, snapshot)
+	return snapshot, nil
+}
+
+// Rollback restores the snapshot and removes snapshots saved after it, empty
+// name restores the latest one
+func (h *AdminHandler) Rollback(name string) (State, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.rollback(name)
+}
+
+// rollback restores the snapshot, caller must hold the lock
+func (h *AdminHandler) rollback(name string) (State, error) {
+	h.snapshots.mu.Lock()
+	defer h.snapshots.mu.Unlock()
+	i := len(h.snapshots.stack) - 1
+	if name != "" {
+		i = h.snapshots.find(name)
+	}
+	if i < 0 {
+		return State{}, ErrSnapshotNotFound
+	}
+	state := h.snapshots.stack[i].State
+	if err := h.replaceState(state); err != nil {
+		return State{}, err
+	}
+	h.snapshots.stack = h.snapshots.stack[:i+1]
+	return h.currentState(), nil
+}
+
+// Serialize returns middleware running admin requests changing the state one at a
+// time, so they never interleave with an import or rollback
+func (h *AdminHandler) Serialize() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Method == http.MethodGet {
+				h.mu.RLock()
+				defer h.mu.RUnlock()
+			} else {
+				h.mu.Lock()
+				defer h.mu.Unlock()
+			}
+			return next(c)
+		}
+	}
+}
+
+// find returns index of the snapshot, -1 is returned when it doesn't exist,
+// caller must hold the lock
+func (s *snapshots) find(name string) int {
+	for i, snapshot := range s.stack {
+		if snapshot.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ExportState returns the current state
+func (h *AdminHandler) ExportState(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, h.currentState())
+}
+
+// ImportState replaces the current state with the document
+func (h *AdminHandler) ImportState(ctx echo.Context) error {
+	state := State{}
+	if err := ctx.Bind(&state); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.replaceState(state); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, h.currentState())
+}
+
+// GetSnapshots returns saved snapshots, the latest is the last one
+func (h *AdminHandler) GetSnapshots(ctx echo.Context) error {
+	h.snapshots.mu.Lock()
+	defer h.snapshots.mu.Unlock()
+	result := make([]admin.Snapshot, 0, len(h.snapshots.stack))
+	for _, snapshot := range h.snapshots.stack {
+		result = append(result, admin.Snapshot{Name: snapshot.Name, Time: snapshot.Time})
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// SaveSnapshot saves the current state on top of the stack
+func (h *AdminHandler) SaveSnapshot(ctx echo.Context) error {
+	request := admin.SnapshotRequest{}
+	if err := ctx.Bind(&request); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	name := ""
+	if request.Name != nil {
+		name = *request.Name
+	}
+	snapshot, err := h.snapshot(name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return ctx.JSON(http.StatusCreated, admin.Snapshot{Name: snapshot.Name, Time: snapshot.Time})
+}
+
+// DeleteSnapshot removes the snapshot
+func (h *AdminHandler) DeleteSnapshot(ctx echo.Context, name admin.SnapshotPathParam) error {
+	h.snapshots.mu.Lock()
+	defer h.snapshots.mu.Unlock()
+	i := h.snapshots.find(string(name))
+	if i < 0 {
+		return echo.NewHTTPError(http.StatusNotFound, ErrSnapshotNotFound.Error())
+	}
+	h.snapshots.stack = append(h.snapshots.stack[:i], h.snapshots.stack[i+1:]...)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// RestoreSnapshot restores the snapshot, snapshots saved after it are removed
+func (h *AdminHandler) RestoreSnapshot(ctx echo.Context, name admin.SnapshotPathParam) error {
+	return rollback(ctx, h, string(name))
+}
+
+// ResetState restores the latest snapshot
+func (h *AdminHandler) ResetState(ctx echo.Context) error {
+	return rollback(ctx, h, "")
+}
+
+func rollback(ctx echo.Context, h *AdminHandler, name string) error {
+	state, err := h.rollback(name)
+	switch {
+	case errors.Is(err, ErrSnapshotNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case err != nil:
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, state)
+}
+
+func toAdminFaults(rules []service.FaultRule) []admin.FaultRule {
+	result := make([]admin.FaultRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toAdminFault(rule))
+	}
+	return result
+}
+
+func fromAdminFaults(rules []admin.FaultRule) ([]service.FaultRule, error) {
+	result := make([]service.FaultRule, 0, len(rules))
+	for _, request := range rules {
+		rule, err := fromAdminFault(request)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
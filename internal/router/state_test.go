@@ -0,0 +1,104 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/admin"
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSerialize(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+	}{
+		{"write waits for import", http.MethodPost},
+		{"read waits for import", http.MethodGet},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &AdminHandler{}
+			handler := h.Serialize()(func(c echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			})
+
+			// an import holds the lock while it applies the state
+			h.mu.Lock()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				c := echo.New().NewContext(httptest.NewRequest(tt.method, "/admin/faults", nil), httptest.NewRecorder())
+				_ = handler(c)
+			}()
+			select {
+			case <-done:
+				t.Fatal("request was served during the import")
+			case <-time.After(50 * time.Millisecond):
+			}
+			h.mu.Unlock()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("request wasn't served after the import")
+			}
+		})
+	}
+}
+
+func TestSerializeReadsRunTogether(t *testing.T) {
+	h := &AdminHandler{}
+	handler := h.Serialize()(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	// an export holds the read lock, other reads don't wait for it
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/admin/state", nil), httptest.NewRecorder())
+		_ = handler(c)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("read waited for another read")
+	}
+}
+
+func TestStateMissing(t *testing.T) {
+	available := true
+	mode := "strong"
+	tests := []struct {
+		name  string
+		state State
+		want  int
+	}{
+		{"empty document", State{}, 13},
+		{"lists without settings", State{
+			Flags: []api.FeatureConfig{}, Segments: []api.Segment{}, APIKeys: []service.APIKey{},
+			Faults: []admin.FaultRule{}, EventsFaults: []admin.FaultRule{}, RateLimits: []admin.RateLimit{},
+			NetworkFaults: []admin.NetworkFault{},
+		}, 6},
+		{"complete document", State{
+			Flags: []api.FeatureConfig{}, Segments: []api.Segment{}, APIKeys: []service.APIKey{},
+			TokenOptions: &service.TokenOptions{}, Faults: []admin.FaultRule{}, EventsFaults: []admin.FaultRule{},
+			RateLimits: []admin.RateLimit{}, NetworkFaults: []admin.NetworkFault{}, StreamsAvailable: &available,
+			EventsAvailable: &available, ETagMode: &mode, Compression: &service.CompressionOptions{},
+			StreamScript: &StreamScript{},
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if missing := tt.state.missing(); len(missing) != tt.want {
+				t.Errorf("got missing sections %v, want %d", missing, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/r3labs/sse/v2"
+)
+
+// StreamScript takes streams offline like the --sse and --sse-out options do
+type StreamScript struct {
+	// OffSequence is seconds streams stay connected before they are closed, every
+	// connection uses the next entry
+	OffSequence []int `json:"offSequence"`
+	// OffDuration is seconds the stream endpoint responds with 503 after a stream
+	// was closed
+	OffDuration *int `json:"offDuration,omitempty"`
+}
+
+// Validate checks the script has no negative seconds
+func (s StreamScript) Validate() error {
+	for _, seconds := range s.OffSequence {
+		if seconds < 0 {
+			return errors.New("stream off sequence must not have negative seconds")
+		}
+	}
+	if s.OffDuration != nil && *s.OffDuration < 0 {
+		return errors.New("stream off duration must not be negative")
+	}
+	return nil
+}
+
+// streams tracks connected streams and availability of the stream endpoint
+type streams struct {
+	mu          sync.Mutex
+	connections map[string]int
+	unavailable uint32
+	script      StreamScript
+}
+
+func (s *streams) connected(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connections[key]++
+}
+
+func (s *streams) disconnected(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connections[key]--
+	if s.connections[key] <= 0 {
+		delete(s.connections, key)
+	}
+}
+
+// StreamConnections is number of connections on the stream of an api key
+type StreamConnections struct {
+	Key         string `json:"key"`
+	Connections int    `json:"connections"`
+}
+
+// Streams returns connected streams sorted by api key
+func (h *Handler) Streams() []StreamConnections {
+	h.streams.mu.Lock()
+	defer h.streams.mu.Unlock()
+	result := make([]StreamConnections, 0, len(h.streams.connections))
+	for key, connections := range h.streams.connections {
+		result = append(result, StreamConnections{Key: key, Connections: connections})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// PublishEvent sends the event to the stream of the api key, empty key sends
+// it to streams of all api keys
+func (h *Handler) PublishEvent(key string, event dto.StreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	keys := []string{key}
+	if key == "" {
+		keys = keys[:0]
+		for _, apiKey := range h.auth.Keys() {
+			keys = append(keys, apiKey.Key)
+		}
+	}
+	for _, k := range keys {
+		if h.eventSource.StreamExists(k) {
+			h.telemetry.EventPublished(event.Domain)
+		}
+		h.eventSource.Publish(k, &sse.Event{Data: data})
+	}
+	return nil
+}
+
+// NotifyChange publishes patch or delete event of a flag or target group to streams
+// of all api keys, SDKs reload the changed item when they receive it
+func (h *Handler) NotifyChange(domain, identifier, event string, version int64) error {
+	return h.PublishEvent("", dto.StreamEvent{
+		Event:      event,
+		Domain:     domain,
+		Identifier: identifier,
+		Version:    version,
+	})
+}
+
+// DisconnectStreams closes the stream of the api key, empty key closes all
+// streams. SDKs are expected to reconnect
+func (h *Handler) DisconnectStreams(key string) {
+	if key == "" {
+		h.eventSource.Close()
+		return
+	}
+	h.eventSource.RemoveStream(key)
+}
+
+// StreamScript returns the script taking streams offline
+func (h *Handler) StreamScript() StreamScript {
+	h.streams.mu.Lock()
+	defer h.streams.mu.Unlock()
+	script := h.streams.script
+	script.OffSequence = append([]int{}, script.OffSequence...)
+	return script
+}
+
+// SetStreamScript replaces the script, the next stream starts from the beginning
+// of the off sequence
+func (h *Handler) SetStreamScript(script StreamScript) error {
+	if err := script.Validate(); err != nil {
+		return err
+	}
+	h.streams.mu.Lock()
+	defer h.streams.mu.Unlock()
+	h.streams.script = script
+	atomic.StoreUint32(&h.sseSeq, 0)
+	return nil
+}
+
+// StreamsAvailable returns false when the stream endpoint responds with 503
+func (h *Handler) StreamsAvailable() bool {
+	return atomic.LoadUint32(&h.streams.unavailable) == 0
+}
+
+// SetStreamsAvailable makes the stream endpoint respond with 503 when available
+// is false, connected streams are closed
+func (h *Handler) SetStreamsAvailable(available bool) {
+	if available {
+		atomic.StoreUint32(&h.streams.unavailable, 0)
+		return
+	}
+	atomic.StoreUint32(&h.streams.unavailable, 1)
+	h.DisconnectStreams("")
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"errors"
+)
+
+// TenantHeader selects tenant serving the request, requests with tenant api keys
+// or tokens issued for them are routed to the tenant without it
+const TenantHeader = "X-Mock-Tenant"
+
+var (
+	// ErrTenantExists is returned when tenant with the id was already created
+	ErrTenantExists = errors.New("tenant already exists")
+	// ErrTenantIDInvalid is returned for ids with other characters than letters, digits and dashes
+	ErrTenantIDInvalid = errors.New("tenant id must have letters, digits and dashes only")
+)
+
+// Tenant has its own api keys, environment, flags, faults, streams and journal so
+// CI jobs sharing one server don't change each other's state
+type Tenant struct {
+	ID          string `json:"id"`
+	Environment string `json:"environment"`
+	ServerKey   string `json:"serverKey"`
+	ClientKey   string `json:"clientKey"`
+	ProxyKey    string `json:"proxyKey"`
+}
+
+// Tenants creates and removes tenants of the server
+type Tenants interface {
+	// CreateTenant creates tenant with the id, the id is generated when it is empty
+	CreateTenant(id string) (Tenant, error)
+	Tenants() []Tenant
+	Tenant(id string) (Tenant, bool)
+	// DeleteTenant disconnects streams of the tenant and drops its state
+	DeleteTenant(id string) bool
+}
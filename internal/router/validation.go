@@ -0,0 +1,117 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+// Response validation modes
+const (
+	ResponseValidationOff  = "off"
+	ResponseValidationLog  = "log"
+	ResponseValidationFail = "fail"
+)
+
+// ValidateResponses validates status, headers and body of every response against
+// the spec. Invalid responses are logged, in fail mode they are replaced with 500
+// so drift between fixtures and the real service is noticed in SDK tests. Error is
+// returned when routes of the spec can't be built
+func ValidateResponses(swagger *openapi3.T, mode string) (echo.MiddlewareFunc, error) {
+	specRouter, err := gorillamux.NewRouter(swagger)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route, pathParams, err := specRouter.FindRoute(req)
+			if err != nil || route.Operation.OperationID == "Stream" {
+				// unknown routes are reported by the request validator and
+				// streams can't be buffered
+				return next(c)
+			}
+
+			res := c.Response()
+			writer := &bufferedWriter{ResponseWriter: res.Writer, status: http.StatusOK}
+			res.Writer = writer
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			res.Writer = writer.ResponseWriter
+
+			if fault, _ := c.Get(faultKey).(bool); fault {
+				return writer.flush()
+			}
+
+			input := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    req,
+					PathParams: pathParams,
+					Route:      route,
+				},
+				Status: writer.status,
+				Header: writer.Header(),
+				Options: &openapi3filter.Options{
+					IncludeResponseStatus: true,
+				},
+			}
+			input.SetBodyBytes(writer.body.Bytes())
+
+			err = openapi3filter.ValidateResponse(req.Context(), input)
+			if err == nil {
+				return writer.flush()
+			}
+
+			message := strings.Split(err.Error(), "\n")[0]
+			logger(c).Errorf("response of %s doesn't match api.yaml: %s", route.Operation.OperationID, message)
+			if mode != ResponseValidationFail {
+				return writer.flush()
+			}
+			writer.status = http.StatusInternalServerError
+			writer.body.Reset()
+			writer.Header().Del(echo.HeaderContentLength)
+			writer.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+			if err := json.NewEncoder(&writer.body).Encode(api.Error{
+				Code:    strconv.Itoa(http.StatusInternalServerError),
+				Message: "response validation failed: " + message,
+			}); err != nil {
+				return err
+			}
+			return writer.flush()
+		}
+	}, nil
+}
+
+// bufferedWriter keeps the response in memory until it is validated
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// Flush is a no-op, the response is written at once after validation
+func (w *bufferedWriter) Flush() {}
+
+func (w *bufferedWriter) flush() error {
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.body.Bytes())
+	return err
+}
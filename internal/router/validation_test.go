@@ -0,0 +1,81 @@
+package router
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+func TestValidateResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		status int
+		body   string
+		// want is the status sent to the client
+		want int
+		// reported tells if the response is logged as invalid
+		reported bool
+	}{
+		{name: "declared response", mode: ResponseValidationFail, status: http.StatusOK, body: `[]`, want: http.StatusOK},
+		{name: "undeclared status fails", mode: ResponseValidationFail, status: http.StatusTeapot, body: `[]`,
+			want: http.StatusInternalServerError, reported: true},
+		{name: "undeclared body fails", mode: ResponseValidationFail, status: http.StatusOK, body: `{"flags": 1}`,
+			want: http.StatusInternalServerError, reported: true},
+		{name: "undeclared status is logged", mode: ResponseValidationLog, status: http.StatusTeapot, body: `[]`,
+			want: http.StatusTeapot, reported: true},
+		{name: "undeclared body is logged", mode: ResponseValidationLog, status: http.StatusOK, body: `{"flags": 1}`,
+			want: http.StatusOK, reported: true},
+		{name: "undeclared status passes when off", mode: ResponseValidationOff, status: http.StatusTeapot, body: `[]`,
+			want: http.StatusTeapot},
+		{name: "undeclared body passes when off", mode: ResponseValidationOff, status: http.StatusOK, body: `{"flags": 1}`,
+			want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swagger, err := api.GetSwagger()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ServeOnAnyHost(swagger, "/api/1.0")
+
+			var logs bytes.Buffer
+			log := logrus.New()
+			log.SetOutput(&logs)
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set(loggerKey, log)
+					return next(c)
+				}
+			})
+			// the server installs the middleware only when validation is on
+			if tt.mode != ResponseValidationOff {
+				validate, err := ValidateResponses(swagger, tt.mode)
+				if err != nil {
+					t.Fatal(err)
+				}
+				e.Use(validate)
+			}
+			e.GET("/api/1.0/client/env/:environmentUUID/feature-configs", func(c echo.Context) error {
+				return c.JSONBlob(tt.status, []byte(tt.body))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/1.0/client/env/env/feature-configs", nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("got status %d, want %d", rec.Code, tt.want)
+			}
+			if reported := strings.Contains(logs.String(), "doesn't match api.yaml"); reported != tt.reported {
+				t.Errorf("got logs %q, want reported %t", logs.String(), tt.reported)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Start listens on all configured addresses and serves requests in background,
+// error is returned when any of the addresses can't be listened on
+func (s *Server) Start() error {
+	listeners, err := listen(s.listen)
+	if err != nil {
+		return err
+	}
+	if s.Events != s.Echo {
+		eventsListeners, err := listen(s.eventsListen)
+		if err != nil {
+			closeListeners(listeners)
+			return err
+		}
+		s.serve(s.Events, eventsListeners)
+	}
+	s.serve(s.Echo, listeners)
+	return nil
+}
+
+// Shutdown gracefully stops all listeners
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.Events != s.Echo {
+		if err := s.Events.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	return s.Echo.Shutdown(ctx)
+}
+
+// TLS returns whether requests are served over TLS
+func (s *Server) TLS() bool {
+	return s.tls != nil
+}
+
+func (s *Server) serve(e *echo.Echo, listeners []net.Listener) {
+	e.Server.Handler = e
+	e.Server.TLSConfig = s.tls
+	if s.tls == nil && s.h2c {
+		e.Server.Handler = h2c.NewHandler(e, &http2.Server{})
+	}
+	if s.disableHTTP2 {
+		// non-nil map disables HTTP/2 over TLS
+		e.Server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			var err error
+			if s.tls != nil {
+				err = e.Server.ServeTLS(l, "", "")
+			} else {
+				err = e.Server.Serve(l)
+			}
+			if err != nil && err != http.ErrServerClosed {
+				s.Logger.Errorf("serving %s: %s", l.Addr(), err)
+			}
+		}(l)
+	}
+}
+
+// listen opens listeners of all addresses, none is left open on error
+func listen(addresses []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addresses))
+	for _, address := range addresses {
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			closeListeners(listeners)
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+func closeListeners(listeners []net.Listener) {
+	for _, l := range listeners {
+		_ = l.Close()
+	}
+}
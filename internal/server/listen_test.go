@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/drone/ff-mock-server/internal/config"
+	"golang.org/x/net/http2"
+)
+
+// startTestServer serves the client and admin API on a free port of localhost
+// and returns its address
+func startTestServer(t *testing.T, options config.Config) string {
+	t.Helper()
+	s, err := New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Logger.SetOutput(io.Discard)
+	// failed handshakes are logged by the http server
+	s.Echo.Server.ErrorLog = log.New(io.Discard, "", 0)
+	listeners, err := listen([]string{"127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.serve(s.Echo, listeners)
+	t.Cleanup(func() {
+		_ = s.Shutdown(context.Background())
+		s.Close()
+	})
+	return listeners[0].Addr().String()
+}
+
+// caPool returns pool with the PEM encoded certificates of the file
+func caPool(t *testing.T, filename string) *x509.CertPool {
+	t.Helper()
+	bundle, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		t.Fatalf("%s has no certificates", filename)
+	}
+	return pool
+}
+
+func TestTLSListeners(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientAuth   bool
+		clientCert   bool
+		disableHTTP2 bool
+		// proto is protocol of the response, empty when the request fails
+		proto int
+	}{
+		{name: "self-signed", proto: 2},
+		{name: "http2 disabled", disableHTTP2: true, proto: 1},
+		{name: "client certificate", clientAuth: true, clientCert: true, proto: 2},
+		{name: "client certificate missing", clientAuth: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			options := config.Default()
+			options.TLSSelfSigned = true
+			options.TLSCAOut = filepath.Join(dir, "ca.pem")
+			options.TLSClientAuth = tt.clientAuth
+			if tt.clientAuth {
+				options.TLSClientCertOut = filepath.Join(dir, "client.pem")
+			}
+			options.DisableHTTP2 = tt.disableHTTP2
+			address := startTestServer(t, options)
+
+			cfg := &tls.Config{RootCAs: caPool(t, options.TLSCAOut), MinVersion: tls.VersionTLS12}
+			if tt.clientCert {
+				// the file has the certificate followed by its key
+				cert, err := tls.LoadX509KeyPair(options.TLSClientCertOut, options.TLSClientCertOut)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cfg.Certificates = []tls.Certificate{cert}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg, ForceAttemptHTTP2: true}}
+			res, err := client.Get("https://" + address + "/admin/clock")
+			if tt.proto == 0 {
+				if err == nil {
+					res.Body.Close()
+					t.Fatal("request without client certificate was served")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("got status %d, want %d", res.StatusCode, http.StatusOK)
+			}
+			if res.ProtoMajor != tt.proto {
+				t.Errorf("got HTTP/%d, want HTTP/%d", res.ProtoMajor, tt.proto)
+			}
+		})
+	}
+}
+
+func TestH2CListener(t *testing.T) {
+	tests := []struct {
+		name   string
+		h2c    bool
+		served bool
+	}{
+		{name: "h2c", h2c: true, served: true},
+		{name: "http/1.1 only", h2c: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := config.Default()
+			options.H2C = tt.h2c
+			address := startTestServer(t, options)
+
+			// prior knowledge client speaks HTTP/2 over plain TCP
+			client := &http.Client{Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+					return net.Dial(network, addr)
+				},
+			}}
+			res, err := client.Get("http://" + address + "/admin/clock")
+			if !tt.served {
+				if err == nil {
+					res.Body.Close()
+					t.Fatal("HTTP/2 request was served without h2c")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusOK || res.ProtoMajor != 2 {
+				t.Errorf("got status %d over HTTP/%d, want 200 over HTTP/2", res.StatusCode, res.ProtoMajor)
+			}
+		})
+	}
+}
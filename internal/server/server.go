@@ -0,0 +1,499 @@
+package server
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	oapimdl "github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/config"
+	"github.com/drone/ff-mock-server/internal/generator"
+	"github.com/drone/ff-mock-server/internal/replay"
+	"github.com/drone/ff-mock-server/internal/repository"
+	"github.com/drone/ff-mock-server/internal/router"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/admin"
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/r3labs/sse/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// Server holds the echo instances serving client and admin API together with
+// the state shared by handlers, tests change the state at runtime
+type Server struct {
+	Echo *echo.Echo
+	// Events serves stream and metrics, it is Echo unless events are served on
+	// separate listeners
+	Events     *echo.Echo
+	Repository *repository.DummyRepository
+	Auth       *service.Auth
+	Faults     *service.Faults
+	// EventsFaults and EventsAvailability apply to stream and metrics only
+	EventsFaults       *service.Faults
+	EventsAvailability *service.Availability
+	Journal            *service.Journal
+	Metrics            *service.ReceivedMetrics
+	Telemetry          *service.Telemetry
+	Clock              *clock.Clock
+	ETags              *service.ETags
+	Compression        *service.Compression
+	RateLimits         *service.RateLimits
+	NetworkFaults      *service.NetworkFaults
+	EventSource        *sse.Server
+	Handler            *router.Handler
+	Admin              *router.AdminHandler
+	Logger             *logrus.Logger
+
+	tenants      *tenants
+	listen       []string
+	eventsListen []string
+	tls          *tls.Config
+	h2c          bool
+	disableHTTP2 bool
+}
+
+// New returns new Server with routes and middlewares configured from options
+func New(options config.Config) (*Server, error) {
+	logger, err := newLogger(options)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &base{
+		options:        options,
+		logger:         logger,
+		telemetry:      service.NewTelemetry(),
+		basePath:       cleanBasePath(options.BasePath),
+		eventsBasePath: cleanBasePath(options.BasePath),
+	}
+	if options.EventsBasePath != "" {
+		b.eventsBasePath = cleanBasePath(options.EventsBasePath)
+	}
+	b.swagger, err = api.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("loading swagger spec: %w", err)
+	}
+	basePaths := []string{b.basePath}
+	if b.eventsBasePath != b.basePath {
+		basePaths = append(basePaths, b.eventsBasePath)
+	}
+	router.ServeOnAnyHost(b.swagger, basePaths...)
+	b.tenants = newTenants(b)
+
+	apiKeys := service.DefaultAPIKeys()
+	if options.APIKeysFile != "" {
+		keys, err := service.LoadAPIKeys(options.APIKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading api keys: %w", err)
+		}
+		apiKeys = append(apiKeys, keys...)
+	}
+	if options.StateFile != "" {
+		if b.state, err = loadState(options.StateFile); err != nil {
+			return nil, fmt.Errorf("loading state: %w", err)
+		}
+	}
+	tls, err := tlsConfig(options)
+	if err != nil {
+		return nil, fmt.Errorf("configuring tls: %w", err)
+	}
+
+	s, err := newServer(b, apiKeys, options.ReplayFile, b.state)
+	if err != nil {
+		return nil, err
+	}
+	s.Echo.GET("/metrics", echo.WrapHandler(b.telemetry.Handler())).Name = "GetPrometheusMetrics"
+	s.Echo.Pre(b.tenants.route(false))
+	if s.Events != s.Echo {
+		s.Events.Pre(b.tenants.route(true))
+	}
+
+	s.tenants = b.tenants
+	s.listen = options.Listen
+	s.eventsListen = options.EventsListen
+	s.tls = tls
+	s.h2c = options.H2C
+	s.disableHTTP2 = options.DisableHTTP2
+	return s, nil
+}
+
+// initialSnapshot is saved on startup so tests can reset the state without saving one
+const initialSnapshot = "initial"
+
+// base is shared by the server and its tenants
+type base struct {
+	options        config.Config
+	logger         *logrus.Logger
+	swagger        *openapi3.T
+	telemetry      *service.Telemetry
+	tenants        *tenants
+	state          *router.State
+	basePath       string
+	eventsBasePath string
+}
+
+// newServer returns Server with its own state accepting apiKeys, the session is
+// replayed unless replayFile is empty. State replaces the initial state unless
+// it is nil, the result is saved as initial snapshot
+func newServer(b *base, apiKeys []service.APIKey, replayFile string, state *router.State) (*Server, error) {
+	options := b.options
+	clk := clock.New()
+	if _, err := clk.SetSpeed(options.ClockSpeed); err != nil {
+		return nil, fmt.Errorf("setting clock speed: %w", err)
+	}
+
+	var player *replay.Player
+	if replayFile != "" {
+		session, err := replay.LoadSession(replayFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading replay session: %w", err)
+		}
+		player = replay.NewPlayer(session, options.ReplaySpeed, clk)
+	}
+
+	e := newEcho(b.swagger, player, b.logger)
+	events := e
+	if len(options.EventsListen) > 0 {
+		events = newEcho(b.swagger, player, b.logger)
+	}
+
+	signingKey, err := config.GetSigningKey(options)
+	if err != nil {
+		return nil, fmt.Errorf("loading signing key: %w", err)
+	}
+	signer, err := service.NewSigner(options.SigningMethod, signingKey)
+	if err != nil {
+		return nil, fmt.Errorf("creating token signer: %w", err)
+	}
+	e.GET("/.well-known/jwks.json", router.JWKS(signer)).Name = "GetJWKS"
+
+	auth := service.NewAuth(signer, clk, service.TokenOptions{
+		Lifetime:  options.TokenLifetime,
+		Issuer:    options.TokenIssuer,
+		NotBefore: options.TokenNotBefore,
+	}, apiKeys)
+
+	jwtConfig := middleware.JWTConfig{
+		Skipper: func(e echo.Context) bool {
+			jwt, ok := e.Get(internal.JWTKey).(bool)
+			if ok && jwt {
+				return false
+			}
+			return true
+		},
+		ParseTokenFunc: func(token string, c echo.Context) (interface{}, error) {
+			return auth.ParseToken(token)
+		},
+	}
+
+	telemetry := b.telemetry
+	journal := service.NewJournal(options.JournalSize)
+	cliRules, err := cliFaults(options)
+	if err != nil {
+		return nil, err
+	}
+	faults := service.NewFaults(cliRules...)
+	eventsFaults := service.NewFaults(cliEventsFaults(options)...)
+	eventsAvailability := &service.Availability{}
+	limits, err := cliRateLimits(options)
+	if err != nil {
+		return nil, err
+	}
+	rateLimits := service.NewRateLimits(clk, limits...)
+	networkFaults := service.NewNetworkFaults()
+	compression, err := service.NewCompression(service.CompressionOptions{
+		Mode:    options.Compression,
+		MinSize: options.CompressionMinSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	identityService := service.NewIdentityService(config.GetIdentityServiceSecret(), clk)
+	var validateResponses echo.MiddlewareFunc
+	if options.ValidateResponses != router.ResponseValidationOff {
+		validateResponses, err = router.ValidateResponses(b.swagger, options.ValidateResponses)
+		if err != nil {
+			return nil, fmt.Errorf("configuring response validation: %w", err)
+		}
+	}
+
+	clientGroup := func(e *echo.Echo, prefix string) *echo.Group {
+		g := e.Group(prefix)
+		g.Use(router.ObserveRequests(telemetry))
+		g.Use(router.InjectNetworkFaults(networkFaults, telemetry, clk))
+		g.Use(router.Compress(compression))
+		g.Use(router.RecordJournal(journal, clk))
+		g.Use(router.EventsOnly(router.CheckAvailability(eventsAvailability, telemetry)))
+		g.Use(router.InjectFaults(faults, telemetry, clk))
+		g.Use(router.EventsOnly(router.InjectFaults(eventsFaults, telemetry, clk)))
+		if validateResponses != nil {
+			g.Use(validateResponses)
+		}
+		g.Use(oapimdl.OapiRequestValidatorWithOptions(b.swagger, &oapimdl.Options{
+			Options: openapi3filter.Options{
+				AuthenticationFunc: router.JWTValidation,
+			},
+		}))
+		g.Use(middleware.JWTWithConfig(jwtConfig))
+		g.Use(router.ValidateIdentityService(identityService))
+		g.Use(router.ValidateEnvironment())
+		g.Use(router.RateLimit(rateLimits, auth, telemetry, clk))
+		return g
+	}
+
+	eventSource := sse.New()
+	repo, err := newRepository(options)
+	if err != nil {
+		return nil, fmt.Errorf("loading dataset: %w", err)
+	}
+
+	etags, err := service.NewETags(options.ETagMode)
+	if err != nil {
+		return nil, err
+	}
+	metrics := service.NewReceivedMetrics()
+	handler := router.NewHandler(repo, eventSource, auth, metrics, telemetry, clk, etags, options)
+	if events == e && b.eventsBasePath == b.basePath {
+		api.RegisterHandlers(clientGroup(e, b.basePath), handler)
+	} else {
+		api.RegisterHandlers(router.ConfigRoutes(clientGroup(e, b.basePath)), handler)
+		api.RegisterHandlers(router.EventsRoutes(clientGroup(events, b.eventsBasePath)), handler)
+	}
+
+	adminHandler := router.NewAdminHandler(auth, signer, identityService, repo, faults, journal, metrics, handler,
+		&router.Events{Faults: eventsFaults, Availability: eventsAvailability}, clk, compression, rateLimits, networkFaults,
+		player, b.tenants)
+	admin.RegisterHandlers(e.Group("/admin", adminHandler.Serialize()), adminHandler)
+	if state != nil {
+		if err := adminHandler.ReplaceState(*state); err != nil {
+			return nil, fmt.Errorf("replacing state: %w", err)
+		}
+	}
+	if _, err := adminHandler.Snapshot(initialSnapshot); err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		Echo:       e,
+		Events:     events,
+		Repository: repo,
+		Auth:       auth,
+		Faults:     faults,
+
+		EventsFaults:       eventsFaults,
+		EventsAvailability: eventsAvailability,
+		Journal:            journal,
+		Metrics:            metrics,
+		Telemetry:          telemetry,
+		Clock:              clk,
+		ETags:              etags,
+		Compression:        compression,
+		RateLimits:         rateLimits,
+		NetworkFaults:      networkFaults,
+		EventSource:        eventSource,
+		Handler:            handler,
+		Admin:              adminHandler,
+		Logger:             b.logger,
+	}, nil
+}
+
+// newEcho returns echo instance with middlewares and health check shared by
+// the client and events listeners
+func newEcho(swagger *openapi3.T, player *replay.Player, logger *logrus.Logger) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+
+	e.Server.ReadTimeout = 15 * time.Second
+	e.Server.WriteTimeout = 15 * time.Second
+	e.Use(router.RequestLogger(logger))
+	e.Use(router.Recover())
+
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, "Cache-Control",
+			echo.HeaderAuthorization, "api-key", "Pragma"},
+	}))
+
+	if player != nil {
+		e.Use(router.ReplaySession(player))
+	}
+
+	e.GET("/health", HealthCheck).Name = "HealthCheck"
+	e.HTTPErrorHandler = router.ErrorHandler(swagger)
+	return e
+}
+
+// newLogger returns logger writing messages of the configured level and format to stderr
+func newLogger(options config.Config) (*logrus.Logger, error) {
+	level, err := logrus.ParseLevel(options.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	logger := logrus.New()
+	logger.SetLevel(level)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	if options.LogFormat == "text" {
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	}
+	return logger, nil
+}
+
+// cleanBasePath returns base path with leading slash and without trailing one
+func cleanBasePath(basePath string) string {
+	return "/" + strings.Trim(basePath, "/")
+}
+
+// Close closes all streams so connected SDKs are disconnected, streams of
+// tenants too
+func (s *Server) Close() {
+	if s.tenants != nil {
+		s.tenants.close()
+	}
+	s.EventSource.Close()
+}
+
+// SetFlag stores the flag and notifies connected SDKs about the change
+func (s *Server) SetFlag(fc api.FeatureConfig) (api.FeatureConfig, error) {
+	return s.Admin.UpdateFlag(fc)
+}
+
+// DeleteFlag removes the flag and notifies connected SDKs, false is returned
+// when the flag doesn't exist
+func (s *Server) DeleteFlag(identifier string) (bool, error) {
+	return s.Admin.RemoveFlag(identifier)
+}
+
+// SetSegment stores the target group and notifies connected SDKs about the change
+func (s *Server) SetSegment(segment api.Segment) (api.Segment, error) {
+	return s.Admin.UpdateSegment(segment)
+}
+
+// DeleteSegment removes the target group and notifies connected SDKs, false is
+// returned when the target group doesn't exist
+func (s *Server) DeleteSegment(identifier string) (bool, error) {
+	return s.Admin.RemoveSegment(identifier)
+}
+
+// loadState reads state document from the json file
+func loadState(filename string) (*router.State, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+	state := &router.State{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("state file %s: %w", filename, err)
+	}
+	return state, nil
+}
+
+// newRepository returns repository with the dataset loaded from file or generated
+// on startup, the mocked flags and segment are served by default
+func newRepository(options config.Config) (*repository.DummyRepository, error) {
+	if options.DatasetFile != "" {
+		dataset, err := repository.LoadDataset(options.DatasetFile)
+		if err != nil {
+			return nil, err
+		}
+		return repository.NewRepository(dataset)
+	}
+	generate := generator.Options{
+		Seed:     options.GenerateSeed,
+		Flags:    options.GenerateFlags,
+		Segments: options.GenerateSegments,
+		Targets:  options.GenerateTargets,
+		Rules:    options.GenerateRules,
+	}
+	if err := generate.Validate(); err != nil {
+		return nil, err
+	}
+	if generate.Flags > 0 || generate.Segments > 0 {
+		return repository.NewRepository(generator.Generate(generate))
+	}
+	return repository.NewDummyRepository(), nil
+}
+
+// cliFaults returns fault rules of the timeout, status code and operation flags,
+// the timeout only applies to listed operations
+func cliFaults(options config.Config) ([]service.FaultRule, error) {
+	rule := service.FaultRule{
+		Message: options.Message,
+	}
+	if options.StatusCode != nil {
+		rule.Status = *options.StatusCode
+	}
+	if len(options.Handlers) == 0 {
+		if rule.Status == 0 {
+			return nil, nil
+		}
+		return []service.FaultRule{rule}, nil
+	}
+
+	if options.Timeout != nil {
+		rule.Delay = time.Duration(*options.Timeout) * time.Second
+	}
+	rules := make([]service.FaultRule, 0, len(options.Handlers))
+	for _, operation := range options.Handlers {
+		name, err := router.NormalizeOperation(operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation flag: %w", err)
+		}
+		rule.Operation = name
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// cliEventsFaults returns fault rule of the events status code and latency flags
+func cliEventsFaults(options config.Config) []service.FaultRule {
+	if options.EventsStatusCode == 0 && options.EventsLatency == 0 {
+		return nil
+	}
+	return []service.FaultRule{{
+		Status: options.EventsStatusCode,
+		Delay:  time.Duration(options.EventsLatency) * time.Millisecond,
+	}}
+}
+
+// cliRateLimits parses rate limits of the rate limit flags
+func cliRateLimits(options config.Config) ([]service.RateLimit, error) {
+	limits := make([]service.RateLimit, 0, len(options.RateLimits))
+	for _, value := range options.RateLimits {
+		parts := strings.Split(value, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("rate limit %s must be by:rate[:burst]", value)
+		}
+		limit := service.RateLimit{By: parts[0]}
+		var err error
+		if limit.Rate, err = strconv.ParseFloat(parts[1], 64); err != nil {
+			return nil, fmt.Errorf("rate limit %s: %w", value, err)
+		}
+		if len(parts) == 3 {
+			if limit.Burst, err = strconv.Atoi(parts[2]); err != nil {
+				return nil, fmt.Errorf("rate limit %s: %w", value, err)
+			}
+		}
+		if err := limit.Validate(); err != nil {
+			return nil, fmt.Errorf("rate limit %s: %w", value, err)
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
+
+// HealthCheck returns the health of the service
+func HealthCheck(ctx echo.Context) error {
+	return ctx.String(http.StatusOK, "healthy")
+}
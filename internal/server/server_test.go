@@ -0,0 +1,176 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/config"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// testMetrics has the target data the server requires in the first metrics
+const testMetrics = `{"targetData":[{"identifier":"alice","name":"Alice","attributes":[]}]}`
+
+func TestBasePaths(t *testing.T) {
+	type request struct {
+		// events sends the request to the events listener
+		events bool
+		method string
+		path   string
+		want   int
+	}
+	metrics := "/metrics/" + internal.EnvironmentUUID
+	tests := []struct {
+		name           string
+		basePath       string
+		eventsBasePath string
+		eventsListen   []string
+		authPath       string
+		requests       []request
+	}{
+		{
+			name:     "default",
+			authPath: "/api/1.0/client/auth",
+			requests: []request{
+				{method: http.MethodGet, path: "/api/1.0/client/env/" + internal.EnvironmentUUID + "/feature-configs", want: http.StatusOK},
+				{method: http.MethodPost, path: "/api/1.0" + metrics, want: http.StatusOK},
+			},
+		},
+		{
+			name:     "base path",
+			basePath: "ff/api/1.0/",
+			authPath: "/ff/api/1.0/client/auth",
+			requests: []request{
+				{method: http.MethodGet, path: "/ff/api/1.0/client/env/" + internal.EnvironmentUUID + "/feature-configs", want: http.StatusOK},
+				{method: http.MethodPost, path: "/ff/api/1.0" + metrics, want: http.StatusOK},
+				{method: http.MethodGet, path: "/api/1.0/client/env/" + internal.EnvironmentUUID + "/feature-configs", want: http.StatusNotFound},
+			},
+		},
+		{
+			name:           "events base path",
+			basePath:       "/ff/api/1.0",
+			eventsBasePath: "/ff/events/api/1.0",
+			authPath:       "/ff/api/1.0/client/auth",
+			requests: []request{
+				{method: http.MethodPost, path: "/ff/events/api/1.0" + metrics, want: http.StatusOK},
+				{method: http.MethodPost, path: "/ff/api/1.0" + metrics, want: http.StatusNotFound},
+				{method: http.MethodGet, path: "/ff/events/api/1.0/client/env/" + internal.EnvironmentUUID + "/feature-configs", want: http.StatusNotFound},
+			},
+		},
+		{
+			name:         "events listener",
+			eventsListen: []string{"127.0.0.1:0"},
+			authPath:     "/api/1.0/client/auth",
+			requests: []request{
+				{events: true, method: http.MethodPost, path: "/api/1.0" + metrics, want: http.StatusOK},
+				{method: http.MethodPost, path: "/api/1.0" + metrics, want: http.StatusNotFound},
+				{events: true, method: http.MethodGet, path: "/api/1.0/client/env/" + internal.EnvironmentUUID + "/feature-configs", want: http.StatusNotFound},
+				{events: true, method: http.MethodGet, path: "/admin/clock", want: http.StatusNotFound},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := config.Default()
+			if tt.basePath != "" {
+				options.BasePath = tt.basePath
+			}
+			options.EventsBasePath = tt.eventsBasePath
+			options.EventsListen = tt.eventsListen
+			s, err := New(options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Logger.SetOutput(io.Discard)
+			logrus.SetOutput(io.Discard)
+			t.Cleanup(s.Close)
+			if separate := s.Events != s.Echo; separate != (len(tt.eventsListen) > 0) {
+				t.Fatalf("events are served by a separate echo %t", separate)
+			}
+
+			token := testToken(t, s.Echo, tt.authPath)
+			for _, r := range tt.requests {
+				e := s.Echo
+				if r.events {
+					e = s.Events
+				}
+				body := ""
+				if r.method == http.MethodPost {
+					body = testMetrics
+				}
+				req := httptest.NewRequest(r.method, r.path, strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+token)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				if rec.Code != r.want {
+					t.Errorf("%s %s got status %d, want %d: %s", r.method, r.path, rec.Code, r.want, rec.Body)
+				}
+			}
+		})
+	}
+}
+
+// testToken authenticates with the default server key on the path
+func testToken(t *testing.T, e *echo.Echo, path string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"apiKey": "`+internal.ServerKey+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("authentication on %s failed with status %d", path, rec.Code)
+	}
+	var auth struct {
+		AuthToken string `json:"authToken"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &auth); err != nil {
+		t.Fatal(err)
+	}
+	return auth.AuthToken
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	s := newTestServer(t)
+	token, _ := authenticate(t, s, internal.ServerKey)
+	if _, status := authenticate(t, s, "unknown"); status != http.StatusUnauthorized {
+		t.Fatalf("unknown key was authenticated with status %d", status)
+	}
+	s.Faults.Add(service.FaultRule{Operation: "GetFeatureConfig", Status: http.StatusServiceUnavailable, Times: 1})
+	bearer := map[string]string{"Authorization": "Bearer " + token}
+	flags := "/api/1.0/client/env/" + internal.EnvironmentUUID + "/feature-configs"
+	for _, want := range []int{http.StatusServiceUnavailable, http.StatusOK} {
+		if rec := serve(s, "", http.MethodGet, flags, "", bearer); rec.Code != want {
+			t.Fatalf("flags were served with status %d, want %d", rec.Code, want)
+		}
+	}
+	if rec := serve(s, "", http.MethodPost, "/api/1.0/metrics/"+internal.EnvironmentUUID, testMetrics, bearer); rec.Code != http.StatusOK {
+		t.Fatalf("metrics were posted with status %d", rec.Code)
+	}
+
+	rec := serve(s, "", http.MethodGet, "/metrics", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("metrics endpoint answered with status %d", rec.Code)
+	}
+	for _, want := range []string{
+		`ff_mock_authentications_total{key_type="Server",result="success"} 1`,
+		`ff_mock_authentications_total{key_type="unknown",result="failure"} 1`,
+		`ff_mock_requests_total{operation="GetFeatureConfig",status="200"} 1`,
+		`ff_mock_requests_total{operation="GetFeatureConfig",status="503"} 1`,
+		`ff_mock_request_duration_seconds_count{operation="PostMetrics",status="200"} 1`,
+		`ff_mock_faults_total{operation="GetFeatureConfig",status="503"} 1`,
+		`ff_mock_metrics_payloads_total 1`,
+		`ff_mock_stream_connections 0`,
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("metrics miss %s", want)
+		}
+	}
+}
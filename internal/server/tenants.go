@@ -0,0 +1,248 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/drone/ff-mock-server/internal/router"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// tenantKeySeparator separates tenant id from the generated part of its api keys,
+// it only makes the keys readable as requests are routed by tenants.byKey
+const tenantKeySeparator = "_"
+
+var tenantIDPattern = regexp.MustCompile(`^[a-zA-Z0-9-]{1,64}$`)
+
+// tenant is served by its own Server built from the same options without listeners
+type tenant struct {
+	info   router.Tenant
+	server *Server
+}
+
+// tenants keeps tenants of the server and routes their requests
+type tenants struct {
+	base          *base
+	mu            sync.RWMutex
+	byID          map[string]*tenant
+	byEnvironment map[string]*tenant
+	byKey         map[string]*tenant
+}
+
+func newTenants(b *base) *tenants {
+	return &tenants{
+		base:          b,
+		byID:          make(map[string]*tenant),
+		byEnvironment: make(map[string]*tenant),
+		byKey:         make(map[string]*tenant),
+	}
+}
+
+// CreateTenant creates tenant with generated api keys and environment, the dataset
+// and faults configured on the command line apply to it
+func (t *tenants) CreateTenant(id string) (router.Tenant, error) {
+	if id == "" {
+		generated, err := service.NewUUID()
+		if err != nil {
+			return router.Tenant{}, err
+		}
+		id = generated[:8]
+	}
+	if !tenantIDPattern.MatchString(id) {
+		return router.Tenant{}, router.ErrTenantIDInvalid
+	}
+	if t.get(id) != nil {
+		return router.Tenant{}, router.ErrTenantExists
+	}
+
+	environment, err := service.NewUUID()
+	if err != nil {
+		return router.Tenant{}, err
+	}
+	keys, err := service.GenerateAPIKeys(id+tenantKeySeparator, environment)
+	if err != nil {
+		return router.Tenant{}, err
+	}
+	// tenants keep their generated keys and don't replay sessions
+	state := t.base.state
+	if state != nil {
+		tenantState := *state
+		tenantState.APIKeys = keys
+		tenantState.ReplaySession = nil
+		state = &tenantState
+	}
+	s, err := newServer(t.base, keys, "", state)
+	if err != nil {
+		return router.Tenant{}, err
+	}
+	s.Echo.Use(router.WithLogFields(logrus.Fields{"tenant": id}))
+	if s.Events != s.Echo {
+		s.Events.Use(router.WithLogFields(logrus.Fields{"tenant": id}))
+	}
+	created := &tenant{
+		info: router.Tenant{
+			ID:          id,
+			Environment: environment,
+			ServerKey:   keys[0].Key,
+			ClientKey:   keys[1].Key,
+			ProxyKey:    keys[2].Key,
+		},
+		server: s,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, exists := t.byID[id]; exists {
+		return router.Tenant{}, router.ErrTenantExists
+	}
+	t.byID[id] = created
+	t.byEnvironment[environment] = created
+	for _, key := range keys {
+		t.byKey[key.Key] = created
+	}
+	return created.info, nil
+}
+
+// Tenants returns all tenants sorted by id
+func (t *tenants) Tenants() []router.Tenant {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := make([]router.Tenant, 0, len(t.byID))
+	for _, tenant := range t.byID {
+		result = append(result, tenant.info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Tenant returns tenant with the id
+func (t *tenants) Tenant(id string) (router.Tenant, bool) {
+	tenant := t.get(id)
+	if tenant == nil {
+		return router.Tenant{}, false
+	}
+	return tenant.info, true
+}
+
+// DeleteTenant disconnects streams of the tenant, its requests are served by the
+// server from now on
+func (t *tenants) DeleteTenant(id string) bool {
+	t.mu.Lock()
+	tenant, ok := t.byID[id]
+	if ok {
+		delete(t.byID, id)
+		delete(t.byEnvironment, tenant.info.Environment)
+		for _, key := range []string{tenant.info.ServerKey, tenant.info.ClientKey, tenant.info.ProxyKey} {
+			delete(t.byKey, key)
+		}
+	}
+	t.mu.Unlock()
+	if ok {
+		tenant.server.Close()
+	}
+	return ok
+}
+
+// close disconnects streams of all tenants
+func (t *tenants) close() {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, tenant := range t.byID {
+		tenant.server.Close()
+	}
+}
+
+func (t *tenants) get(id string) *tenant {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.byID[id]
+}
+
+// route returns middleware passing requests of tenants to their echo instance,
+// events selects the one serving stream and metrics. Other requests are served
+// by the server
+func (t *tenants) route(events bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			var tenant *tenant
+			if id := req.Header.Get(router.TenantHeader); id != "" {
+				if tenant = t.get(id); tenant == nil {
+					return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("tenant %s not found", id))
+				}
+			} else if tenant = t.match(req); tenant == nil {
+				return next(c)
+			}
+
+			e := tenant.server.Echo
+			if events {
+				e = tenant.server.Events
+			}
+			e.ServeHTTP(c.Response(), req)
+			return nil
+		}
+	}
+}
+
+// match returns tenant of the api key or the token environment of the request,
+// nil is returned for requests of the server
+func (t *tenants) match(req *http.Request) *tenant {
+	t.mu.RLock()
+	empty := len(t.byID) == 0
+	t.mu.RUnlock()
+	if empty {
+		return nil
+	}
+
+	if key := req.Header.Get("API-Key"); key != "" {
+		return t.forKey(key)
+	}
+	if fields := strings.Fields(req.Header.Get(echo.HeaderAuthorization)); len(fields) == 2 {
+		claims := jwt.MapClaims{}
+		if _, _, err := new(jwt.Parser).ParseUnverified(fields[1], claims); err == nil {
+			environment, _ := claims["environment"].(string)
+			t.mu.RLock()
+			defer t.mu.RUnlock()
+			return t.byEnvironment[environment]
+		}
+	}
+	if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/auth") && req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		request := struct {
+			APIKey   string `json:"apiKey"`
+			ProxyKey string `json:"proxyKey"`
+		}{}
+		if err := json.Unmarshal(body, &request); err != nil {
+			return nil
+		}
+		if request.APIKey != "" {
+			return t.forKey(request.APIKey)
+		}
+		return t.forKey(request.ProxyKey)
+	}
+	return nil
+}
+
+// forKey returns tenant the api key was generated for, nil is returned for keys
+// of the server
+func (t *tenants) forKey(key string) *tenant {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.byKey[key]
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/ff-mock-server/internal/config"
+	"github.com/drone/ff-mock-server/internal/router"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestServer returns server with default options logging nothing
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := New(config.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Logger.SetOutput(io.Discard)
+	logrus.SetOutput(io.Discard)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// serve sends the request to the server, tenant selects it with the tenant header
+func serve(s *Server, tenant, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if tenant != "" {
+		req.Header.Set(router.TenantHeader, tenant)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	s.Echo.ServeHTTP(rec, req)
+	return rec
+}
+
+func createTenant(t *testing.T, s *Server, id string) router.Tenant {
+	t.Helper()
+	tenant, err := s.tenants.CreateTenant(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tenant
+}
+
+// authenticate returns token of the api key and status of the response
+func authenticate(t *testing.T, s *Server, apiKey string) (string, int) {
+	t.Helper()
+	rec := serve(s, "", http.MethodPost, "/api/1.0/client/auth", `{"apiKey": "`+apiKey+`"}`, nil)
+	if rec.Code != http.StatusOK {
+		return "", rec.Code
+	}
+	var auth struct {
+		AuthToken string `json:"authToken"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &auth); err != nil {
+		t.Fatal(err)
+	}
+	return auth.AuthToken, rec.Code
+}
+
+func TestTenantKeyRouting(t *testing.T) {
+	s := newTestServer(t)
+	job := createTenant(t, s, "job")
+	// root keys may contain the separator of tenant keys
+	for _, key := range []string{"job_root", "other_root"} {
+		if _, err := s.Auth.AddKey(service.APIKey{Key: key, Type: service.ServerKeyType}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		apiKey string
+		want   *tenant
+	}{
+		{name: "server key of tenant", apiKey: job.ServerKey, want: s.tenants.get("job")},
+		{name: "client key of tenant", apiKey: job.ClientKey, want: s.tenants.get("job")},
+		{name: "proxy key of tenant", apiKey: job.ProxyKey, want: s.tenants.get("job")},
+		{name: "root key with tenant prefix", apiKey: "job_root"},
+		{name: "root key with other prefix", apiKey: "other_root"},
+		{name: "default key", apiKey: s.Auth.Keys()[0].Key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.tenants.forKey(tt.apiKey); got != tt.want {
+				t.Errorf("got tenant %v, want %v", got, tt.want)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/1.0/client/env/env/feature-configs", nil)
+			req.Header.Set("API-Key", tt.apiKey)
+			if got := s.tenants.match(req); got != tt.want {
+				t.Errorf("request was routed to tenant %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, status := authenticate(t, s, "job_root"); status != http.StatusOK {
+		t.Errorf("root key with tenant prefix was authenticated with status %d", status)
+	}
+	if !s.tenants.DeleteTenant("job") || s.tenants.forKey(job.ServerKey) != nil {
+		t.Error("keys of the deleted tenant are still routed to it")
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	s := newTestServer(t)
+	first := createTenant(t, s, "first")
+	second := createTenant(t, s, "second")
+
+	// flags
+	if rec := serve(s, "first", http.MethodDelete, "/admin/flags/bool-flag", "", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("flag of the first tenant was deleted with status %d", rec.Code)
+	}
+	for _, tenant := range []string{"second", ""} {
+		if rec := serve(s, tenant, http.MethodGet, "/admin/flags", "", nil); !strings.Contains(rec.Body.String(), `"bool-flag"`) {
+			t.Errorf("flag deleted in the first tenant is missing in tenant %q", tenant)
+		}
+	}
+	if rec := serve(s, "first", http.MethodGet, "/admin/flags", "", nil); strings.Contains(rec.Body.String(), `"bool-flag"`) {
+		t.Error("deleted flag is still served by the first tenant")
+	}
+
+	// faults
+	fault := `{"operation": "GetFeatureConfig", "status": 503}`
+	if rec := serve(s, "first", http.MethodPost, "/admin/faults", fault, nil); rec.Code != http.StatusCreated {
+		t.Fatalf("fault was created with status %d", rec.Code)
+	}
+	for _, tt := range []struct {
+		tenant router.Tenant
+		want   int
+	}{{first, http.StatusServiceUnavailable}, {second, http.StatusOK}} {
+		token, status := authenticate(t, s, tt.tenant.ServerKey)
+		if status != http.StatusOK {
+			t.Fatalf("key of tenant %s was authenticated with status %d", tt.tenant.ID, status)
+		}
+		rec := serve(s, "", http.MethodGet, "/api/1.0/client/env/"+tt.tenant.Environment+"/feature-configs", "",
+			map[string]string{"Authorization": "Bearer " + token})
+		if rec.Code != tt.want {
+			t.Errorf("flags of tenant %s were served with status %d, want %d", tt.tenant.ID, rec.Code, tt.want)
+		}
+	}
+	if rec := serve(s, "", http.MethodGet, "/admin/faults", "", nil); strings.Contains(rec.Body.String(), "GetFeatureConfig") {
+		t.Error("fault of the first tenant is listed by the server")
+	}
+
+	// keys
+	rec := serve(s, "second", http.MethodGet, "/admin/api-keys", "", nil)
+	for _, key := range []string{first.ServerKey, first.ClientKey, first.ProxyKey} {
+		if strings.Contains(rec.Body.String(), key) {
+			t.Errorf("key %s of the first tenant is listed by the second one", key)
+		}
+	}
+	if !strings.Contains(rec.Body.String(), second.ServerKey) {
+		t.Error("second tenant doesn't list its own key")
+	}
+	if rec := serve(s, "second", http.MethodPost, "/api/1.0/client/auth", `{"apiKey": "`+first.ServerKey+`"}`, nil); rec.Code == http.StatusOK {
+		t.Error("key of the first tenant was authenticated by the second one")
+	}
+	token, _ := authenticate(t, s, second.ServerKey)
+	rec = serve(s, "", http.MethodGet, "/api/1.0/client/env/"+first.Environment+"/feature-configs", "",
+		map[string]string{"Authorization": "Bearer " + token})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("token of the second tenant got flags of the first one with status %d", rec.Code)
+	}
+}
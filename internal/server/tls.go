@@ -0,0 +1,187 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/config"
+)
+
+// certificateLifetime is validity of generated certificates
+const certificateLifetime = 365 * 24 * time.Hour
+
+// certificateAuthority signs certificates of the server and clients
+type certificateAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// tlsConfig returns TLS configuration from options, nil is returned when
+// the server doesn't serve TLS
+func tlsConfig(options config.Config) (*tls.Config, error) {
+	if options.TLSCertFile == "" && !options.TLSSelfSigned {
+		if options.TLSClientAuth {
+			return nil, errors.New("client authentication requires --tls-cert or --tls-self-signed")
+		}
+		return nil, nil
+	}
+
+	var ca *certificateAuthority
+	var cert tls.Certificate
+	var err error
+	if options.TLSCertFile != "" {
+		cert, err = tls.LoadX509KeyPair(filepath.Clean(options.TLSCertFile), filepath.Clean(options.TLSKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("loading certificate: %w", err)
+		}
+	} else {
+		ca, err = newCertificateAuthority()
+		if err != nil {
+			return nil, err
+		}
+		if cert, err = ca.issue(options.TLSHosts); err != nil {
+			return nil, err
+		}
+		if options.TLSCAOut != "" {
+			if err := ioutil.WriteFile(options.TLSCAOut, ca.pem, 0600); err != nil {
+				return nil, fmt.Errorf("writing CA certificate: %w", err)
+			}
+		}
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if !options.TLSClientAuth {
+		return cfg, nil
+	}
+
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	cfg.ClientCAs = x509.NewCertPool()
+	switch {
+	case options.TLSClientCAFile != "":
+		bundle, err := ioutil.ReadFile(filepath.Clean(options.TLSClientCAFile))
+		if err != nil {
+			return nil, fmt.Errorf("loading client CA: %w", err)
+		}
+		if !cfg.ClientCAs.AppendCertsFromPEM(bundle) {
+			return nil, errors.New("client CA doesn't contain PEM encoded certificates")
+		}
+	case ca != nil:
+		cfg.ClientCAs.AddCert(ca.cert)
+		if options.TLSClientCertOut != "" {
+			if err := ca.writeClientCertificate(options.TLSClientCertOut); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errors.New("client authentication with --tls-cert requires --tls-client-ca")
+	}
+	return cfg, nil
+}
+
+// newCertificateAuthority returns self-signed CA with new ECDSA key
+func newCertificateAuthority() (*certificateAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating CA key: %w", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ff-mock-server CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(certificateLifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("creating CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &certificateAuthority{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, nil
+}
+
+// issue returns certificate for server hosts signed by the CA
+func (ca *certificateAuthority) issue(hosts []string) (tls.Certificate, error) {
+	certPEM, keyPEM, err := ca.issuePEM(hosts, false)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// issuePEM returns PEM encoded server or client certificate and its key
+func (ca *certificateAuthority) issuePEM(hosts []string, client bool) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating certificate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "ff-mock-server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(certificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if client {
+		template.Subject.CommonName = "ff-mock-server client"
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// writeClientCertificate writes PEM encoded client certificate followed by its key
+func (ca *certificateAuthority) writeClientCertificate(filename string) error {
+	certPEM, keyPEM, err := ca.issuePEM(nil, true)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filename, append(certPEM, keyPEM...), 0600); err != nil {
+		return fmt.Errorf("writing client certificate: %w", err)
+	}
+	return nil
+}
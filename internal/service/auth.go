@@ -4,10 +4,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
-
-	"github.com/drone/ff-mock-server/internal/config"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/clock"
 	"github.com/drone/ff-mock-server/internal/dto"
 	"github.com/golang-jwt/jwt"
 )
@@ -17,53 +20,329 @@ const (
 	ServerKeyType = "Server"
 	// ClientKeyType ...
 	ClientKeyType = "Client"
+	// ProxyKeyType is used by relay proxies
+	ProxyKeyType = "Proxy"
 )
 
 var (
-	apiKeyTypes = map[string]string{
-		internal.ServerKey: ServerKeyType,
-		internal.ClientKey: ClientKeyType,
-	}
+	// ErrTokenExpired is returned for tokens expired on demand
+	ErrTokenExpired = errors.New("token is expired")
+	// ErrTokenRejected is returned for tokens rejected on demand
+	ErrTokenRejected = errors.New("token is rejected")
+	// ErrKeyRevoked is returned for tokens issued with revoked api key
+	ErrKeyRevoked = errors.New("api key was revoked")
+	// ErrClaimsInvalid is returned when token claims can't be decoded
+	ErrClaimsInvalid = errors.New("can't decode api key type from request")
+	// ErrAPIKeyTypeInvalid is returned when resource can't be accessed with the key type
+	ErrAPIKeyTypeInvalid = errors.New("you cannot access resource with this api key type")
+	// ErrKeyExists is returned when an added api key is already accepted
+	ErrKeyExists = errors.New("api key already exists")
+	// ErrRejectCountInvalid is returned when number of rejected tokens is negative
+	ErrRejectCountInvalid = errors.New("count must not be negative")
 )
 
-// Authenticate with apiKey and return JWT signed token
-func Authenticate(apiKey string) (string, error) {
-	apiKeyType, ok := apiKeyTypes[apiKey]
-	if !ok {
+// TokenOptions controls the standard claims of issued tokens
+type TokenOptions struct {
+	// Lifetime in seconds, zero means tokens never expire
+	Lifetime int64 `json:"lifetime"`
+	// Issuer is set as iss claim
+	Issuer string `json:"issuer"`
+	// NotBefore in seconds is added to the issue time to compute nbf claim
+	NotBefore int64 `json:"notBefore"`
+}
+
+// Auth issues JWT tokens for api keys and validates them
+type Auth struct {
+	mu          sync.RWMutex
+	signer      *Signer
+	clock       *clock.Clock
+	keys        map[string]APIKey
+	options     TokenOptions
+	issued      uint64
+	expiredTill uint64
+	rejectCount int
+}
+
+// NewAuth returns new Auth accepting keys and signing tokens with signer, token
+// times are taken from clock
+func NewAuth(signer *Signer, clock *clock.Clock, options TokenOptions, keys []APIKey) *Auth {
+	a := &Auth{
+		signer:  signer,
+		clock:   clock,
+		options: options,
+		keys:    make(map[string]APIKey, len(keys)),
+	}
+	for _, key := range keys {
+		a.keys[key.Key] = key
+	}
+	return a
+}
+
+// Authenticate with server or client apiKey and return JWT signed token
+func (a *Auth) Authenticate(apiKey string) (string, error) {
+	a.mu.Lock()
+	key, ok := a.keys[apiKey]
+	if !ok || key.Type == ProxyKeyType {
+		a.mu.Unlock()
 		return "", fmt.Errorf("api key '%s' not found", apiKey)
 	}
+	return a.issue(key)
+}
 
-	clusterIdentifier := os.Getenv("CLUSTER_IDENTIFIER")
-	if len(clusterIdentifier) == 0 {
-		clusterIdentifier = internal.DefaultClusterIdentifier
+// KeyType returns type of the api key, empty type is returned when it doesn't exist
+func (a *Auth) KeyType(apiKey string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.keys[apiKey].Type
+}
+
+// Key returns the api key, false is returned when it doesn't exist
+func (a *Auth) Key(apiKey string) (APIKey, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key, ok := a.keys[apiKey]
+	return key, ok
+}
+
+// AuthenticateProxyKey with proxyKey and return JWT signed token
+func (a *Auth) AuthenticateProxyKey(proxyKey string) (string, error) {
+	a.mu.Lock()
+	key, ok := a.keys[proxyKey]
+	if !ok || key.Type != ProxyKeyType {
+		a.mu.Unlock()
+		return "", fmt.Errorf("proxy key '%s' not found", proxyKey)
+	}
+	return a.issue(key)
+}
+
+// issue signs token for the key, caller must hold the lock which is
+// released before signing
+func (a *Auth) issue(key APIKey) (string, error) {
+	a.issued++
+	seq := a.issued
+	options := a.options
+	a.mu.Unlock()
+
+	now := a.clock.Now().Unix()
+	standardClaims := jwt.StandardClaims{
+		Id:        strconv.FormatUint(seq, 10),
+		Subject:   key.Subject(),
+		IssuedAt:  now,
+		NotBefore: now + options.NotBefore,
+		Issuer:    options.Issuer,
+	}
+	if options.Lifetime > 0 {
+		standardClaims.ExpiresAt = now + options.Lifetime
 	}
-	var jwtKey = []byte(config.GetAuthSecret())
+
 	claims := &dto.JWTCustomClaims{
-		ClusterIdentifier:      clusterIdentifier,
-		Account:                "Harness account",
-		Organization:           "Harness",
-		OrganizationIdentifier: "harness",
+		ClusterIdentifier:      valueOrDefault(key.ClusterIdentifier, defaultClusterIdentifier()),
+		Account:                valueOrDefault(key.Account, "Harness account"),
+		Organization:           valueOrDefault(key.Organization, "Harness"),
+		OrganizationIdentifier: valueOrDefault(key.OrganizationIdentifier, "harness"),
 		Project:                internal.Project,
 		ProjectIdentifier:      internal.Project,
-		Environment:            internal.EnvironmentUUID,
+		Environment:            key.EnvironmentID(),
 		EnvironmentIdentifier:  internal.Environment,
-		KeyType:                apiKeyType,
-		StandardClaims:         jwt.StandardClaims{},
+		KeyType:                key.Type,
+		StandardClaims:         standardClaims,
+	}
+	return a.signer.Sign(claims)
+}
+
+// ParseToken validates signature and standard claims of the token and checks
+// if it was expired or rejected on demand or its api key was revoked
+func (a *Auth) ParseToken(auth string) (*jwt.Token, error) {
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(auth, &dto.JWTCustomClaims{}, a.signer.KeyFunc)
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Inner != nil {
+			return nil, validationErr.Inner
+		}
+		return nil, err
+	}
+	claims, ok := token.Claims.(*dto.JWTCustomClaims)
+	if !ok {
+		return nil, errors.New("can't decode token claims")
+	}
+	if err := validateClaims(claims.StandardClaims, a.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.rejectCount > 0 {
+		a.rejectCount--
+		return nil, ErrTokenRejected
+	}
+	if seq, err := strconv.ParseUint(claims.Id, 10, 64); err == nil && seq <= a.expiredTill {
+		return nil, ErrTokenExpired
+	}
+	if !a.subjectExists(claims.Subject) {
+		return nil, ErrKeyRevoked
+	}
+	return token, nil
+}
+
+// validateClaims checks exp, iat and nbf claims against now the way jwt does
+// against wall clock, so tokens expire with the clock of the server
+func validateClaims(claims jwt.StandardClaims, now time.Time) error {
+	unix := now.Unix()
+	if !claims.VerifyExpiresAt(unix, false) {
+		delta := now.Sub(time.Unix(claims.ExpiresAt, 0))
+		return fmt.Errorf("token is expired by %v", delta.Truncate(time.Second))
+	}
+	if !claims.VerifyIssuedAt(unix, false) {
+		return errors.New("token used before issued")
+	}
+	if !claims.VerifyNotBefore(unix, false) {
+		return errors.New("token is not valid yet")
+	}
+	return nil
+}
+
+// Keys returns all accepted api keys
+func (a *Auth) Keys() []APIKey {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	keys := make([]APIKey, 0, len(a.keys))
+	for _, key := range a.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Key < keys[j].Key
+	})
+	return keys
+}
+
+// AddKey starts accepting the key, random key is generated if it is empty.
+// ErrKeyExists is returned for keys which are already accepted
+func (a *Auth) AddKey(key APIKey) (APIKey, error) {
+	if key.Key == "" {
+		generated, err := NewUUID()
+		if err != nil {
+			return APIKey{}, err
+		}
+		key.Key = generated
+	}
+	if err := key.Validate(); err != nil {
+		return APIKey{}, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.keys[key.Key]; ok {
+		return APIKey{}, fmt.Errorf("%w: %s", ErrKeyExists, key.Key)
+	}
+	a.keys[key.Key] = key
+	return key, nil
+}
+
+// RevokeKey stops accepting the key, tokens issued with it are rejected
+// from the next request
+func (a *Auth) RevokeKey(apiKey string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.keys[apiKey]
+	delete(a.keys, apiKey)
+	return ok
+}
+
+// SetKeys replaces all accepted keys, tokens issued for keys which aren't among
+// them are rejected from now on
+func (a *Auth) SetKeys(keys []APIKey) error {
+	if err := ValidateAPIKeys(keys); err != nil {
+		return err
+	}
+	accepted := make(map[string]APIKey, len(keys))
+	for _, key := range keys {
+		accepted[key.Key] = key
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keys = accepted
+	return nil
+}
+
+// IssuedFor reports if token was issued for apiKey
+func IssuedFor(token *jwt.Token, apiKey string) bool {
+	claims, ok := token.Claims.(*dto.JWTCustomClaims)
+	if !ok {
+		return false
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return claims.Subject == APIKey{Key: apiKey}.Subject()
+}
+
+// subjectExists checks if the key token was issued for is still accepted,
+// caller must hold the lock
+func (a *Auth) subjectExists(subject string) bool {
+	for _, key := range a.keys {
+		if key.Subject() == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenOptions returns options used for new tokens
+func (a *Auth) TokenOptions() TokenOptions {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.options
+}
 
-	return token.SignedString(jwtKey)
+// SetTokenOptions changes options for tokens issued from now on
+func (a *Auth) SetTokenOptions(options TokenOptions) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.options = options
+}
+
+// ExpireTokens makes all tokens issued so far expired
+func (a *Auth) ExpireTokens() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expiredTill = a.issued
+}
+
+// RejectTokens rejects next count token validations, zero count clears a
+// pending rejection
+func (a *Auth) RejectTokens(count int) error {
+	if count < 0 {
+		return ErrRejectCountInvalid
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rejectCount = count
+	return nil
 }
 
 // CheckAPIKeyType ...
 func CheckAPIKeyType(expectedTokenType string, token *jwt.Token) error {
 	claims, ok := token.Claims.(*dto.JWTCustomClaims)
 	if !ok {
-		return errors.New("can't decode api key type from request")
+		return ErrClaimsInvalid
 	}
 
-	if claims.KeyType != string(expectedTokenType) {
-		return errors.New("you cannot access resource with this api key type")
+	// identity service tokens are issued for our services, not for a key type
+	if claims.KeyType != string(expectedTokenType) && claims.KeyType != IdentityServiceKeyType {
+		return ErrAPIKeyTypeInvalid
 	}
 	return nil
 }
+
+func defaultClusterIdentifier() string {
+	clusterIdentifier := os.Getenv("CLUSTER_IDENTIFIER")
+	if len(clusterIdentifier) == 0 {
+		clusterIdentifier = internal.DefaultClusterIdentifier
+	}
+	return clusterIdentifier
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/dto"
+)
+
+const testServerKey = "server-key"
+
+// newTestAuth returns Auth accepting a server key on a frozen clock
+func newTestAuth(t *testing.T, options TokenOptions) (*Auth, *clock.Clock) {
+	t.Helper()
+	clk := clock.New()
+	clk.Freeze()
+	keys := []APIKey{{Key: testServerKey, Type: ServerKeyType}}
+	return NewAuth(newTestSigner(t, "HS256", []byte("secret")), clk, options, keys), clk
+}
+
+func authenticate(t *testing.T, auth *Auth, apiKey string) string {
+	t.Helper()
+	token, err := auth.Authenticate(apiKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestTokenClaims(t *testing.T) {
+	auth, clk := newTestAuth(t, TokenOptions{Lifetime: 60, Issuer: "mock", NotBefore: 5})
+	issuedAt := clk.Now().Unix()
+	signed := authenticate(t, auth, testServerKey)
+	if _, err := auth.ParseToken(signed); err == nil {
+		t.Fatal("token was valid before nbf")
+	}
+
+	clk.Advance(5 * time.Second)
+	token, err := auth.ParseToken(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := token.Claims.(*dto.JWTCustomClaims)
+	if claims.Issuer != "mock" || claims.IssuedAt != issuedAt || claims.NotBefore != issuedAt+5 ||
+		claims.ExpiresAt != issuedAt+60 {
+		t.Errorf("got iss %q, iat %d, nbf %d and exp %d, issued at %d", claims.Issuer, claims.IssuedAt,
+			claims.NotBefore, claims.ExpiresAt, issuedAt)
+	}
+}
+
+func TestTokenValidationFollowsClock(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TokenOptions
+		advance time.Duration
+		valid   bool
+	}{
+		{name: "fresh token", options: TokenOptions{Lifetime: 60}, valid: true},
+		{name: "before expiry", options: TokenOptions{Lifetime: 60}, advance: 59 * time.Second, valid: true},
+		{name: "expired", options: TokenOptions{Lifetime: 60}, advance: 61 * time.Second},
+		{name: "never expires", advance: 24 * 365 * time.Hour, valid: true},
+		{name: "not valid yet", options: TokenOptions{NotBefore: 30}, advance: 29 * time.Second},
+		{name: "valid from nbf", options: TokenOptions{NotBefore: 30}, advance: 30 * time.Second, valid: true},
+		{name: "used before issued", advance: -time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, clk := newTestAuth(t, tt.options)
+			token := authenticate(t, auth, testServerKey)
+			clk.Advance(tt.advance)
+			if _, err := auth.ParseToken(token); (err == nil) != tt.valid {
+				t.Errorf("ParseToken() = %v, want valid %t", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestExpireTokens(t *testing.T) {
+	auth, _ := newTestAuth(t, TokenOptions{})
+	old := authenticate(t, auth, testServerKey)
+	auth.ExpireTokens()
+	if _, err := auth.ParseToken(old); err != ErrTokenExpired {
+		t.Errorf("got error %v for token issued before expiry, want %v", err, ErrTokenExpired)
+	}
+	if _, err := auth.ParseToken(authenticate(t, auth, testServerKey)); err != nil {
+		t.Errorf("token issued after expiry is invalid: %v", err)
+	}
+}
+
+func TestRejectTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		// rejected is the number of rejected validations out of 5
+		rejected int
+		wantErr  bool
+	}{
+		{name: "none", counts: nil},
+		{name: "one", counts: []int{1}, rejected: 1},
+		{name: "three", counts: []int{3}, rejected: 3},
+		{name: "replaced count", counts: []int{4, 2}, rejected: 2},
+		{name: "zero clears pending rejection", counts: []int{3, 0}},
+		{name: "negative", counts: []int{-1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, _ := newTestAuth(t, TokenOptions{})
+			token := authenticate(t, auth, testServerKey)
+			for _, count := range tt.counts {
+				if err := auth.RejectTokens(count); (err != nil) != tt.wantErr {
+					t.Fatalf("RejectTokens(%d) = %v, want error %t", count, err, tt.wantErr)
+				}
+			}
+			rejected := 0
+			for i := 0; i < 5; i++ {
+				if _, err := auth.ParseToken(token); err == ErrTokenRejected {
+					rejected++
+				} else if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if rejected != tt.rejected {
+				t.Errorf("rejected %d validations, want %d", rejected, tt.rejected)
+			}
+		})
+	}
+}
+
+func TestAddKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     APIKey
+		wantErr error
+	}{
+		{name: "client key", key: APIKey{Key: "client-key", Type: ClientKeyType}},
+		{name: "generated key", key: APIKey{Type: ProxyKeyType}},
+		{name: "existing key", key: APIKey{Key: testServerKey, Type: ClientKeyType}, wantErr: ErrKeyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, _ := newTestAuth(t, TokenOptions{})
+			key, err := auth.AddKey(tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddKey() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if got, _ := auth.Key(testServerKey); got.Type != ServerKeyType {
+					t.Errorf("existing key was overwritten with %+v", got)
+				}
+				return
+			}
+			if key.Key == "" || (tt.key.Key != "" && key.Key != tt.key.Key) {
+				t.Errorf("got key %q, want %q or generated key", key.Key, tt.key.Key)
+			}
+			if got, ok := auth.Key(key.Key); !ok || got != key {
+				t.Errorf("got key %+v, want %+v", got, key)
+			}
+			if keys := auth.Keys(); len(keys) != 2 {
+				t.Errorf("got %d keys, want 2", len(keys))
+			}
+		})
+	}
+	auth, _ := newTestAuth(t, TokenOptions{})
+	if _, err := auth.AddKey(APIKey{Key: "key", Type: "Admin"}); err == nil || errors.Is(err, ErrKeyExists) {
+		t.Errorf("AddKey() with unknown type = %v, want validation error", err)
+	}
+}
+
+func TestRevokeKey(t *testing.T) {
+	auth, _ := newTestAuth(t, TokenOptions{})
+	key, err := auth.AddKey(APIKey{Key: "client-key", Type: ClientKeyType, Environment: "env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked := authenticate(t, auth, key.Key)
+	other := authenticate(t, auth, testServerKey)
+	if _, err := auth.ParseToken(revoked); err != nil {
+		t.Fatal(err)
+	}
+
+	if !auth.RevokeKey(key.Key) {
+		t.Fatal("key wasn't revoked")
+	}
+	if auth.RevokeKey(key.Key) {
+		t.Error("key was revoked twice")
+	}
+	if _, err := auth.ParseToken(revoked); err != ErrKeyRevoked {
+		t.Errorf("got error %v for token issued before revocation, want %v", err, ErrKeyRevoked)
+	}
+	if _, err := auth.ParseToken(other); err != nil {
+		t.Errorf("token of other key is invalid: %v", err)
+	}
+	if _, err := auth.Authenticate(key.Key); err == nil {
+		t.Error("revoked key was authenticated")
+	}
+	if keys := auth.Keys(); len(keys) != 1 || keys[0].Key != testServerKey {
+		t.Errorf("got keys %+v after revocation", keys)
+	}
+}
+
+func TestAuthenticateProxyKey(t *testing.T) {
+	auth, _ := newTestAuth(t, TokenOptions{})
+	if _, err := auth.AddKey(APIKey{Key: "proxy-key", Type: ProxyKeyType}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		proxy    bool
+		key      string
+		accepted bool
+	}{
+		{name: "proxy key on proxy auth", proxy: true, key: "proxy-key", accepted: true},
+		{name: "server key on proxy auth", proxy: true, key: testServerKey},
+		{name: "proxy key on sdk auth", key: "proxy-key"},
+		{name: "server key on sdk auth", key: testServerKey, accepted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticate := auth.Authenticate
+			if tt.proxy {
+				authenticate = auth.AuthenticateProxyKey
+			}
+			signed, err := authenticate(tt.key)
+			if accepted := err == nil; accepted != tt.accepted {
+				t.Fatalf("key was accepted %t, want %t: %v", accepted, tt.accepted, err)
+			}
+			if !tt.accepted {
+				return
+			}
+			token, err := auth.ParseToken(signed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IssuedFor(token, tt.key) {
+				t.Errorf("token wasn't issued for %s", tt.key)
+			}
+		})
+	}
+}
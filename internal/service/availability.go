@@ -0,0 +1,22 @@
+package service
+
+import "sync/atomic"
+
+// Availability switches endpoints between serving requests and responding with 503
+type Availability struct {
+	unavailable uint32
+}
+
+// Available returns false when requests are rejected with 503
+func (a *Availability) Available() bool {
+	return atomic.LoadUint32(&a.unavailable) == 0
+}
+
+// SetAvailable makes endpoints serve requests again or reject them with 503
+func (a *Availability) SetAvailable(available bool) {
+	var unavailable uint32
+	if !available {
+		unavailable = 1
+	}
+	atomic.StoreUint32(&a.unavailable, unavailable)
+}
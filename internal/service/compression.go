@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"sync"
+)
+
+// Compression modes of responses
+const (
+	// CompressionNegotiate compresses responses of at least the minimum size with
+	// encoding negotiated from Accept-Encoding
+	CompressionNegotiate = "negotiate"
+	// CompressionOff never compresses responses
+	CompressionOff = "off"
+	// CompressionWrongEncoding sends gzip compressed bodies labelled with another
+	// Content-Encoding
+	CompressionWrongEncoding = "wrong-encoding"
+	// CompressionTruncated sends gzip streams cut before their end
+	CompressionTruncated = "truncated"
+	// CompressionUnrequested sends gzip compressed bodies to clients which didn't
+	// accept any encoding
+	CompressionUnrequested = "unrequested"
+)
+
+var (
+	// ErrCompressionModeInvalid is returned for unknown modes
+	ErrCompressionModeInvalid = errors.New("compression mode must be negotiate, off, wrong-encoding, truncated or unrequested")
+	// ErrCompressionMinSizeInvalid is returned for negative minimum sizes
+	ErrCompressionMinSizeInvalid = errors.New("compression min size must not be negative")
+)
+
+// CompressionOptions decide how responses are compressed, fault modes apply to
+// responses of any size
+type CompressionOptions struct {
+	Mode    string `json:"mode"`
+	MinSize int    `json:"minSize"`
+}
+
+// Compression holds compression options which can be changed at runtime
+type Compression struct {
+	mu      sync.Mutex
+	options CompressionOptions
+}
+
+// NewCompression returns new Compression with the options
+func NewCompression(options CompressionOptions) (*Compression, error) {
+	c := &Compression{}
+	if err := c.SetOptions(options); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Options returns current options
+func (c *Compression) Options() CompressionOptions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.options
+}
+
+// Validate checks mode and minimum size of the options
+func (o CompressionOptions) Validate() error {
+	switch o.Mode {
+	case CompressionNegotiate, CompressionOff, CompressionWrongEncoding, CompressionTruncated, CompressionUnrequested:
+	default:
+		return ErrCompressionModeInvalid
+	}
+	if o.MinSize < 0 {
+		return ErrCompressionMinSizeInvalid
+	}
+	return nil
+}
+
+// SetOptions validates and changes the options
+func (c *Compression) SetOptions(options CompressionOptions) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = options
+	return nil
+}
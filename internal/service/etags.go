@@ -0,0 +1,121 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Modes of entity tags sent with configuration responses
+const (
+	// ETagModeStrong sends strong entity tags, requests with a matching If-None-Match get 304
+	ETagModeStrong = "strong"
+	// ETagModeMissing sends no entity tags so responses are always complete
+	ETagModeMissing = "missing"
+	// ETagModeStale keeps sending the entity tag a resource had when it was first
+	// requested in this mode, requests with it get 304 even after the resource changed
+	ETagModeStale = "stale"
+)
+
+// ErrETagModeInvalid is returned for unknown modes
+var ErrETagModeInvalid = errors.New("etag mode must be strong, missing or stale")
+
+// ETags decides entity tags of configuration responses, the mode can be changed
+// at runtime to check cache validation of SDKs
+type ETags struct {
+	mu    sync.Mutex
+	mode  string
+	stale map[string]string
+}
+
+// NewETags returns new ETags in the mode
+func NewETags(mode string) (*ETags, error) {
+	e := &ETags{}
+	if err := e.SetMode(mode); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Mode returns current mode
+func (e *ETags) Mode() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.mode
+}
+
+// ValidateETagMode checks the mode is known
+func ValidateETagMode(mode string) error {
+	if mode != ETagModeStrong && mode != ETagModeMissing && mode != ETagModeStale {
+		return ErrETagModeInvalid
+	}
+	return nil
+}
+
+// SetMode changes the mode, tags remembered in stale mode are forgotten
+func (e *ETags) SetMode(mode string) error {
+	if err := ValidateETagMode(mode); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.mode = mode
+	e.stale = make(map[string]string)
+	return nil
+}
+
+// Tag returns entity tag sent for the resource which currently has tag current,
+// empty tag means no tag is sent
+func (e *ETags) Tag(resource, current string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch e.mode {
+	case ETagModeMissing:
+		return ""
+	case ETagModeStale:
+		if tag, ok := e.stale[resource]; ok {
+			return tag
+		}
+		e.stale[resource] = current
+	}
+	return current
+}
+
+// ETag returns strong entity tag of the parts, e.g. JSON of items in the response
+func ETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// EncodedETag returns the tag of the representation compressed with the encoding,
+// e.g. "<hash>-gzip"
+func EncodedETag(tag, encoding string) string {
+	if !strings.HasSuffix(tag, `"`) {
+		return tag
+	}
+	return strings.TrimSuffix(tag, `"`) + "-" + encoding + `"`
+}
+
+// MatchETag reports whether If-None-Match header value matches the tag or the tag
+// of any compressed representation, tags are compared weakly as RFC 7232 requires
+// for If-None-Match
+func MatchETag(ifNoneMatch, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	encodedPrefix := strings.TrimSuffix(tag, `"`) + "-"
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+		if strings.HasPrefix(candidate, encodedPrefix) && strings.HasSuffix(candidate, `"`) &&
+			!strings.ContainsAny(candidate[len(encodedPrefix):len(candidate)-1], `"-`) {
+			return true
+		}
+	}
+	return false
+}
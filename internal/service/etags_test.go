@@ -0,0 +1,76 @@
+package service
+
+import "testing"
+
+func TestMatchETag(t *testing.T) {
+	tag := ETag("flag")
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		tag         string
+		want        bool
+	}{
+		{"same tag", tag, tag, true},
+		{"other tag", ETag("segment"), tag, false},
+		{"weak tag", "W/" + tag, tag, true},
+		{"list", ETag("segment") + ", " + tag, tag, true},
+		{"wildcard", "*", tag, true},
+		{"gzip tag", EncodedETag(tag, "gzip"), tag, true},
+		{"brotli tag in list", `"other", ` + EncodedETag(tag, "br"), tag, true},
+		{"weak deflate tag", "W/" + EncodedETag(tag, "deflate"), tag, true},
+		{"encoded other tag", EncodedETag(ETag("segment"), "gzip"), tag, false},
+		{"tag with longer suffix", EncodedETag(EncodedETag(tag, "gzip"), "br"), tag, false},
+		{"no tag is sent", tag, "", false},
+		{"empty header", "", tag, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchETag(tt.ifNoneMatch, tt.tag); got != tt.want {
+				t.Errorf("MatchETag(%s, %s) = %t, want %t", tt.ifNoneMatch, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodedETag(t *testing.T) {
+	tests := []struct {
+		tag, encoding, want string
+	}{
+		{`"abc"`, "gzip", `"abc-gzip"`},
+		{`W/"abc"`, "br", `W/"abc-br"`},
+		{`abc`, "gzip", `abc`},
+	}
+	for _, tt := range tests {
+		if got := EncodedETag(tt.tag, tt.encoding); got != tt.want {
+			t.Errorf("EncodedETag(%s, %s) = %s, want %s", tt.tag, tt.encoding, got, tt.want)
+		}
+	}
+}
+
+func TestETagModes(t *testing.T) {
+	tests := []struct {
+		mode        string
+		first, then string
+	}{
+		{ETagModeStrong, `"a"`, `"b"`},
+		{ETagModeMissing, "", ""},
+		{ETagModeStale, `"a"`, `"a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			etags, err := NewETags(tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := etags.Tag("/flags", `"a"`); got != tt.first {
+				t.Errorf("got tag %s, want %s", got, tt.first)
+			}
+			if got := etags.Tag("/flags", `"b"`); got != tt.then {
+				t.Errorf("got tag %s after change, want %s", got, tt.then)
+			}
+		})
+	}
+	if _, err := NewETags("weak"); err != ErrETagModeInvalid {
+		t.Errorf("got error %v, want %v", err, ErrETagModeInvalid)
+	}
+}
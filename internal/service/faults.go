@@ -0,0 +1,120 @@
+package service
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FaultRule injects an error status and/or a delay into requests of an operation
+type FaultRule struct {
+	ID string `json:"id"`
+	// Operation is operationId of the faulted requests, empty matches all operations
+	Operation string `json:"operation,omitempty"`
+	// Status is returned instead of the response, zero only delays the request
+	Status  int           `json:"status,omitempty"`
+	Message string        `json:"message,omitempty"`
+	Delay   time.Duration `json:"delay,omitempty"`
+	// Times limits number of faulted requests, zero means the rule never expires
+	Times int `json:"times,omitempty"`
+}
+
+// Faults holds fault rules which can be changed at runtime
+type Faults struct {
+	mu    sync.Mutex
+	rules []FaultRule
+	seq   uint64
+}
+
+// NewFaults returns new Faults with initial rules
+func NewFaults(rules ...FaultRule) *Faults {
+	f := &Faults{}
+	for _, rule := range rules {
+		f.Add(rule)
+	}
+	return f
+}
+
+// Add appends the rule and returns it with assigned id
+func (f *Faults) Add(rule FaultRule) FaultRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	rule.ID = strconv.FormatUint(f.seq, 10)
+	f.rules = append(f.rules, rule)
+	return rule
+}
+
+// Remove deletes the rule, false is returned when it doesn't exist
+func (f *Faults) Remove(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, rule := range f.rules {
+		if rule.ID == id {
+			f.rules = append(f.rules[:i], f.rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear deletes all rules
+func (f *Faults) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = nil
+}
+
+// Replace deletes all rules and appends rules with assigned ids
+func (f *Faults) Replace(rules []FaultRule) []FaultRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = make([]FaultRule, 0, len(rules))
+	for _, rule := range rules {
+		f.seq++
+		rule.ID = strconv.FormatUint(f.seq, 10)
+		f.rules = append(f.rules, rule)
+	}
+	result := make([]FaultRule, len(f.rules))
+	copy(result, f.rules)
+	return result
+}
+
+// Rules returns active rules in the order they are matched
+func (f *Faults) Rules() []FaultRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rules := make([]FaultRule, len(f.rules))
+	copy(rules, f.rules)
+	return rules
+}
+
+// Match returns the first rule with status for the operation, delays of rules
+// without status matched before it are added up. Rules limited by Times are
+// removed once they were matched that many times
+func (f *Faults) Match(operation string) (FaultRule, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result FaultRule
+	matched := false
+	rules := f.rules[:0]
+	for i, rule := range f.rules {
+		if result.Status != 0 || (rule.Operation != "" && rule.Operation != operation) {
+			rules = append(rules, rule)
+			continue
+		}
+		if matched {
+			rule.Delay += result.Delay
+		}
+		result, matched = rule, true
+		if rule.Times > 0 {
+			f.rules[i].Times--
+			if f.rules[i].Times == 0 {
+				continue
+			}
+		}
+		rules = append(rules, f.rules[i])
+	}
+	f.rules = rules
+	return result, matched
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/golang-jwt/jwt"
+)
+
+// IdentityServiceKeyType is set as key type of claims mapped from IdentityService
+// tokens, they can access both server and client resources
+const IdentityServiceKeyType = "IdentityService"
+
+// IdentityService validates tokens issued by the identity service, they are
+// signed with HS256 and a secret separate from api key tokens
+type IdentityService struct {
+	secret []byte
+	clock  *clock.Clock
+}
+
+// NewIdentityService returns new IdentityService validating tokens with secret
+// against time of clock
+func NewIdentityService(secret string, clock *clock.Clock) *IdentityService {
+	return &IdentityService{
+		secret: []byte(secret),
+		clock:  clock,
+	}
+}
+
+// ParseToken validates IdentityService token and returns token with claims
+// mapped onto dto.JWTCustomClaims so handlers can treat it as any other token
+func (s *IdentityService) ParseToken(auth string) (*jwt.Token, error) {
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.ParseWithClaims(auth, &dto.IdentityServiceClaims{}, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+		}
+		return s.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*dto.IdentityServiceClaims)
+	if !ok {
+		return nil, fmt.Errorf("can't decode identity service claims")
+	}
+	if err := validateClaims(claims.StandardClaims, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	token.Claims = &dto.JWTCustomClaims{
+		Environment:            claims.Environment,
+		EnvironmentIdentifier:  claims.EnvironmentIdentifier,
+		Project:                internal.Project,
+		ProjectIdentifier:      internal.Project,
+		Account:                claims.AccountID,
+		Organization:           "Harness",
+		OrganizationIdentifier: "harness",
+		ClusterIdentifier:      defaultClusterIdentifier(),
+		KeyType:                IdentityServiceKeyType,
+		StandardClaims:         claims.StandardClaims,
+	}
+	return token, nil
+}
+
+// Sign returns IdentityService token with claims, used for minting tokens in tests
+func (s *IdentityService) Sign(claims dto.IdentityServiceClaims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
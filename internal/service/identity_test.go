@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+	"github.com/drone/ff-mock-server/internal/dto"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestIdentityServiceParseToken(t *testing.T) {
+	clk := clock.New()
+	clk.Freeze()
+	identity := NewIdentityService("identity-secret", clk)
+	now := clk.Now().Unix()
+	claims := dto.IdentityServiceClaims{Type: "USER", AccountID: "account", Environment: "env",
+		StandardClaims: jwt.StandardClaims{IssuedAt: now, ExpiresAt: now + 60}}
+
+	signed := func(t *testing.T, method jwt.SigningMethod, secret string, claims dto.IdentityServiceClaims) string {
+		t.Helper()
+		token, err := jwt.NewWithClaims(method, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	apiKeyToken := sign(t, newTestSigner(t, "HS256", []byte("api-key-secret")))
+	expired := claims
+	expired.ExpiresAt = now - 1
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "identity secret", token: signed(t, jwt.SigningMethodHS256, "identity-secret", claims), valid: true},
+		{name: "wrong secret", token: signed(t, jwt.SigningMethodHS256, "other-secret", claims)},
+		{name: "api key token", token: apiKeyToken},
+		{name: "other algorithm", token: signed(t, jwt.SigningMethodHS512, "identity-secret", claims)},
+		{name: "expired on the clock", token: signed(t, jwt.SigningMethodHS256, "identity-secret", expired)},
+		{name: "malformed", token: "identity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := identity.ParseToken(tt.token)
+			if (err == nil) != tt.valid {
+				t.Fatalf("ParseToken() = %v, want valid %t", err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+			mapped, ok := token.Claims.(*dto.JWTCustomClaims)
+			if !ok || mapped.KeyType != IdentityServiceKeyType || mapped.Environment != "env" || mapped.Account != "account" {
+				t.Errorf("got claims %+v, want identity service claims of env and account", token.Claims)
+			}
+		})
+	}
+
+	clk.Advance(61 * time.Second)
+	if _, err := identity.ParseToken(signed(t, jwt.SigningMethodHS256, "identity-secret", claims)); err == nil {
+		t.Error("token is valid after the clock passed its expiry")
+	}
+}
+
+func TestIdentityServiceSign(t *testing.T) {
+	identity := NewIdentityService("identity-secret", clock.New())
+	token, err := identity.Sign(dto.IdentityServiceClaims{Type: "SERVICE", AccountID: "account"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := identity.ParseToken(token); err != nil {
+		t.Errorf("signed token is invalid: %v", err)
+	}
+	if _, err := NewIdentityService("other-secret", clock.New()).ParseToken(token); err == nil {
+		t.Error("token is valid with other secret")
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// JournalEntry is a request received by the server
+type JournalEntry struct {
+	Time      time.Time     `json:"time"`
+	Operation string        `json:"operation"`
+	Method    string        `json:"method"`
+	Path      string        `json:"path"`
+	Query     string        `json:"query,omitempty"`
+	Body      string        `json:"body,omitempty"`
+	Status    int           `json:"status"`
+	Duration  time.Duration `json:"duration"`
+	// Fault is set when the response was injected by a fault rule
+	Fault bool `json:"fault,omitempty"`
+}
+
+// Journal keeps the most recent requests so tests can assert what SDKs sent
+type Journal struct {
+	mu       sync.Mutex
+	entries  []JournalEntry
+	capacity int
+}
+
+// NewJournal returns new Journal keeping up to capacity entries
+func NewJournal(capacity int) *Journal {
+	return &Journal{
+		capacity: capacity,
+	}
+}
+
+// Record appends the entry, the oldest entry is dropped when the journal is full
+func (j *Journal) Record(entry JournalEntry) {
+	if j.capacity <= 0 {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.entries) == j.capacity {
+		copy(j.entries, j.entries[1:])
+		j.entries = j.entries[:len(j.entries)-1]
+	}
+	j.entries = append(j.entries, entry)
+}
+
+// Entries returns recorded entries of the operation in order they were received,
+// empty operation returns entries of all operations
+func (j *Journal) Entries(operation string) []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := make([]JournalEntry, 0, len(j.entries))
+	for _, entry := range j.entries {
+		if operation == "" || entry.Operation == operation {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Clear deletes all entries
+func (j *Journal) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = nil
+}
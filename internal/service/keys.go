@@ -0,0 +1,120 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/drone/ff-mock-server/internal"
+)
+
+// APIKey is a server, client or proxy key accepted by Authenticate, optional fields
+// override claims of tokens issued for the key
+type APIKey struct {
+	Key                    string `json:"key"`
+	Type                   string `json:"type"`
+	Account                string `json:"account,omitempty"`
+	Organization           string `json:"organization,omitempty"`
+	OrganizationIdentifier string `json:"organizationIdentifier,omitempty"`
+	ClusterIdentifier      string `json:"clusterIdentifier,omitempty"`
+	Environment            string `json:"environment,omitempty"`
+}
+
+// DefaultAPIKeys returns mocked server, client and proxy key
+func DefaultAPIKeys() []APIKey {
+	return []APIKey{
+		{Key: internal.ServerKey, Type: ServerKeyType},
+		{Key: internal.ClientKey, Type: ClientKeyType},
+		{Key: internal.ProxyKey, Type: ProxyKeyType},
+	}
+}
+
+// GenerateAPIKeys returns random server, client and proxy key starting with prefix,
+// tokens issued for them have environment claim
+func GenerateAPIKeys(prefix, environment string) ([]APIKey, error) {
+	keys := []APIKey{{Type: ServerKeyType}, {Type: ClientKeyType}, {Type: ProxyKeyType}}
+	for i := range keys {
+		key, err := NewUUID()
+		if err != nil {
+			return nil, err
+		}
+		keys[i].Key = prefix + key
+		keys[i].Environment = environment
+	}
+	return keys, nil
+}
+
+// LoadAPIKeys reads api keys from the json file
+func LoadAPIKeys(filename string) ([]APIKey, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, err
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, fmt.Errorf("api keys file %s: %w", filename, err)
+	}
+	if err := ValidateAPIKeys(keys); err != nil {
+		return nil, fmt.Errorf("api keys file %s: %w", filename, err)
+	}
+	return keys, nil
+}
+
+// ValidateAPIKeys validates every key and checks no key is listed twice
+func ValidateAPIKeys(keys []APIKey) error {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if err := key.Validate(); err != nil {
+			return err
+		}
+		if seen[key.Key] {
+			return fmt.Errorf("api key '%s' is listed twice", key.Key)
+		}
+		seen[key.Key] = true
+	}
+	return nil
+}
+
+// Validate checks the key is set and its type
+func (k APIKey) Validate() error {
+	if k.Key == "" {
+		return errors.New("api key is required")
+	}
+	if k.Type != ServerKeyType && k.Type != ClientKeyType && k.Type != ProxyKeyType {
+		return fmt.Errorf("api key type '%s' must be %s, %s or %s", k.Type, ServerKeyType, ClientKeyType, ProxyKeyType)
+	}
+	return nil
+}
+
+// Subject identifies key in issued tokens without exposing it
+func (k APIKey) Subject() string {
+	sum := sha256.Sum256([]byte(k.Key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// EnvironmentID returns environment of tokens issued for the key
+func (k APIKey) EnvironmentID() string {
+	return valueOrDefault(k.Environment, internal.EnvironmentUUID)
+}
+
+// NewUUID returns random UUID v4 used for minted keys and environments
+func NewUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// Hash returns sha256 of the key, relay proxies receive hashed keys
+func (k APIKey) Hash() string {
+	sum := sha256.Sum256([]byte(k.Key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+// Attributes of metrics data identifying the evaluated flag and variation
+const (
+	featureIdentifierAttribute   = "featureIdentifier"
+	variationIdentifierAttribute = "variationIdentifier"
+)
+
+// EvaluationCount is number of evaluations of a flag variation reported by SDKs
+type EvaluationCount struct {
+	Flag      string `json:"flag"`
+	Variation string `json:"variation"`
+	Count     int    `json:"count"`
+}
+
+// ReceivedMetrics keeps metrics posted by SDKs so tests can assert what was reported
+type ReceivedMetrics struct {
+	mu       sync.Mutex
+	payloads []api.Metrics
+}
+
+// NewReceivedMetrics returns new empty ReceivedMetrics
+func NewReceivedMetrics() *ReceivedMetrics {
+	return &ReceivedMetrics{}
+}
+
+// Record appends metrics payload
+func (m *ReceivedMetrics) Record(metrics api.Metrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloads = append(m.payloads, metrics)
+}
+
+// Payloads returns all payloads in order they were received
+func (m *ReceivedMetrics) Payloads() []api.Metrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	payloads := make([]api.Metrics, len(m.payloads))
+	copy(payloads, m.payloads)
+	return payloads
+}
+
+// Evaluations returns evaluation counts summed by flag and variation
+func (m *ReceivedMetrics) Evaluations() []EvaluationCount {
+	type key struct{ flag, variation string }
+	counts := make(map[key]int)
+	for _, payload := range m.Payloads() {
+		if payload.MetricsData == nil {
+			continue
+		}
+		for _, data := range *payload.MetricsData {
+			k := key{}
+			for _, attribute := range data.Attributes {
+				switch attribute.Key {
+				case featureIdentifierAttribute:
+					k.flag = attribute.Value
+				case variationIdentifierAttribute:
+					k.variation = attribute.Value
+				}
+			}
+			counts[k] += data.Count
+		}
+	}
+
+	evaluations := make([]EvaluationCount, 0, len(counts))
+	for k, count := range counts {
+		evaluations = append(evaluations, EvaluationCount{Flag: k.flag, Variation: k.variation, Count: count})
+	}
+	sort.Slice(evaluations, func(i, j int) bool {
+		if evaluations[i].Flag != evaluations[j].Flag {
+			return evaluations[i].Flag < evaluations[j].Flag
+		}
+		return evaluations[i].Variation < evaluations[j].Variation
+	})
+	return evaluations
+}
+
+// Targets returns targets reported by SDKs, a target reported multiple times is
+// returned once with the latest data
+func (m *ReceivedMetrics) Targets() []api.TargetData {
+	index := make(map[string]int)
+	targets := make([]api.TargetData, 0)
+	for _, payload := range m.Payloads() {
+		if payload.TargetData == nil {
+			continue
+		}
+		for _, target := range *payload.TargetData {
+			if i, ok := index[target.Identifier]; ok {
+				targets[i] = target
+				continue
+			}
+			index[target.Identifier] = len(targets)
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// Clear removes all payloads
+func (m *ReceivedMetrics) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.payloads = nil
+}
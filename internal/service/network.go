@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Ways network faults drop the connection
+const (
+	// DisconnectReset resets the connection so clients get connection reset by peer
+	DisconnectReset = "reset"
+	// DisconnectClose closes the connection so clients get unexpected EOF
+	DisconnectClose = "close"
+)
+
+// Content-Length headers of network faults
+const (
+	// ContentLengthGreater declares more bytes than the body has
+	ContentLengthGreater = "greater"
+	// ContentLengthSmaller declares less bytes than the body has
+	ContentLengthSmaller = "smaller"
+	// ContentLengthMalformed sends Content-Length which is not a number
+	ContentLengthMalformed = "malformed"
+)
+
+// NetworkFault breaks responses of an operation at the connection level, the
+// response is written by the handler first and then sent as the fault says
+type NetworkFault struct {
+	ID string `json:"id"`
+	// Operation is operationId of the faulted requests, empty matches all operations
+	Operation string `json:"operation,omitempty"`
+	// HeaderDelay is the delay before headers are sent
+	HeaderDelay time.Duration `json:"headerDelay,omitempty"`
+	// BodyDelay is the delay between headers and body
+	BodyDelay time.Duration `json:"bodyDelay,omitempty"`
+	// BytesPerSecond trickles the body at the rate, zero sends it at once
+	BytesPerSecond int `json:"bytesPerSecond,omitempty"`
+	// Disconnect drops the connection after After bytes of the body were sent
+	Disconnect string `json:"disconnect,omitempty"`
+	After      int    `json:"after,omitempty"`
+	// ContentLength replaces Content-Length of the response
+	ContentLength string `json:"contentLength,omitempty"`
+	// Times limits number of faulted requests, zero means the fault never expires
+	Times int `json:"times,omitempty"`
+}
+
+// Validate checks the fault changes the response
+func (f NetworkFault) Validate() error {
+	if f.HeaderDelay < 0 || f.BodyDelay < 0 || f.BytesPerSecond < 0 || f.After < 0 || f.Times < 0 {
+		return errors.New("delays, bytes per second, after and times must not be negative")
+	}
+	if f.Disconnect != "" && f.Disconnect != DisconnectReset && f.Disconnect != DisconnectClose {
+		return errors.New("disconnect must be reset or close")
+	}
+	if f.ContentLength != "" && f.ContentLength != ContentLengthGreater &&
+		f.ContentLength != ContentLengthSmaller && f.ContentLength != ContentLengthMalformed {
+		return errors.New("content length must be greater, smaller or malformed")
+	}
+	if f.HeaderDelay == 0 && f.BodyDelay == 0 && f.BytesPerSecond == 0 && f.Disconnect == "" && f.ContentLength == "" {
+		return errors.New("delay, bytes per second, disconnect or content length must be set")
+	}
+	return nil
+}
+
+// NetworkFaults holds network faults which can be changed at runtime
+type NetworkFaults struct {
+	mu     sync.Mutex
+	faults []NetworkFault
+	seq    uint64
+}
+
+// NewNetworkFaults returns new NetworkFaults with initial faults
+func NewNetworkFaults(faults ...NetworkFault) *NetworkFaults {
+	n := &NetworkFaults{}
+	n.Replace(faults)
+	return n
+}
+
+// Add appends the fault and returns it with assigned id
+func (n *NetworkFaults) Add(fault NetworkFault) NetworkFault {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.add(fault)
+}
+
+// add assigns id to the fault and appends it, caller must hold the lock
+func (n *NetworkFaults) add(fault NetworkFault) NetworkFault {
+	n.seq++
+	fault.ID = strconv.FormatUint(n.seq, 10)
+	n.faults = append(n.faults, fault)
+	return fault
+}
+
+// Remove deletes the fault, false is returned when it doesn't exist
+func (n *NetworkFaults) Remove(id string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i, fault := range n.faults {
+		if fault.ID == id {
+			n.faults = append(n.faults[:i], n.faults[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear deletes all faults
+func (n *NetworkFaults) Clear() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.faults = nil
+}
+
+// Replace deletes all faults and appends faults with assigned ids
+func (n *NetworkFaults) Replace(faults []NetworkFault) []NetworkFault {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.faults = nil
+	result := make([]NetworkFault, 0, len(faults))
+	for _, fault := range faults {
+		result = append(result, n.add(fault))
+	}
+	return result
+}
+
+// Faults returns active faults in the order they are matched
+func (n *NetworkFaults) Faults() []NetworkFault {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	faults := make([]NetworkFault, len(n.faults))
+	copy(faults, n.faults)
+	return faults
+}
+
+// Match returns the first fault of the operation, faults limited by Times are
+// removed once they were matched that many times
+func (n *NetworkFaults) Match(operation string) (NetworkFault, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i, fault := range n.faults {
+		if fault.Operation != "" && fault.Operation != operation {
+			continue
+		}
+		if fault.Times > 0 {
+			n.faults[i].Times--
+			if n.faults[i].Times == 0 {
+				n.faults = append(n.faults[:i], n.faults[i+1:]...)
+			}
+		}
+		return fault, true
+	}
+	return NetworkFault{}, false
+}
@@ -0,0 +1,34 @@
+package service
+
+import "testing"
+
+func TestNetworkFaultsMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		faults    []NetworkFault
+		operation string
+		// matched is the number of matched requests out of 3
+		matched int
+	}{
+		{name: "no faults", operation: "GetFeatureConfig"},
+		{name: "all operations", faults: []NetworkFault{{HeaderDelay: 1}}, operation: "GetFeatureConfig", matched: 3},
+		{name: "operation", faults: []NetworkFault{{Operation: "GetFeatureConfig", HeaderDelay: 1}}, operation: "GetFeatureConfig", matched: 3},
+		{name: "other operation", faults: []NetworkFault{{Operation: "GetAllSegments", HeaderDelay: 1}}, operation: "GetFeatureConfig"},
+		{name: "operation is matched exactly", faults: []NetworkFault{{Operation: "getfeatureconfig", HeaderDelay: 1}}, operation: "GetFeatureConfig"},
+		{name: "times", faults: []NetworkFault{{HeaderDelay: 1, Times: 2}}, operation: "GetFeatureConfig", matched: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faults := NewNetworkFaults(tt.faults...)
+			matched := 0
+			for i := 0; i < 3; i++ {
+				if _, ok := faults.Match(tt.operation); ok {
+					matched++
+				}
+			}
+			if matched != tt.matched {
+				t.Errorf("matched %d requests, want %d", matched, tt.matched)
+			}
+		})
+	}
+}
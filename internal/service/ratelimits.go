@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+)
+
+// Request attributes rate limits are counted by
+const (
+	RateLimitByAPIKey      = "apiKey"
+	RateLimitByEnvironment = "environment"
+	RateLimitByOperation   = "operation"
+	RateLimitByIP          = "ip"
+)
+
+// Formats of Retry-After header
+const (
+	RetryAfterSeconds = "seconds"
+	RetryAfterDate    = "date"
+)
+
+// RateLimit is a token bucket per distinct value of the request attribute, requests
+// are rejected with 429 while the bucket is empty
+type RateLimit struct {
+	ID string `json:"id"`
+	// By is the request attribute buckets are kept for
+	By string `json:"by"`
+	// Operation is operationId of the limited requests, empty matches all operations
+	Operation string `json:"operation,omitempty"`
+	// Rate is the number of requests per second refilled to the bucket
+	Rate float64 `json:"rate"`
+	// Burst is the size of the bucket, rate rounded up is used when it is zero
+	Burst int `json:"burst,omitempty"`
+	// RetryAfter is format of Retry-After header, seconds are sent when it is empty
+	RetryAfter string `json:"retryAfter,omitempty"`
+}
+
+// Validate checks attribute, rate, burst and Retry-After format of the limit
+func (l RateLimit) Validate() error {
+	switch l.By {
+	case RateLimitByAPIKey, RateLimitByEnvironment, RateLimitByOperation, RateLimitByIP:
+	default:
+		return errors.New("rate limit must be by apiKey, environment, operation or ip")
+	}
+	if l.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+	if l.Burst < 0 {
+		return errors.New("burst must not be negative")
+	}
+	if l.RetryAfter != "" && l.RetryAfter != RetryAfterSeconds && l.RetryAfter != RetryAfterDate {
+		return errors.New("retry after must be seconds or date")
+	}
+	return nil
+}
+
+// size returns capacity of the bucket
+func (l RateLimit) size() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Ceil(l.Rate)
+}
+
+// RateLimitRequest has attributes of a request rate limits are counted by, limits
+// by an empty attribute don't apply
+type RateLimitRequest struct {
+	// APIKey is subject of tokens issued for the key
+	APIKey      string
+	Environment string
+	Operation   string
+	IP          string
+}
+
+func (r RateLimitRequest) attribute(by string) string {
+	switch by {
+	case RateLimitByAPIKey:
+		return r.APIKey
+	case RateLimitByEnvironment:
+		return r.Environment
+	case RateLimitByOperation:
+		return r.Operation
+	}
+	return r.IP
+}
+
+// bucket holds tokens left at the time it was updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// limiter keeps buckets of the limit by attribute value
+type limiter struct {
+	limit   RateLimit
+	buckets map[string]*bucket
+}
+
+// RateLimits holds rate limits which can be changed at runtime
+type RateLimits struct {
+	mu       sync.Mutex
+	clock    *clock.Clock
+	limiters []*limiter
+	seq      uint64
+}
+
+// NewRateLimits returns new RateLimits with initial limits, buckets are refilled
+// as the clock moves
+func NewRateLimits(clock *clock.Clock, limits ...RateLimit) *RateLimits {
+	r := &RateLimits{clock: clock}
+	r.Replace(limits)
+	return r
+}
+
+// Add appends the limit and returns it with assigned id
+func (r *RateLimits) Add(limit RateLimit) RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.add(limit)
+}
+
+// add assigns id to the limit and appends it with empty buckets, caller must
+// hold the lock
+func (r *RateLimits) add(limit RateLimit) RateLimit {
+	r.seq++
+	limit.ID = strconv.FormatUint(r.seq, 10)
+	r.limiters = append(r.limiters, &limiter{limit: limit, buckets: make(map[string]*bucket)})
+	return limit
+}
+
+// Remove deletes the limit, false is returned when it doesn't exist
+func (r *RateLimits) Remove(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, l := range r.limiters {
+		if l.limit.ID == id {
+			r.limiters = append(r.limiters[:i], r.limiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear deletes all limits
+func (r *RateLimits) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limiters = nil
+}
+
+// Replace deletes all limits and appends limits with assigned ids and full buckets
+func (r *RateLimits) Replace(limits []RateLimit) []RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limiters = nil
+	result := make([]RateLimit, 0, len(limits))
+	for _, limit := range limits {
+		result = append(result, r.add(limit))
+	}
+	return result
+}
+
+// Limits returns active limits
+func (r *RateLimits) Limits() []RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	limits := make([]RateLimit, 0, len(r.limiters))
+	for _, l := range r.limiters {
+		limits = append(limits, l.limit)
+	}
+	return limits
+}
+
+// Empty reports whether there are no limits, requests don't need to be inspected then
+func (r *RateLimits) Empty() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.limiters) == 0
+}
+
+// Take takes a token from buckets of all limits matching the request. When a bucket
+// is empty no token is taken and the exceeded limit with the longest wait until
+// the next token is returned
+func (r *RateLimits) Take(request RateLimitRequest) (RateLimit, time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.clock.Now()
+	var matched []*bucket
+	var exceeded RateLimit
+	var wait time.Duration
+	for _, l := range r.limiters {
+		if l.limit.Operation != "" && l.limit.Operation != request.Operation {
+			continue
+		}
+		value := request.attribute(l.limit.By)
+		if value == "" {
+			continue
+		}
+		b, ok := l.buckets[value]
+		if !ok {
+			b = &bucket{tokens: l.limit.size(), updated: now}
+			l.buckets[value] = b
+		}
+		b.tokens = math.Min(l.limit.size(), b.tokens+now.Sub(b.updated).Seconds()*l.limit.Rate)
+		b.updated = now
+		if b.tokens < 1 {
+			if d := time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second)); d > wait {
+				exceeded, wait = l.limit, d
+			}
+			continue
+		}
+		matched = append(matched, b)
+	}
+	if wait > 0 {
+		return exceeded, wait, false
+	}
+	for _, b := range matched {
+		b.tokens--
+	}
+	return RateLimit{}, 0, true
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal/clock"
+)
+
+func TestRateLimitValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   RateLimit
+		wantErr bool
+	}{
+		{name: "by api key", limit: RateLimit{By: RateLimitByAPIKey, Rate: 1}},
+		{name: "with burst and date", limit: RateLimit{By: RateLimitByIP, Rate: 0.5, Burst: 3, RetryAfter: RetryAfterDate}},
+		{name: "unknown attribute", limit: RateLimit{By: "target", Rate: 1}, wantErr: true},
+		{name: "zero rate", limit: RateLimit{By: RateLimitByAPIKey}, wantErr: true},
+		{name: "negative burst", limit: RateLimit{By: RateLimitByAPIKey, Rate: 1, Burst: -1}, wantErr: true},
+		{name: "unknown retry after", limit: RateLimit{By: RateLimitByAPIKey, Rate: 1, RetryAfter: "minutes"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limit.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// take takes n tokens and returns how many requests were allowed
+func take(limits *RateLimits, request RateLimitRequest, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		if _, _, ok := limits.Take(request); ok {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestRateLimitsTake(t *testing.T) {
+	alice := RateLimitRequest{APIKey: "alice", Environment: "env", Operation: "GetFeatureConfig", IP: "10.0.0.1"}
+	bob := RateLimitRequest{APIKey: "bob", Environment: "env", Operation: "Stream", IP: "10.0.0.2"}
+
+	tests := []struct {
+		name    string
+		limits  []RateLimit
+		request RateLimitRequest
+		// allowed is the number of allowed requests out of 10 sent at once
+		allowed int
+		// other is the number of allowed requests of bob sent afterwards
+		other int
+	}{
+		{name: "no limits", request: alice, allowed: 10, other: 10},
+		{name: "burst is rate rounded up", limits: []RateLimit{{By: RateLimitByAPIKey, Rate: 2.5}}, request: alice, allowed: 3, other: 3},
+		{name: "burst", limits: []RateLimit{{By: RateLimitByAPIKey, Rate: 1, Burst: 5}}, request: alice, allowed: 5, other: 5},
+		{name: "shared environment", limits: []RateLimit{{By: RateLimitByEnvironment, Rate: 4}}, request: alice, allowed: 4, other: 0},
+		{name: "by ip", limits: []RateLimit{{By: RateLimitByIP, Rate: 2}}, request: alice, allowed: 2, other: 2},
+		{name: "by operation", limits: []RateLimit{{By: RateLimitByOperation, Rate: 2}}, request: alice, allowed: 2, other: 2},
+		{
+			name:    "limit of an operation",
+			limits:  []RateLimit{{By: RateLimitByEnvironment, Operation: "GetFeatureConfig", Rate: 1}},
+			request: alice, allowed: 1, other: 10,
+		},
+		{
+			name:    "operation is matched exactly",
+			limits:  []RateLimit{{By: RateLimitByEnvironment, Operation: "getFeatureConfig", Rate: 1}},
+			request: alice, allowed: 10, other: 10,
+		},
+		{
+			name:    "empty attribute isn't limited",
+			limits:  []RateLimit{{By: RateLimitByAPIKey, Rate: 1}},
+			request: RateLimitRequest{Operation: "Authenticate"}, allowed: 10, other: 1,
+		},
+		{
+			name:    "exceeded limit takes no token of other limits",
+			limits:  []RateLimit{{By: RateLimitByAPIKey, Rate: 2}, {By: RateLimitByEnvironment, Rate: 3}},
+			request: alice, allowed: 2, other: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.New()
+			clk.Freeze()
+			limits := NewRateLimits(clk, tt.limits...)
+			if got := take(limits, tt.request, 10); got != tt.allowed {
+				t.Errorf("allowed %d requests, want %d", got, tt.allowed)
+			}
+			if got := take(limits, bob, 10); got != tt.other {
+				t.Errorf("allowed %d other requests, want %d", got, tt.other)
+			}
+		})
+	}
+}
+
+func TestRateLimitsRefill(t *testing.T) {
+	clk := clock.New()
+	clk.Freeze()
+	limits := NewRateLimits(clk, RateLimit{By: RateLimitByAPIKey, Rate: 2, Burst: 4})
+	request := RateLimitRequest{APIKey: "alice"}
+	if got := take(limits, request, 4); got != 4 {
+		t.Fatalf("allowed %d requests of the burst, want 4", got)
+	}
+
+	limit, wait, ok := limits.Take(request)
+	if ok || limit.By != RateLimitByAPIKey || wait != 500*time.Millisecond {
+		t.Errorf("got %+v, wait %s and allowed %t, want the limit with wait 500ms", limit, wait, ok)
+	}
+
+	clk.Advance(250 * time.Millisecond)
+	if _, wait, ok := limits.Take(request); ok || wait != 250*time.Millisecond {
+		t.Errorf("got wait %s and allowed %t after 250ms, want wait 250ms", wait, ok)
+	}
+	clk.Advance(250 * time.Millisecond)
+	if got := take(limits, request, 4); got != 1 {
+		t.Errorf("allowed %d requests after 500ms, want 1", got)
+	}
+	clk.Advance(time.Hour)
+	if got := take(limits, request, 10); got != 4 {
+		t.Errorf("allowed %d requests after an hour, want the burst of 4", got)
+	}
+}
+
+func TestRateLimitsReplace(t *testing.T) {
+	limits := NewRateLimits(clock.New())
+	if !limits.Empty() {
+		t.Fatal("new rate limits aren't empty")
+	}
+	added := limits.Add(RateLimit{By: RateLimitByIP, Rate: 1})
+	replaced := limits.Replace([]RateLimit{{By: RateLimitByAPIKey, Rate: 1}, {By: RateLimitByIP, Rate: 2}})
+	if len(replaced) != 2 || replaced[0].ID == added.ID || replaced[0].ID == replaced[1].ID {
+		t.Errorf("got replaced limits %+v after %+v, want new ids", replaced, added)
+	}
+	if limits.Remove(added.ID) {
+		t.Error("replaced limit was removed")
+	}
+	if !limits.Remove(replaced[0].ID) || len(limits.Limits()) != 1 {
+		t.Errorf("got limits %+v after removing %s", limits.Limits(), replaced[0].ID)
+	}
+	limits.Clear()
+	if !limits.Empty() {
+		t.Error("rate limits aren't empty after clearing")
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// ErrUnknownSigningKey is returned when token was signed with a key that
+// is not known or was revoked
+var ErrUnknownSigningKey = errors.New("token signed with unknown key")
+
+// SigningKey describes key used for signing tokens
+type SigningKey struct {
+	ID        string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Current   bool   `json:"current"`
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type signingKey struct {
+	id      string
+	private interface{}
+	public  interface{}
+}
+
+// Signer signs and verifies tokens with HMAC, RSA or ECDSA keys, keys
+// replaced by rotation are still used for verification until revoked
+type Signer struct {
+	mu     sync.RWMutex
+	method jwt.SigningMethod
+	keys   []signingKey
+}
+
+// NewSigner returns new Signer for algorithm (HS256, HS384, HS512, RS256
+// or ES256), key is a secret for HMAC or PEM encoded private key, if it is
+// empty for RS256 and ES256 new key is generated
+func NewSigner(algorithm string, key []byte) (*Signer, error) {
+	method := jwt.GetSigningMethod(algorithm)
+	switch method {
+	case jwt.SigningMethodHS256, jwt.SigningMethodHS384, jwt.SigningMethodHS512,
+		jwt.SigningMethodRS256, jwt.SigningMethodES256:
+	default:
+		return nil, fmt.Errorf("unsupported signing method '%s'", algorithm)
+	}
+
+	s := &Signer{method: method}
+	if err := s.Rotate(key, true); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Algorithm returns name of the signing algorithm
+func (s *Signer) Algorithm() string {
+	return s.method.Alg()
+}
+
+// Sign returns token signed with the current key
+func (s *Signer) Sign(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	key := s.keys[0]
+	s.mu.RUnlock()
+
+	token := jwt.NewWithClaims(s.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// KeyFunc returns verification key for the token, it can be passed
+// to jwt.Parse functions
+func (s *Signer) KeyFunc(t *jwt.Token) (interface{}, error) {
+	if t.Method.Alg() != s.method.Alg() {
+		return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	kid, ok := t.Header["kid"].(string)
+	if !ok {
+		return s.keys[0].public, nil
+	}
+	for _, key := range s.keys {
+		if key.id == kid {
+			return key.public, nil
+		}
+	}
+	return nil, ErrUnknownSigningKey
+}
+
+// Rotate replaces current signing key, if key is empty new key is generated.
+// When revokePrevious is set tokens signed with older keys fail verification
+func (s *Signer) Rotate(key []byte, revokePrevious bool) error {
+	newKey, err := s.newKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if revokePrevious {
+		s.keys = nil
+	}
+	s.keys = append([]signingKey{newKey}, s.keys...)
+	return nil
+}
+
+// Keys returns all keys used for verification, current key first
+func (s *Signer) Keys() []SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]SigningKey, 0, len(s.keys))
+	for i, key := range s.keys {
+		keys = append(keys, SigningKey{
+			ID:        key.id,
+			Algorithm: s.method.Alg(),
+			Current:   i == 0,
+		})
+	}
+	return keys
+}
+
+// JWKS returns public keys, HMAC secrets are never published so the set
+// is empty for HS algorithms
+func (s *Signer) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range s.keys {
+		jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: s.method.Alg()}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = encodeBase64(public.N.Bytes())
+			jwk.E = encodeBase64(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwk.KeyType = "EC"
+			jwk.Curve = public.Curve.Params().Name
+			jwk.X = encodeBase64(public.X.FillBytes(make([]byte, size)))
+			jwk.Y = encodeBase64(public.Y.FillBytes(make([]byte, size)))
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func (s *Signer) newKey(key []byte) (signingKey, error) {
+	switch s.method {
+	case jwt.SigningMethodRS256:
+		private, err := loadOrGenerateRSA(key)
+		if err != nil {
+			return signingKey{}, err
+		}
+		return newSigningKey(private, private.Public())
+	case jwt.SigningMethodES256:
+		private, err := loadOrGenerateEC(key)
+		if err != nil {
+			return signingKey{}, err
+		}
+		return newSigningKey(private, private.Public())
+	default:
+		if len(key) == 0 {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return signingKey{}, err
+			}
+		}
+		sum := sha256.Sum256(key)
+		return signingKey{id: hex.EncodeToString(sum[:8]), private: key, public: key}, nil
+	}
+}
+
+func newSigningKey(private interface{}, public crypto.PublicKey) (signingKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return signingKey{}, err
+	}
+	sum := sha256.Sum256(der)
+	return signingKey{id: hex.EncodeToString(sum[:8]), private: private, public: public}, nil
+}
+
+func loadOrGenerateRSA(key []byte) (*rsa.PrivateKey, error) {
+	if len(key) == 0 {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	return jwt.ParseRSAPrivateKeyFromPEM(key)
+}
+
+func loadOrGenerateEC(key []byte) (*ecdsa.PrivateKey, error) {
+	if len(key) == 0 {
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	private, err := jwt.ParseECPrivateKeyFromPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	if private.Curve != elliptic.P256() {
+		return nil, errors.New("ES256 requires P-256 key")
+	}
+	return private, nil
+}
+
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var algorithms = []string{"HS256", "HS384", "HS512", "RS256", "ES256"}
+
+func newTestSigner(t *testing.T, algorithm string, key []byte) *Signer {
+	t.Helper()
+	signer, err := NewSigner(algorithm, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func sign(t *testing.T, signer *Signer) string {
+	t.Helper()
+	token, err := signer.Sign(jwt.StandardClaims{Subject: "subject"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// verify returns error of the key func when the token fails verification
+func verify(signer *Signer, token string) error {
+	_, err := jwt.ParseWithClaims(token, &jwt.StandardClaims{}, signer.KeyFunc)
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Inner != nil {
+		return validationErr.Inner
+	}
+	return err
+}
+
+func TestNewSignerUnsupported(t *testing.T) {
+	for _, algorithm := range []string{"", "none", "PS256", "ES384"} {
+		if _, err := NewSigner(algorithm, nil); err == nil {
+			t.Errorf("NewSigner(%q) didn't fail", algorithm)
+		}
+	}
+}
+
+func TestNewSignerRejectsOtherCurve(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if _, err := NewSigner("ES256", key); err == nil {
+		t.Error("P-384 key was accepted for ES256")
+	}
+}
+
+func TestSignerSignAndVerify(t *testing.T) {
+	for _, algorithm := range algorithms {
+		t.Run(algorithm, func(t *testing.T) {
+			signer := newTestSigner(t, algorithm, nil)
+			token := sign(t, signer)
+			if err := verify(signer, token); err != nil {
+				t.Fatalf("token failed verification: %s", err)
+			}
+
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token, &jwt.StandardClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Header["alg"] != algorithm || parsed.Header["kid"] != signer.Keys()[0].ID {
+				t.Errorf("got header %v, want alg %s and kid %s", parsed.Header, algorithm, signer.Keys()[0].ID)
+			}
+			if err := verify(newTestSigner(t, algorithm, nil), token); err == nil {
+				t.Error("token was verified by other signer")
+			}
+		})
+	}
+}
+
+func TestSignerRejectsOtherAlgorithm(t *testing.T) {
+	token := sign(t, newTestSigner(t, "HS256", []byte("secret")))
+	if err := verify(newTestSigner(t, "HS512", []byte("secret")), token); err == nil {
+		t.Error("HS256 token was verified by HS512 signer")
+	}
+}
+
+func TestSignerKeyIDIsStable(t *testing.T) {
+	a := newTestSigner(t, "HS256", []byte("secret"))
+	b := newTestSigner(t, "HS256", []byte("secret"))
+	if a.Keys()[0].ID != b.Keys()[0].ID {
+		t.Error("same secret got different key ids")
+	}
+	if err := verify(b, sign(t, a)); err != nil {
+		t.Errorf("token of same secret failed verification: %s", err)
+	}
+}
+
+func TestSignerRotate(t *testing.T) {
+	tests := []struct {
+		name           string
+		revokePrevious bool
+		wantErr        error
+		wantKeys       int
+	}{
+		{name: "previous key verifies", wantKeys: 2},
+		{name: "previous key revoked", revokePrevious: true, wantErr: ErrUnknownSigningKey, wantKeys: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := newTestSigner(t, "ES256", nil)
+			previous := sign(t, signer)
+			if err := signer.Rotate(nil, tt.revokePrevious); err != nil {
+				t.Fatal(err)
+			}
+			if err := verify(signer, previous); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err := verify(signer, sign(t, signer)); err != nil {
+				t.Errorf("token of current key failed verification: %s", err)
+			}
+
+			keys := signer.Keys()
+			if len(keys) != tt.wantKeys {
+				t.Fatalf("got %d keys, want %d", len(keys), tt.wantKeys)
+			}
+			for i, key := range keys {
+				if key.Current != (i == 0) || key.Algorithm != "ES256" {
+					t.Errorf("got key %+v at %d", key, i)
+				}
+			}
+		})
+	}
+}
+
+func TestSignerJWKS(t *testing.T) {
+	for _, algorithm := range []string{"HS256", "HS384", "HS512"} {
+		if keys := newTestSigner(t, algorithm, nil).JWKS().Keys; len(keys) != 0 {
+			t.Errorf("%s secrets were published", algorithm)
+		}
+	}
+
+	for _, algorithm := range []string{"RS256", "ES256"} {
+		t.Run(algorithm, func(t *testing.T) {
+			signer := newTestSigner(t, algorithm, nil)
+			if err := signer.Rotate(nil, false); err != nil {
+				t.Fatal(err)
+			}
+			token := sign(t, signer)
+			jwks := signer.JWKS()
+			if len(jwks.Keys) != 2 {
+				t.Fatalf("got %d keys, want 2", len(jwks.Keys))
+			}
+
+			// the token verifies with the published key of its kid
+			jwk := jwks.Keys[0]
+			if jwk.KeyID != signer.Keys()[0].ID || jwk.Use != "sig" || jwk.Algorithm != algorithm {
+				t.Errorf("got key %+v", jwk)
+			}
+			public := publicKey(t, jwk)
+			if _, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return public, nil }); err != nil {
+				t.Errorf("token failed verification with published key: %s", err)
+			}
+		})
+	}
+}
+
+// publicKey decodes the JWK like clients do
+func publicKey(t *testing.T, jwk JWK) interface{} {
+	t.Helper()
+	decode := func(s string) *big.Int {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return new(big.Int).SetBytes(b)
+	}
+	switch jwk.KeyType {
+	case "RSA":
+		return &rsa.PublicKey{N: decode(jwk.N), E: int(decode(jwk.E).Int64())}
+	case "EC":
+		if jwk.Curve != "P-256" || len(jwk.X) != 43 || len(jwk.Y) != 43 {
+			t.Errorf("got curve %s with coordinates %s %s", jwk.Curve, jwk.X, jwk.Y)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: decode(jwk.X), Y: decode(jwk.Y)}
+	}
+	t.Fatalf("unexpected key type %s", jwk.KeyType)
+	return nil
+}
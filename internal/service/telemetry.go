@@ -0,0 +1,127 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unknownKeyType labels authentications with keys that don't exist
+const unknownKeyType = "unknown"
+
+// Telemetry collects Prometheus metrics of the mock server itself, each instance
+// has its own registry so several servers can run in one process
+type Telemetry struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	duration        *prometheus.HistogramVec
+	streams         prometheus.Gauge
+	eventsPublished *prometheus.CounterVec
+	authentications *prometheus.CounterVec
+	metricsPayloads prometheus.Counter
+	faults          *prometheus.CounterVec
+	notModified     *prometheus.CounterVec
+}
+
+// NewTelemetry returns new Telemetry with registered collectors
+func NewTelemetry() *Telemetry {
+	t := &Telemetry{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ff_mock_requests_total",
+			Help: "Requests of the client API by operationId and status",
+		}, []string{"operation", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ff_mock_request_duration_seconds",
+			Help:    "Latency of client API requests by operationId and status",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation", "status"}),
+		streams: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "ff_mock_stream_connections",
+			Help: "Connected SSE streams",
+		}),
+		eventsPublished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ff_mock_stream_events_published_total",
+			Help: "Events published to SSE streams by domain",
+		}, []string{"domain"}),
+		authentications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ff_mock_authentications_total",
+			Help: "Authentications by api key type and result",
+		}, []string{"key_type", "result"}),
+		metricsPayloads: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "ff_mock_metrics_payloads_total",
+			Help: "Metrics payloads received from SDKs",
+		}),
+		faults: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ff_mock_faults_total",
+			Help: "Injected faults fired by operationId and status, status 0 is a delay or network fault",
+		}, []string{"operation", "status"}),
+		notModified: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ff_mock_not_modified_total",
+			Help: "Configuration requests answered with 304 by operationId",
+		}, []string{"operation"}),
+	}
+	t.registry.MustRegister(t.requests, t.duration, t.streams, t.eventsPublished, t.authentications,
+		t.metricsPayloads, t.faults, t.notModified, collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return t
+}
+
+// Handler returns handler serving the metrics in Prometheus text format
+func (t *Telemetry) Handler() http.Handler {
+	return promhttp.HandlerFor(t.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest counts the request and its latency
+func (t *Telemetry) ObserveRequest(operation string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	t.requests.WithLabelValues(operation, code).Inc()
+	t.duration.WithLabelValues(operation, code).Observe(duration.Seconds())
+}
+
+// StreamConnected increments number of connected streams
+func (t *Telemetry) StreamConnected() {
+	t.streams.Inc()
+}
+
+// StreamDisconnected decrements number of connected streams
+func (t *Telemetry) StreamDisconnected() {
+	t.streams.Dec()
+}
+
+// EventPublished counts event of the domain sent to a stream
+func (t *Telemetry) EventPublished(domain string) {
+	t.eventsPublished.WithLabelValues(domain).Inc()
+}
+
+// Authenticated counts authentication with key of the type, empty type means
+// the key doesn't exist
+func (t *Telemetry) Authenticated(keyType string, success bool) {
+	if keyType == "" {
+		keyType = unknownKeyType
+	}
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+	t.authentications.WithLabelValues(keyType, result).Inc()
+}
+
+// MetricsReceived counts metrics payload posted by SDK
+func (t *Telemetry) MetricsReceived() {
+	t.metricsPayloads.Inc()
+}
+
+// FaultFired counts fault injected into request of the operation
+func (t *Telemetry) FaultFired(operation string, status int) {
+	t.faults.WithLabelValues(operation, strconv.Itoa(status)).Inc()
+}
+
+// NotModified counts request of the operation answered with 304
+func (t *Telemetry) NotModified(operation string) {
+	t.notModified.WithLabelValues(operation).Inc()
+}
@@ -0,0 +1,147 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/drone/ff-mock-server/pkg/admin"
+	"github.com/drone/ff-mock-server/pkg/api"
+	"github.com/drone/ff-mock-server/pkg/mockserver"
+)
+
+// request sends the json body to the client api with the token and returns the
+// response status
+func request(t *testing.T, srv *mockserver.Server, url, token string, body interface{}, result interface{}) int {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if result != nil && res.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res.StatusCode
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	client, err := admin.NewClientWithResponses(srv.AdminURL(), admin.WithHTTPClient(srv.Client()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// seed a flag
+	flag := api.FeatureConfig{
+		Feature:      "round-trip",
+		Kind:         "boolean",
+		State:        "on",
+		OffVariation: "false",
+		DefaultServe: api.Serve{Variation: stringPtr("true")},
+		Variations: []api.Variation{
+			{Identifier: "true", Value: "true"},
+			{Identifier: "false", Value: "false"},
+		},
+	}
+	setFlag, err := client.SetFlagWithResponse(ctx, admin.IdentifierPathParam(flag.Feature), admin.SetFlagJSONRequestBody(flag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setFlag.JSON200 == nil {
+		t.Fatalf("flag was set with status %d: %s", setFlag.StatusCode(), setFlag.Body)
+	}
+	flags, err := client.GetFlagsWithResponse(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags.JSON200 == nil || !hasFlag(*flags.JSON200, flag.Feature) {
+		t.Fatalf("seeded flag isn't listed: %s", flags.Body)
+	}
+
+	// fault the next metrics request
+	status, times := http.StatusServiceUnavailable, 1
+	operation := "postMetrics"
+	fault, err := client.CreateFaultWithResponse(ctx, admin.CreateFaultJSONRequestBody{
+		Operation: &operation, Status: &status, Times: &times,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fault.JSON201 == nil || fault.JSON201.Id == nil {
+		t.Fatalf("fault was created with status %d: %s", fault.StatusCode(), fault.Body)
+	}
+	faults, err := client.GetFaultsWithResponse(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if faults.JSON200 == nil || len(*faults.JSON200) != 1 || *(*faults.JSON200)[0].Id != *fault.JSON201.Id {
+		t.Fatalf("got faults %s, want the created one", faults.Body)
+	}
+
+	auth := api.AuthenticationResponse{}
+	if status := request(t, srv, srv.ConfigURL()+"/client/auth", "",
+		api.AuthenticationRequest{ApiKey: srv.ServerKey}, &auth); status != http.StatusOK {
+		t.Fatalf("authentication failed with status %d", status)
+	}
+	metrics := api.Metrics{TargetData: &[]api.TargetData{{Identifier: "alice", Name: "Alice", Attributes: []api.KeyValue{}}}}
+	url := srv.EventsURL() + "/metrics/" + srv.EnvironmentUUID
+	for _, want := range []int{http.StatusServiceUnavailable, http.StatusOK} {
+		if status := request(t, srv, url, auth.AuthToken, metrics, nil); status != want {
+			t.Errorf("metrics were posted with status %d, want %d", status, want)
+		}
+	}
+
+	// assert on what the server saw
+	journal, err := client.GetJournalWithResponse(ctx, &admin.GetJournalParams{Operation: &operation})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if journal.JSON200 == nil || len(*journal.JSON200) != 2 {
+		t.Fatalf("got journal %s, want two metrics requests", journal.Body)
+	}
+	if entry := (*journal.JSON200)[0]; entry.Status != http.StatusServiceUnavailable || entry.Fault == nil || !*entry.Fault {
+		t.Errorf("got first journal entry %+v, want the faulted request", entry)
+	}
+	received, err := client.GetReceivedMetricsWithResponse(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received.JSON200 == nil || len(received.JSON200.Targets) != 1 || received.JSON200.Targets[0].Identifier != "alice" {
+		t.Errorf("got received metrics %s, want target alice", received.Body)
+	}
+}
+
+func hasFlag(flags []api.FeatureConfig, feature string) bool {
+	for _, fc := range flags {
+		if fc.Feature == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
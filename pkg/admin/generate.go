@@ -0,0 +1,7 @@
+// Admin Service
+
+//go:generate oapi-codegen -generate server,spec -templates=../../templates/echo -package=admin -import-mapping=./api.yaml:github.com/drone/ff-mock-server/pkg/api -o services.gen.go ../../admin.yaml
+//go:generate oapi-codegen -generate types -package=admin -import-mapping=./api.yaml:github.com/drone/ff-mock-server/pkg/api -o types.gen.go ../../admin.yaml
+//go:generate oapi-codegen -generate client -package=admin -import-mapping=./api.yaml:github.com/drone/ff-mock-server/pkg/api -o client.gen.go ../../admin.yaml
+
+package admin
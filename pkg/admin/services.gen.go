@@ -0,0 +1,1171 @@
+// Package admin provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.8.3 DO NOT EDIT.
+package admin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/deepmap/oapi-codegen/pkg/runtime"
+	externalRef0 "github.com/drone/ff-mock-server/pkg/api"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+)
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Get accepted api keys
+	// (GET /api-keys)
+	GetAPIKeys(ctx echo.Context) error
+	// Add api key, the key is generated when it is not set
+	// (POST /api-keys)
+	CreateAPIKey(ctx echo.Context) error
+	// Revoke api key and tokens issued with it
+	// (DELETE /api-keys/{key})
+	RevokeAPIKey(ctx echo.Context, key string) error
+	// Get time, speed and state of the server clock
+	// (GET /clock)
+	GetClock(ctx echo.Context) error
+	// Move the server clock forward
+	// (POST /clock/advance)
+	AdvanceClock(ctx echo.Context) error
+	// Stop the server clock
+	// (POST /clock/freeze)
+	FreezeClock(ctx echo.Context) error
+	// Start the frozen server clock from the time it was frozen at
+	// (POST /clock/resume)
+	ResumeClock(ctx echo.Context) error
+	// Change speed of the server clock
+	// (PUT /clock/speed)
+	SetClockSpeed(ctx echo.Context) error
+	// Get compression options of responses
+	// (GET /compression)
+	GetCompression(ctx echo.Context) error
+	// Change compression options of responses
+	// (PUT /compression)
+	SetCompression(ctx echo.Context) error
+	// Get mode of entity tags sent with configuration responses
+	// (GET /etags)
+	GetETags(ctx echo.Context) error
+	// Change mode of entity tags sent with configuration responses
+	// (PUT /etags)
+	SetETags(ctx echo.Context) error
+	// Get availability of stream and metrics
+	// (GET /events/availability)
+	GetEventsAvailability(ctx echo.Context) error
+	// Make stream and metrics available or respond with 503
+	// (PUT /events/availability)
+	SetEventsAvailability(ctx echo.Context) error
+	// Remove all fault rules of stream and metrics
+	// (DELETE /events/faults)
+	ClearEventsFaults(ctx echo.Context) error
+	// Get fault rules of stream and metrics
+	// (GET /events/faults)
+	GetEventsFaults(ctx echo.Context) error
+	// Add fault rule of stream and metrics
+	// (POST /events/faults)
+	CreateEventsFault(ctx echo.Context) error
+	// Remove fault rule of stream and metrics
+	// (DELETE /events/faults/{id})
+	DeleteEventsFault(ctx echo.Context, id string) error
+	// Remove all fault rules
+	// (DELETE /faults)
+	ClearFaults(ctx echo.Context) error
+	// Get active fault rules
+	// (GET /faults)
+	GetFaults(ctx echo.Context) error
+	// Add fault rule
+	// (POST /faults)
+	CreateFault(ctx echo.Context) error
+	// Remove fault rule
+	// (DELETE /faults/{id})
+	DeleteFault(ctx echo.Context, id string) error
+	// Get all flags
+	// (GET /flags)
+	GetFlags(ctx echo.Context) error
+	// Delete flag and notify connected SDKs
+	// (DELETE /flags/{identifier})
+	DeleteFlag(ctx echo.Context, identifier IdentifierPathParam) error
+	// Create or replace flag and notify connected SDKs
+	// (PUT /flags/{identifier})
+	SetFlag(ctx echo.Context, identifier IdentifierPathParam) error
+	// Sign IdentityService token
+	// (POST /identity-tokens)
+	CreateIdentityToken(ctx echo.Context) error
+	// Remove recorded requests
+	// (DELETE /journal)
+	ClearJournal(ctx echo.Context) error
+	// Get requests received by the client api
+	// (GET /journal)
+	GetJournal(ctx echo.Context, params GetJournalParams) error
+	// Remove received metrics
+	// (DELETE /metrics)
+	ClearReceivedMetrics(ctx echo.Context) error
+	// Get metrics received from SDKs
+	// (GET /metrics)
+	GetReceivedMetrics(ctx echo.Context) error
+	// Remove all network faults
+	// (DELETE /network-faults)
+	ClearNetworkFaults(ctx echo.Context) error
+	// Get active network faults
+	// (GET /network-faults)
+	GetNetworkFaults(ctx echo.Context) error
+	// Add network fault
+	// (POST /network-faults)
+	CreateNetworkFault(ctx echo.Context) error
+	// Remove network fault
+	// (DELETE /network-faults/{id})
+	DeleteNetworkFault(ctx echo.Context, id string) error
+	// Remove all rate limits
+	// (DELETE /rate-limits)
+	ClearRateLimits(ctx echo.Context) error
+	// Get active rate limits
+	// (GET /rate-limits)
+	GetRateLimits(ctx echo.Context) error
+	// Add rate limit
+	// (POST /rate-limits)
+	CreateRateLimit(ctx echo.Context) error
+	// Remove rate limit
+	// (DELETE /rate-limits/{id})
+	DeleteRateLimit(ctx echo.Context, id string) error
+	// Restore the latest snapshot, the state on startup unless other snapshots were saved
+	// (POST /reset)
+	ResetState(ctx echo.Context) error
+	// Get all target groups
+	// (GET /segments)
+	GetSegments(ctx echo.Context) error
+	// Delete target group and notify connected SDKs
+	// (DELETE /segments/{identifier})
+	DeleteSegment(ctx echo.Context, identifier IdentifierPathParam) error
+	// Create or replace target group and notify connected SDKs
+	// (PUT /segments/{identifier})
+	SetSegment(ctx echo.Context, identifier IdentifierPathParam) error
+	// Get keys used for verifying tokens
+	// (GET /signing-keys)
+	GetSigningKeys(ctx echo.Context) error
+	// Replace the signing key
+	// (POST /signing-keys/rotate)
+	RotateSigningKey(ctx echo.Context) error
+	// Get saved snapshots, the latest is the last one
+	// (GET /snapshots)
+	GetSnapshots(ctx echo.Context) error
+	// Save the state as named snapshot on top of the stack
+	// (POST /snapshots)
+	SaveSnapshot(ctx echo.Context) error
+	// Remove snapshot
+	// (DELETE /snapshots/{name})
+	DeleteSnapshot(ctx echo.Context, name SnapshotPathParam) error
+	// Restore the snapshot, snapshots saved after it are removed
+	// (POST /snapshots/{name}/restore)
+	RestoreSnapshot(ctx echo.Context, name SnapshotPathParam) error
+	// Export flags, segments, api keys, token options, fault rules and availability as one document
+	// (GET /state)
+	ExportState(ctx echo.Context) error
+	// Replace the state with the document
+	// (PUT /state)
+	ImportState(ctx echo.Context) error
+	// Get connected streams
+	// (GET /streams)
+	GetStreams(ctx echo.Context) error
+	// Get availability of the stream endpoint
+	// (GET /streams/availability)
+	GetStreamAvailability(ctx echo.Context) error
+	// Make the stream endpoint available or respond with 503
+	// (PUT /streams/availability)
+	SetStreamAvailability(ctx echo.Context) error
+	// Disconnect streams, SDKs are expected to reconnect
+	// (POST /streams/disconnect)
+	DisconnectStreams(ctx echo.Context, params DisconnectStreamsParams) error
+	// Publish event to streams
+	// (POST /streams/events)
+	PublishStreamEvent(ctx echo.Context, params PublishStreamEventParams) error
+	// Get tenants created on the server
+	// (GET /tenants)
+	GetTenants(ctx echo.Context) error
+	// Create tenant with its own api keys, environment and state
+	// (POST /tenants)
+	CreateTenant(ctx echo.Context) error
+	// Remove tenant, its streams are disconnected and its state dropped
+	// (DELETE /tenants/{id})
+	DeleteTenant(ctx echo.Context, id TenantPathParam) error
+	// Get tenant
+	// (GET /tenants/{id})
+	GetTenant(ctx echo.Context, id TenantPathParam) error
+	// Get options of issued tokens
+	// (GET /tokens)
+	GetTokenOptions(ctx echo.Context) error
+	// Change options of tokens issued from now on
+	// (PUT /tokens)
+	SetTokenOptions(ctx echo.Context) error
+	// Expire all tokens issued so far
+	// (POST /tokens/expire)
+	ExpireTokens(ctx echo.Context) error
+	// Reject next authenticated requests with 401
+	// (POST /tokens/reject)
+	RejectTokens(ctx echo.Context) error
+}
+
+// ServerInterfaceWrapper converts echo contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+
+// GetAPIKeys converts echo context to params.
+func (w *ServerInterfaceWrapper) GetAPIKeys(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetAPIKeys(ctx)
+	return err
+}
+
+// CreateAPIKey converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateAPIKey(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateAPIKey(ctx)
+	return err
+}
+
+// RevokeAPIKey converts echo context to params.
+func (w *ServerInterfaceWrapper) RevokeAPIKey(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "key" -------------
+	var key string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "key", runtime.ParamLocationPath, ctx.Param("key"), &key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RevokeAPIKey(ctx, key)
+	return err
+}
+
+// GetClock converts echo context to params.
+func (w *ServerInterfaceWrapper) GetClock(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetClock(ctx)
+	return err
+}
+
+// AdvanceClock converts echo context to params.
+func (w *ServerInterfaceWrapper) AdvanceClock(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.AdvanceClock(ctx)
+	return err
+}
+
+// FreezeClock converts echo context to params.
+func (w *ServerInterfaceWrapper) FreezeClock(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.FreezeClock(ctx)
+	return err
+}
+
+// ResumeClock converts echo context to params.
+func (w *ServerInterfaceWrapper) ResumeClock(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ResumeClock(ctx)
+	return err
+}
+
+// SetClockSpeed converts echo context to params.
+func (w *ServerInterfaceWrapper) SetClockSpeed(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetClockSpeed(ctx)
+	return err
+}
+
+// GetCompression converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCompression(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetCompression(ctx)
+	return err
+}
+
+// SetCompression converts echo context to params.
+func (w *ServerInterfaceWrapper) SetCompression(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetCompression(ctx)
+	return err
+}
+
+// GetETags converts echo context to params.
+func (w *ServerInterfaceWrapper) GetETags(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetETags(ctx)
+	return err
+}
+
+// SetETags converts echo context to params.
+func (w *ServerInterfaceWrapper) SetETags(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetETags(ctx)
+	return err
+}
+
+// GetEventsAvailability converts echo context to params.
+func (w *ServerInterfaceWrapper) GetEventsAvailability(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetEventsAvailability(ctx)
+	return err
+}
+
+// SetEventsAvailability converts echo context to params.
+func (w *ServerInterfaceWrapper) SetEventsAvailability(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetEventsAvailability(ctx)
+	return err
+}
+
+// ClearEventsFaults converts echo context to params.
+func (w *ServerInterfaceWrapper) ClearEventsFaults(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ClearEventsFaults(ctx)
+	return err
+}
+
+// GetEventsFaults converts echo context to params.
+func (w *ServerInterfaceWrapper) GetEventsFaults(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetEventsFaults(ctx)
+	return err
+}
+
+// CreateEventsFault converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateEventsFault(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateEventsFault(ctx)
+	return err
+}
+
+// DeleteEventsFault converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteEventsFault(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteEventsFault(ctx, id)
+	return err
+}
+
+// ClearFaults converts echo context to params.
+func (w *ServerInterfaceWrapper) ClearFaults(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ClearFaults(ctx)
+	return err
+}
+
+// GetFaults converts echo context to params.
+func (w *ServerInterfaceWrapper) GetFaults(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetFaults(ctx)
+	return err
+}
+
+// CreateFault converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateFault(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateFault(ctx)
+	return err
+}
+
+// DeleteFault converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteFault(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteFault(ctx, id)
+	return err
+}
+
+// GetFlags converts echo context to params.
+func (w *ServerInterfaceWrapper) GetFlags(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetFlags(ctx)
+	return err
+}
+
+// DeleteFlag converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteFlag(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "identifier" -------------
+	var identifier IdentifierPathParam
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "identifier", runtime.ParamLocationPath, ctx.Param("identifier"), &identifier)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter identifier: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteFlag(ctx, identifier)
+	return err
+}
+
+// SetFlag converts echo context to params.
+func (w *ServerInterfaceWrapper) SetFlag(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "identifier" -------------
+	var identifier IdentifierPathParam
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "identifier", runtime.ParamLocationPath, ctx.Param("identifier"), &identifier)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter identifier: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetFlag(ctx, identifier)
+	return err
+}
+
+// CreateIdentityToken converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateIdentityToken(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateIdentityToken(ctx)
+	return err
+}
+
+// ClearJournal converts echo context to params.
+func (w *ServerInterfaceWrapper) ClearJournal(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ClearJournal(ctx)
+	return err
+}
+
+// GetJournal converts echo context to params.
+func (w *ServerInterfaceWrapper) GetJournal(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetJournalParams
+	// ------------- Optional query parameter "operation" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "operation", ctx.QueryParams(), &params.Operation)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter operation: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetJournal(ctx, params)
+	return err
+}
+
+// ClearReceivedMetrics converts echo context to params.
+func (w *ServerInterfaceWrapper) ClearReceivedMetrics(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ClearReceivedMetrics(ctx)
+	return err
+}
+
+// GetReceivedMetrics converts echo context to params.
+func (w *ServerInterfaceWrapper) GetReceivedMetrics(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetReceivedMetrics(ctx)
+	return err
+}
+
+// ClearNetworkFaults converts echo context to params.
+func (w *ServerInterfaceWrapper) ClearNetworkFaults(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ClearNetworkFaults(ctx)
+	return err
+}
+
+// GetNetworkFaults converts echo context to params.
+func (w *ServerInterfaceWrapper) GetNetworkFaults(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetNetworkFaults(ctx)
+	return err
+}
+
+// CreateNetworkFault converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateNetworkFault(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateNetworkFault(ctx)
+	return err
+}
+
+// DeleteNetworkFault converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteNetworkFault(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteNetworkFault(ctx, id)
+	return err
+}
+
+// ClearRateLimits converts echo context to params.
+func (w *ServerInterfaceWrapper) ClearRateLimits(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ClearRateLimits(ctx)
+	return err
+}
+
+// GetRateLimits converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRateLimits(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetRateLimits(ctx)
+	return err
+}
+
+// CreateRateLimit converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateRateLimit(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateRateLimit(ctx)
+	return err
+}
+
+// DeleteRateLimit converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteRateLimit(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteRateLimit(ctx, id)
+	return err
+}
+
+// ResetState converts echo context to params.
+func (w *ServerInterfaceWrapper) ResetState(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ResetState(ctx)
+	return err
+}
+
+// GetSegments converts echo context to params.
+func (w *ServerInterfaceWrapper) GetSegments(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetSegments(ctx)
+	return err
+}
+
+// DeleteSegment converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteSegment(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "identifier" -------------
+	var identifier IdentifierPathParam
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "identifier", runtime.ParamLocationPath, ctx.Param("identifier"), &identifier)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter identifier: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteSegment(ctx, identifier)
+	return err
+}
+
+// SetSegment converts echo context to params.
+func (w *ServerInterfaceWrapper) SetSegment(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "identifier" -------------
+	var identifier IdentifierPathParam
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "identifier", runtime.ParamLocationPath, ctx.Param("identifier"), &identifier)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter identifier: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetSegment(ctx, identifier)
+	return err
+}
+
+// GetSigningKeys converts echo context to params.
+func (w *ServerInterfaceWrapper) GetSigningKeys(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetSigningKeys(ctx)
+	return err
+}
+
+// RotateSigningKey converts echo context to params.
+func (w *ServerInterfaceWrapper) RotateSigningKey(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RotateSigningKey(ctx)
+	return err
+}
+
+// GetSnapshots converts echo context to params.
+func (w *ServerInterfaceWrapper) GetSnapshots(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetSnapshots(ctx)
+	return err
+}
+
+// SaveSnapshot converts echo context to params.
+func (w *ServerInterfaceWrapper) SaveSnapshot(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SaveSnapshot(ctx)
+	return err
+}
+
+// DeleteSnapshot converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteSnapshot(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name SnapshotPathParam
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteSnapshot(ctx, name)
+	return err
+}
+
+// RestoreSnapshot converts echo context to params.
+func (w *ServerInterfaceWrapper) RestoreSnapshot(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name SnapshotPathParam
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RestoreSnapshot(ctx, name)
+	return err
+}
+
+// ExportState converts echo context to params.
+func (w *ServerInterfaceWrapper) ExportState(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ExportState(ctx)
+	return err
+}
+
+// ImportState converts echo context to params.
+func (w *ServerInterfaceWrapper) ImportState(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ImportState(ctx)
+	return err
+}
+
+// GetStreams converts echo context to params.
+func (w *ServerInterfaceWrapper) GetStreams(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetStreams(ctx)
+	return err
+}
+
+// GetStreamAvailability converts echo context to params.
+func (w *ServerInterfaceWrapper) GetStreamAvailability(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetStreamAvailability(ctx)
+	return err
+}
+
+// SetStreamAvailability converts echo context to params.
+func (w *ServerInterfaceWrapper) SetStreamAvailability(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetStreamAvailability(ctx)
+	return err
+}
+
+// DisconnectStreams converts echo context to params.
+func (w *ServerInterfaceWrapper) DisconnectStreams(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DisconnectStreamsParams
+	// ------------- Optional query parameter "key" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "key", ctx.QueryParams(), &params.Key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DisconnectStreams(ctx, params)
+	return err
+}
+
+// PublishStreamEvent converts echo context to params.
+func (w *ServerInterfaceWrapper) PublishStreamEvent(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PublishStreamEventParams
+	// ------------- Optional query parameter "key" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "key", ctx.QueryParams(), &params.Key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PublishStreamEvent(ctx, params)
+	return err
+}
+
+// GetTenants converts echo context to params.
+func (w *ServerInterfaceWrapper) GetTenants(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetTenants(ctx)
+	return err
+}
+
+// CreateTenant converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateTenant(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateTenant(ctx)
+	return err
+}
+
+// DeleteTenant converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteTenant(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id TenantPathParam
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteTenant(ctx, id)
+	return err
+}
+
+// GetTenant converts echo context to params.
+func (w *ServerInterfaceWrapper) GetTenant(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id TenantPathParam
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetTenant(ctx, id)
+	return err
+}
+
+// GetTokenOptions converts echo context to params.
+func (w *ServerInterfaceWrapper) GetTokenOptions(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetTokenOptions(ctx)
+	return err
+}
+
+// SetTokenOptions converts echo context to params.
+func (w *ServerInterfaceWrapper) SetTokenOptions(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetTokenOptions(ctx)
+	return err
+}
+
+// ExpireTokens converts echo context to params.
+func (w *ServerInterfaceWrapper) ExpireTokens(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ExpireTokens(ctx)
+	return err
+}
+
+// RejectTokens converts echo context to params.
+func (w *ServerInterfaceWrapper) RejectTokens(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RejectTokens(ctx)
+	return err
+}
+
+// This is a simple interface which specifies echo.Route addition functions which
+// are present on both echo.Echo and echo.Group, since we want to allow using
+// either of them for path registration
+type EchoRouter interface {
+	CONNECT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	DELETE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	HEAD(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	OPTIONS(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PATCH(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	PUT(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	TRACE(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// RegisterHandlers adds each server route to the EchoRouter.
+func RegisterHandlers(router EchoRouter, si ServerInterface) {
+	RegisterHandlersWithBaseURL(router, si, "")
+}
+
+// Registers handlers, and prepends BaseURL to the paths, so that the paths
+// can be served under a prefix.
+func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL string) {
+
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	router.GET(baseURL+"/api-keys", wrapper.GetAPIKeys).Name = "GetAPIKeys"
+	router.POST(baseURL+"/api-keys", wrapper.CreateAPIKey).Name = "CreateAPIKey"
+	router.DELETE(baseURL+"/api-keys/:key", wrapper.RevokeAPIKey).Name = "RevokeAPIKey"
+	router.GET(baseURL+"/clock", wrapper.GetClock).Name = "GetClock"
+	router.POST(baseURL+"/clock/advance", wrapper.AdvanceClock).Name = "AdvanceClock"
+	router.POST(baseURL+"/clock/freeze", wrapper.FreezeClock).Name = "FreezeClock"
+	router.POST(baseURL+"/clock/resume", wrapper.ResumeClock).Name = "ResumeClock"
+	router.PUT(baseURL+"/clock/speed", wrapper.SetClockSpeed).Name = "SetClockSpeed"
+	router.GET(baseURL+"/compression", wrapper.GetCompression).Name = "GetCompression"
+	router.PUT(baseURL+"/compression", wrapper.SetCompression).Name = "SetCompression"
+	router.GET(baseURL+"/etags", wrapper.GetETags).Name = "GetETags"
+	router.PUT(baseURL+"/etags", wrapper.SetETags).Name = "SetETags"
+	router.GET(baseURL+"/events/availability", wrapper.GetEventsAvailability).Name = "GetEventsAvailability"
+	router.PUT(baseURL+"/events/availability", wrapper.SetEventsAvailability).Name = "SetEventsAvailability"
+	router.DELETE(baseURL+"/events/faults", wrapper.ClearEventsFaults).Name = "ClearEventsFaults"
+	router.GET(baseURL+"/events/faults", wrapper.GetEventsFaults).Name = "GetEventsFaults"
+	router.POST(baseURL+"/events/faults", wrapper.CreateEventsFault).Name = "CreateEventsFault"
+	router.DELETE(baseURL+"/events/faults/:id", wrapper.DeleteEventsFault).Name = "DeleteEventsFault"
+	router.DELETE(baseURL+"/faults", wrapper.ClearFaults).Name = "ClearFaults"
+	router.GET(baseURL+"/faults", wrapper.GetFaults).Name = "GetFaults"
+	router.POST(baseURL+"/faults", wrapper.CreateFault).Name = "CreateFault"
+	router.DELETE(baseURL+"/faults/:id", wrapper.DeleteFault).Name = "DeleteFault"
+	router.GET(baseURL+"/flags", wrapper.GetFlags).Name = "GetFlags"
+	router.DELETE(baseURL+"/flags/:identifier", wrapper.DeleteFlag).Name = "DeleteFlag"
+	router.PUT(baseURL+"/flags/:identifier", wrapper.SetFlag).Name = "SetFlag"
+	router.POST(baseURL+"/identity-tokens", wrapper.CreateIdentityToken).Name = "CreateIdentityToken"
+	router.DELETE(baseURL+"/journal", wrapper.ClearJournal).Name = "ClearJournal"
+	router.GET(baseURL+"/journal", wrapper.GetJournal).Name = "GetJournal"
+	router.DELETE(baseURL+"/metrics", wrapper.ClearReceivedMetrics).Name = "ClearReceivedMetrics"
+	router.GET(baseURL+"/metrics", wrapper.GetReceivedMetrics).Name = "GetReceivedMetrics"
+	router.DELETE(baseURL+"/network-faults", wrapper.ClearNetworkFaults).Name = "ClearNetworkFaults"
+	router.GET(baseURL+"/network-faults", wrapper.GetNetworkFaults).Name = "GetNetworkFaults"
+	router.POST(baseURL+"/network-faults", wrapper.CreateNetworkFault).Name = "CreateNetworkFault"
+	router.DELETE(baseURL+"/network-faults/:id", wrapper.DeleteNetworkFault).Name = "DeleteNetworkFault"
+	router.DELETE(baseURL+"/rate-limits", wrapper.ClearRateLimits).Name = "ClearRateLimits"
+	router.GET(baseURL+"/rate-limits", wrapper.GetRateLimits).Name = "GetRateLimits"
+	router.POST(baseURL+"/rate-limits", wrapper.CreateRateLimit).Name = "CreateRateLimit"
+	router.DELETE(baseURL+"/rate-limits/:id", wrapper.DeleteRateLimit).Name = "DeleteRateLimit"
+	router.POST(baseURL+"/reset", wrapper.ResetState).Name = "ResetState"
+	router.GET(baseURL+"/segments", wrapper.GetSegments).Name = "GetSegments"
+	router.DELETE(baseURL+"/segments/:identifier", wrapper.DeleteSegment).Name = "DeleteSegment"
+	router.PUT(baseURL+"/segments/:identifier", wrapper.SetSegment).Name = "SetSegment"
+	router.GET(baseURL+"/signing-keys", wrapper.GetSigningKeys).Name = "GetSigningKeys"
+	router.POST(baseURL+"/signing-keys/rotate", wrapper.RotateSigningKey).Name = "RotateSigningKey"
+	router.GET(baseURL+"/snapshots", wrapper.GetSnapshots).Name = "GetSnapshots"
+	router.POST(baseURL+"/snapshots", wrapper.SaveSnapshot).Name = "SaveSnapshot"
+	router.DELETE(baseURL+"/snapshots/:name", wrapper.DeleteSnapshot).Name = "DeleteSnapshot"
+	router.POST(baseURL+"/snapshots/:name/restore", wrapper.RestoreSnapshot).Name = "RestoreSnapshot"
+	router.GET(baseURL+"/state", wrapper.ExportState).Name = "ExportState"
+	router.PUT(baseURL+"/state", wrapper.ImportState).Name = "ImportState"
+	router.GET(baseURL+"/streams", wrapper.GetStreams).Name = "GetStreams"
+	router.GET(baseURL+"/streams/availability", wrapper.GetStreamAvailability).Name = "GetStreamAvailability"
+	router.PUT(baseURL+"/streams/availability", wrapper.SetStreamAvailability).Name = "SetStreamAvailability"
+	router.POST(baseURL+"/streams/disconnect", wrapper.DisconnectStreams).Name = "DisconnectStreams"
+	router.POST(baseURL+"/streams/events", wrapper.PublishStreamEvent).Name = "PublishStreamEvent"
+	router.GET(baseURL+"/tenants", wrapper.GetTenants).Name = "GetTenants"
+	router.POST(baseURL+"/tenants", wrapper.CreateTenant).Name = "CreateTenant"
+	router.DELETE(baseURL+"/tenants/:id", wrapper.DeleteTenant).Name = "DeleteTenant"
+	router.GET(baseURL+"/tenants/:id", wrapper.GetTenant).Name = "GetTenant"
+	router.GET(baseURL+"/tokens", wrapper.GetTokenOptions).Name = "GetTokenOptions"
+	router.PUT(baseURL+"/tokens", wrapper.SetTokenOptions).Name = "SetTokenOptions"
+	router.POST(baseURL+"/tokens/expire", wrapper.ExpireTokens).Name = "ExpireTokens"
+	router.POST(baseURL+"/tokens/reject", wrapper.RejectTokens).Name = "RejectTokens"
+
+} // Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+w9W3PbNpd/BcPdmX2hY7fN7sznt9RxdrNp2myc76mTB5g8klCRAAuAcpSM//sODgAS",
+	"JEGKskX1Mt9TYhIEzv2GA+hbkomyEhy4Vsn1t6SikpagQeJfLAeu2YqB/ED15oN5h495cp1UVG+SNOG0",
+	"hOQ6GJmkiYTfayYhT661rCFNVLaBkpov9b4yo5WWjK+Tx8c02cL+/2qQ+2byHFQmWaWZMKvQipEt7IlY",
+	"Eb0BorQEWqaEFoX7vyJUAqkV5ORhA5xwoYkCnaQWyt/N3C2YW9gn0/AoTiu1EfoQwvjPcahq4JTrw5Q8",
+	"atpHM1hVgitAlv1I84/wew1Km78ywTVw/C+tqoJl1ND18jdliPstmPbfJayS6+TFJa3Yiz0ti3+7bAXj",
+	"0o5Tl7dSCmkX7bLpR5oT6ZZ9TJMbwVcFy84KwqtCAs33BL4wpZWB4meh34ia5+eE4mehyQoXNe/ccDPv",
+	"qw9v38He/K+SogKpmeUXzTJRcx1hbZpkRa00yLetbsVGAd8xKXjp0OvCE7wkWUFZaTSJKVVDTrTYAlcp",
+	"KlYpsi3kJBzeU6fBuluLzuC5kGvK2VdqITgw4ABu9sG3BHhdJte/Jncgd2hibgpm8E2TD1J82Sef04i+",
+	"tUr0q1N8HNOOFfe/QYYC+2pHWUHvWcF0jEf2bQEBjPdCFED5YKF2bGydm0Jk2+ECKym+Ao/NniaqAsiH",
+	"jL0zj4kRTSqRl+TBGMXMLJCS74msuSL6gWVAqCIrqgyxVkKWVCfXSS5qA2IDIa/Le5BIcVbCcLWbWkoj",
+	"E+att8W4VGdSquECvz/EDTfIopZ69EcJ9irfUZ7BkG55LRsx6wL82r0hjJOSFQVTkAmeqxBexvV/vUzS",
+	"pGSclUa+rhoAGNewBjkAvFlwFNY7z64upE/iYlkrTe6BVEIxzXYwh4M9gO26UWhFWUlQypGvC27J+B37",
+	"GhGE95ZWRLGvYGh7v9egjEQ0TohkbmLIzQAOa6EZ1cbE5JAMKZwm+CJQ8uaTJE3EapWkyYMUfH0BPBO5",
+	"Eac00bLmGdUoPTV3nqeDaOh3+6jffqJrFUG6B4fSZlkUEKXsukrTAuKrhFTHmWJEv93RokYJuvFWvwtD",
+	"3xkEZFoVdB01kjsq2Zi17QGGc4RfpG7FGLBvaF3oj3URUzwo6D6idebxHJUbYsdQO4wL/4UXex/3DHAt",
+	"QSm6hrhfqWDMHDSv3ubefK0MdtCELYoUzIgQud8TTq2VY1oRKWoNNuBsJrExp5/gkJ9UmupaRXQfnxMJ",
+	"upYclUVpoA18XqFS9xdCSZgighd7ggwYXTygqzG1kcV/RnNh1urTwa1XF0A47EAS+FIxCerQUjFFs95d",
+	"743bZhncmABEjcZAb/MoW6GkrJgT+Uy9PxRDfanQD8+QU6pnjrTx/ERQM3hRK5AjX/UdqHmbBoSL6e//",
+	"ilpyWtxyLSNBzb3I4/HbaZxqxH4ZQYuoAbhQMxR68kAVYdxgYlWSWjlFuUzSSJhUgt6I/LBdGLzF7Cv2",
+	"wqaO19+mNDqubx0BOToqasFtsEp9juhWTqcDkZ9BPwi5feMJ3tO2lQY55MOP3pMbPhjhIAq4CT9WQgI+",
+	"zATnkFkBUCSXoqrQAQ+JYD5/PeUi7kE/AHCyAZqDVITy3K75JMnCIOQDyDv8aLjmR6qhRYspoiXLtgXk",
+	"hOq08wJRppoInsFh2+qSyp+Ar/VmuOyNfX1h35McsoIaxpPSUFRIUoBSLoLSG8pbSDZUpWaAWZwSF9ql",
+	"TWCylkA1PlElLQr8X0kLQ6loDJQmOVOOexGOSFH1+Ysygg954ygQMgstcyMCmCRYKmWFUBCFwfL6gFig",
+	"sDVSIcFyZMmI4k8aNTzRb+PT5ztuozE/sZJFzMd9LVXMjJuEwFuPOtuCTok0eidNNQRyUldGwWKFuphC",
+	"R5TYRUBUa8nuaw2EuoXMvFuoTOEllEhasXeY9YdhQte8sioqqYtITmHI+RzJ8RMckhxD9VHyKVKBJFaP",
+	"iIQVKwqbd7Z8m1cnkKDl/lXclbzBzw1GH82oCxzmtDp1iw/Essm6Gh03nvNwrnW/TxzOMVf4ETJgO8jf",
+	"g7H5kdgTmowsom1tukYw0FJE1WVpmWZSKfRbYTLFNJQqKCPGi4e9JLBVQCol3dugZF8ImuNU/TkPlCY9",
+	"ppFZNZVr0BE8P9kXREIlpBPKu9fvVEqAZhtiv0OnmKTHwmPnfk01HYLUY2WDddphSwt4nMPmf5+wlhlU",
+	"vkfS6jFrKsHFmq05vSJZAdS4IVIBN4UHN8qFZvOrRuMp9kehqYY7tuaMr9/BfhQBV2zth86ZNHyR5MPt",
+	"e4LlEchJJdnOGN4t7FOyBm6MyByzATuxhQ8Sdkx0gtuw0jlAoAV9CDMt4vWKzBYT4+XOLcsPpz9b3CQx",
+	"87ezxch75zZzhrCNJ2fR+uenoO6psAZta3Rt5uL3jTBzUXRnC5vHJwFuXwnHTqE0KimcxhD475lyEOWx",
+	"dh6lx170r1ETFTUEbgckYpeybilyapKwavmYJqDp+v3Ty3bGzpjpX00V+P0gTKfmo9tWzyIYr045V0HX",
+	"0akOGOY3QHUtwezVsXVsYh6kkfNh7SSfkVmlDy3nT9lGo7H5oCro/q4VH5rnzEg9LT4E8mpjuJ5751qy",
+	"Num1M0FOlJ2MFLDSJjaqXFUTA+wmtrm4sB80w1esAJXa+pwKqxrOYtjhxp+4L16Qt6VzuO6JIkpTqckD",
+	"0xv8lMMX3eysRnRTwboEHqflAf7f2U9jNLVb63dIqkPMuQvHNt8eUince/ylauKuqRU+hWNjFW2VBIRI",
+	"G8PUW6XRup5Cd0SyL/URfIZGI7BDXWPWo2TUnuOAWMTik/GRQlM0Ioi2SyTzNkfDFcchPddOqV3tdgex",
+	"XZJclJTx0Oi7jQ0bKF44YRi3+OGnGVZTbI0t2yTGSBSgYSQznCwj70B6K3SwQNGjhgUr9ail3Y4aP/E4",
+	"pVpl7Yf2W1BNr4xYrQrGTS65tRWxiwulANMY/N+FqDURjcJ0qS5Wq9ejteE7l9W1YkeA55VgXLuqbq6s",
+	"WfvPqx9cfYn6kSZmwsJRfiCwxg3BO2MO3X5wHAiPrtJ078takPvykt7AHjNqu2RKTLVkH5a/agWqNb+A",
+	"BfQg8ZmGcJDiDDj2CRuBIjqPTQ3vRvoqJvs8/vnPt6+91ts+I9Ite4wUOQaPK9NPMQaCdWTxtz15xgi9",
+	"C0L7dRqgGiz5eZRWo9Eui5R7fwKtQaqU5GzNtC0u51RtQM3Ph6Js6/msHiRK1SNmoWAriOcVP7k3Jrpw",
+	"hY+UfAUpSAmUK9ei06nnzSt+cqF/RGkfrvnLaqUAIxh+v3JNQSspSpQdxIL0QJqzZI/9DcqpJ0wI05DR",
+	"5nvGVyJeRZeiaLqUfDRFzWYQR0gt9KDQX2umjftJXHhr6zSdD/PS1K8rpgLDep189+LqxZUr63FaseQ6",
+	"+QEf2c0X5LEJqC62LulZA8pjUOrDPEvbPMdM3mnM+/7q6qhGtGflVMPGtF/eIY9M/YrKvYWU0CyDyiiD",
+	"ixtsrWWtXFCgks/GIggVQfQGvaZb3/IelP7RbSoe2243iVxXtkwg/zig7XeLrNqTREQ5N9R+eXU1Nk8D",
+	"2GXQiomf/OPwJ03jZJdXr/KGRbbMb2I8pvrWjGEpPLBpXVY+pq0AX37bwv7RtXKAhiGDP2I9qGFw2Bj8",
+	"a7RxddsIwrzO1c8DJr6Mla0NFI7mLw8TsOn57BLQTuNpiB7BmVbXC4mRCRshWua79sZU3rb1PVPhJ8se",
+	"uMBctTZGMSXY+oWoKk11tHwVoGv/DvC9pEHXnVA6XhSTiuQ1+OTYOC1rkZkEwkypnFENxT5Je0RzLX0t",
+	"4U5vPjqtg7OMyJn49QTz0WHxe7GDYSVyJeQDlfkkS1cS4OsUR61O5IL/h3bhhhMgDNPFakWUC779qEqK",
+	"tQSliPHFRdsYaiyRk6DcVKglqLqEfCAIbxCkP5EC3WlRDag7SVWLWkjVviU17/9UKJoaE1azsPO2J0g+",
+	"FrRRoK1ou4FUT1Ki6XKt6ggd7pyhtC2yCyq9XeDvpPI3G8rX4Ez6EYa8W1gfdV+dktVyFAqWmevKAgx8",
+	"XaLTbRwiHkyPcWsdsXBY1sNW5LZRuZ3NTE35HrubU9LtOiYFvYdCkfVXVpleGAaKUEVyWBVUQ0qalmSS",
+	"1doN83WItvDAJAFuvXLQt0wU8Nx946Y2AYkpxrzCEP3i1kHxgtwFx55sXtg2XCdpROV6zF1A5/p8PaPS",
+	"HRapE6nekwXR6CFot0UzpoG2I31BQtkF5mqd0Q+Dne3eJQZ624iFYXKGO0a+FzRGAYvvqBK+txtzdhkr",
+	"+lwQAyJRAhsPCC0ezFbJ2pmAAnTbHKpSght69vstQIXQoZKi3zKNGWa0qGUGZEPb9AhdGZOq2VNxETLl",
+	"6gGkIj9cvSRamJGwA98Gh1sMJobxewwkQ5mIqlvLydMrWsDE86nYpOScSLlOJW+oa7g5c0l7+xSjmhfu",
+	"5djRCxKzs87sKk3wkaGSi8aN3Jau2ScgBeIzrns3TTU8PD/b9of6WoKdh9xDJkogNafBbtdQ6ONEXKAk",
+	"NKDf+RRhDu+endrRLUT4Sxrqu1TKbKc0uykx7gea0PYajJV6bgqg8ra3JzqjNFOKHQwrLeYp9i+2Hfvq",
+	"GLF1mtrLTHHPpqoK3Ij3W/zGVjv8TMukEh3Zte3DcmdCZo677qaIpcF2XnKsSY/YgzEyLFDCnejtmGkf",
+	"nkjnqbpuQIWFNDlA+7z13d7CpyvxDiq2LV/ms2Wgt5ffWD5Zp32Nz7sMO1ysZfkStVpnEJ5bq0UL8lTq",
+	"zTR3Cxm6ACoHSGDSBsbmL2VmaGbOIB9Gdsqu/MuinMiixEjfSv9Mo/H3NBejpCkOJOFvCro+uTI+rwlz",
+	"rmoaI+Sg97jnVNMQcyMTvqNojmzYhqqeaMQ41A65jN0XNE8Y7KLPFgY7TXs2gwvNVmELkCkuDImUjhas",
+	"T0yGZ1m940RpseToeDAWqBugVbXJUFXQ7HiWG71g7nj6hd0LHt/Bscv50+y4S7aQE4ufmP/DmPmq1hsD",
+	"kJ3mo1twKa6awySkRwG7Tx/wz/HKcvA3e8T9YLTnjsI/P9yTkAmZB+eEAsg8MFMRXwtIz6QcPD9oO+ZM",
+	"C4M9HegBwDS3uURixh1o4QHIY1z4AkFp54aCmc7vuUJm/GVDO+mOB5oDb10iR/lqRK5sTxJOilz/5OEp",
+	"RM/COkyA/JMpyTsIz+kqZf2lZm872PEtprgV3bPfLa6GG65b/2Jm1vdzr7n/BMmfg4A0hws8oD3Qpnhz",
+	"AKwFNG/6lM5xGeExFIj3nXjHYo3Zg2RaA/c6uaE8L0Dapi1j3tqdgebEuzv8jjs7K7vFa5r5/Y5QQ3As",
+	"ITJQL8j/fPr04fK7oOnbru1utXBlVtCpHfh9p1pO7+2hHXdjT2xP1p3OT9JoJNGh/jKBRJfB502Ih2sv",
+	"mBN3pG9K+IbmYmaa3OPW3yFbPopo0hxeLZoje9NeLzxKdQLr6ur1bkIPZgjRpM+bgGYBozpxTvE4izoP",
+	"6zFb6mIb+JIBNJvi+B3up7/8/h/WeoaXMzTde0wGt2r4CyJS3E/Hee2lhCtmiw4WmtbYMj1i8VrSLGPu",
+	"AtKf19b1Fl7Q0LVCMSoTPW2dad9C3vwdjNtcQoGyZmO0axO0Pfq+YKhsF5hIdZ5DDaX91V0F1UZ3/SUF",
+	"NlxybeGc4JnjuiI1xxuphN6AbMYq8gASmisNPD3xY0fJ8BDymCm+82POW18dPeR8RGXVXX+ylqKuRipJ",
+	"ngJHFlk9dH/VOmtImdPUW09PkSVLro10/VH1uQ4A5yizPpXjqCT2rpiDB+jaO2XOE7a16z3ZTBiU7N1m",
+	"KyHJDiRb7THyssXK6KmikByXUjSXrMS9Ue+ioKXCqJH7iBYQ8IVY81yR/+gF3ThIuzpxl8dHWOh95KQ4",
+	"N4POQjG32pNFGR196/3TMH5gyv2lNBEcIuHA2Pb/Hd1BA9oystu/Genx8XHJwL8l9IG4/+nHPg3RgkiN",
+	"KrwpsGUOwQ64qr3tg3ZOgnRCNM/Py29mjjmxScut41zx8BdU/ogkQLXgz6HHpbTR8mQ+YAYsS5a/XGLR",
+	"ZhRtvmAtiO2dZ9rtT1nejjDD+76oCb39Ugn5B2diHfwtQLbhIm0OBaTNIfrUun1/TCTtNGjiaYOwoZsq",
+	"IjiQXGS1v6pjaFPreEts85WxzDtasJwG95xQvte2KqN8/JanvUgNuYMxHDM8uxe19qcbLIIIsMfxBbnF",
+	"q1KUuyfFlJgqTTpXcNnVbKSQNgAq4m5jQ2StSNgbF1+QX/SmuZah2Zl7oEwHZ0qtBQwQeUHuWu9sobQH",
+	"Vl3t3k7uT3lTCXhYlUodWEs9bGa3F3K1wraAm2rl7Hy96wetwclCJuRTszkzIdVW8XHfZDJ6ckPOEjvh",
+	"Wk+OnLL+0YoO1vZJB+/Zh1QiV28tKiyD1Z56VCVyG1SUKM88rGKW8fO7Iyvq0JmVEZouofBxcp5T++cy",
+	"9CTnVyJMn32AJa4n3evr4/HZ62ZMazKOi9C6P0U4s54WyGK/Rta88tKbti4XvlTeQWFPkUXuEB1co/so",
+	"DT7U9wVTm/DuvFMQYSmdsBDOUoYI6R2yp9hecVPZg0OGJYcMuL1ibdJxfXJDzuG47FpPdlwOG2JvQsTT",
+	"Uu0FAwENPNYzNh+bCMB+01z+7v70obKxBt2LeEz9TG+gDE9vtTdS2K8xhEgJ41lR4wZnP3aMLj6yM+lo",
+	"t4ycd2+vW7gi4aXgz3RzlV3ZM8LdsqSIeOBBuhT+6mRzX1FU7gLdm7m52bD3ODPY/63WP6KEYWFIkWCj",
+	"8Y+hlx1AddO+NKKz06ZqCRpdnUG4n1/QaG3guNA1HeKjROzeNbwcIbq3Ic+z8MGNFZ0ff431VY9vzg1Q",
+	"XMBgDrA7X5g8h7InuvAg4EfP/RlXx8UDEaM97/aPS3cb6GgseIvvP3k2HzZedqivogxrXngZmNkO7wCs",
+	"BFlReQBWW46Zqq22v+Gx1M5W5GdCnhp5BlFOUZgfaPXlplPUUsxM9t5h2h6+CH83CJ3oy6vvojRvLumN",
+	"nSrggmyE0kRVkGHBL0mTWhbJdXKJEVTymPY/KURGC/zIp3Kq+WijdXV9edkMuf7h6urKz/S5gc7/JIWH",
+	"8jFtnuBWWvB3bn8lpvnbtSEGT8I2nuBxr28xeOPj9uCR7+cPHpXNj+Y0j1yuFTyxN2uFQxDDcETn1yla",
+	"xJ0r6YCFBbnPj/8/AEhuHC+BfwAA",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %s", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	var res = make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	pathPrefix := path.Dir(pathToFile)
+
+	for rawPath, rawFunc := range externalRef0.PathToRawSpec(path.Join(pathPrefix, "./api.yaml")) {
+		if _, ok := res[rawPath]; ok {
+			// it is not possible to compare functions in golang, so always overwrite the old value
+		}
+		res[rawPath] = rawFunc
+	}
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	var resolvePath = PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		var pathToFile = url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
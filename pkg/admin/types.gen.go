@@ -0,0 +1,633 @@
+// Package admin provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/deepmap/oapi-codegen version v1.8.3 DO NOT EDIT.
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	externalRef0 "github.com/drone/ff-mock-server/pkg/api"
+)
+
+// Defines values for APIKeyType.
+const (
+	APIKeyTypeClient APIKeyType = "Client"
+
+	APIKeyTypeProxy APIKeyType = "Proxy"
+
+	APIKeyTypeServer APIKeyType = "Server"
+)
+
+// Defines values for CompressionMode.
+const (
+	CompressionModeNegotiate CompressionMode = "negotiate"
+
+	CompressionModeOff CompressionMode = "off"
+
+	CompressionModeTruncated CompressionMode = "truncated"
+
+	CompressionModeUnrequested CompressionMode = "unrequested"
+
+	CompressionModeWrongEncoding CompressionMode = "wrong-encoding"
+)
+
+// Defines values for ETagsMode.
+const (
+	ETagsModeMissing ETagsMode = "missing"
+
+	ETagsModeStale ETagsMode = "stale"
+
+	ETagsModeStrong ETagsMode = "strong"
+)
+
+// Defines values for NetworkFaultContentLength.
+const (
+	NetworkFaultContentLengthGreater NetworkFaultContentLength = "greater"
+
+	NetworkFaultContentLengthMalformed NetworkFaultContentLength = "malformed"
+
+	NetworkFaultContentLengthSmaller NetworkFaultContentLength = "smaller"
+)
+
+// Defines values for NetworkFaultDisconnect.
+const (
+	NetworkFaultDisconnectClose NetworkFaultDisconnect = "close"
+
+	NetworkFaultDisconnectReset NetworkFaultDisconnect = "reset"
+)
+
+// Defines values for RateLimitBy.
+const (
+	RateLimitByApiKey RateLimitBy = "apiKey"
+
+	RateLimitByEnvironment RateLimitBy = "environment"
+
+	RateLimitByIp RateLimitBy = "ip"
+
+	RateLimitByOperation RateLimitBy = "operation"
+)
+
+// Defines values for RateLimitRetryAfter.
+const (
+	RateLimitRetryAfterDate RateLimitRetryAfter = "date"
+
+	RateLimitRetryAfterSeconds RateLimitRetryAfter = "seconds"
+)
+
+// Defines values for StateEtagMode.
+const (
+	StateEtagModeMissing StateEtagMode = "missing"
+
+	StateEtagModeStale StateEtagMode = "stale"
+
+	StateEtagModeStrong StateEtagMode = "strong"
+)
+
+// Defines values for StreamEventDomain.
+const (
+	StreamEventDomainFlag StreamEventDomain = "flag"
+
+	StreamEventDomainTargetSegment StreamEventDomain = "target-segment"
+)
+
+// Defines values for StreamEventEvent.
+const (
+	StreamEventEventCreate StreamEventEvent = "create"
+
+	StreamEventEventDelete StreamEventEvent = "delete"
+
+	StreamEventEventPatch StreamEventEvent = "patch"
+)
+
+// APIKey defines model for APIKey.
+type APIKey struct {
+	Account           *string `json:"account,omitempty"`
+	ClusterIdentifier *string `json:"clusterIdentifier,omitempty"`
+
+	// environment claim of issued tokens, the mocked environment when not set
+	Environment            *string    `json:"environment,omitempty"`
+	Key                    string     `json:"key"`
+	Organization           *string    `json:"organization,omitempty"`
+	OrganizationIdentifier *string    `json:"organizationIdentifier,omitempty"`
+	Type                   APIKeyType `json:"type"`
+}
+
+// APIKeyType defines model for APIKey.Type.
+type APIKeyType string
+
+// Availability defines model for Availability.
+type Availability struct {
+	Available bool `json:"available"`
+}
+
+// Clock defines model for Clock.
+type Clock struct {
+	Frozen bool `json:"frozen"`
+
+	// Speed compared to wall clock, 2 runs twice as fast
+	Speed float64 `json:"speed"`
+
+	// Current time of the clock
+	Time time.Time `json:"time"`
+}
+
+// ClockAdvance defines model for ClockAdvance.
+type ClockAdvance struct {
+	// Duration in milliseconds
+	Duration int64 `json:"duration"`
+}
+
+// ClockSpeed defines model for ClockSpeed.
+type ClockSpeed struct {
+	// Speed compared to wall clock, must be positive
+	Speed float64 `json:"speed"`
+}
+
+// Compression defines model for Compression.
+type Compression struct {
+	// Minimum size in bytes of responses compressed in negotiate mode
+	MinSize *int             `json:"minSize,omitempty"`
+	Mode    *CompressionMode `json:"mode,omitempty"`
+}
+
+// CompressionMode defines model for Compression.Mode.
+type CompressionMode string
+
+// ETags defines model for ETags.
+type ETags struct {
+	Mode ETagsMode `json:"mode"`
+}
+
+// ETagsMode defines model for ETags.Mode.
+type ETagsMode string
+
+// EvaluationCount defines model for EvaluationCount.
+type EvaluationCount struct {
+	Count     int    `json:"count"`
+	Flag      string `json:"flag"`
+	Variation string `json:"variation"`
+}
+
+// FaultRule defines model for FaultRule.
+type FaultRule struct {
+	// Delay in milliseconds
+	Delay   *int64  `json:"delay,omitempty"`
+	Id      *string `json:"id,omitempty"`
+	Message *string `json:"message,omitempty"`
+
+	// operationId of the faulted requests listed by name of its route, all operations are faulted when not set
+	Operation *string `json:"operation,omitempty"`
+
+	// Status returned instead of the response, the request is only delayed when not set
+	Status *int `json:"status,omitempty"`
+
+	// Number of faulted requests, the rule never expires when not set
+	Times *int `json:"times,omitempty"`
+}
+
+// IdentityServiceClaims defines model for IdentityServiceClaims.
+type IdentityServiceClaims struct {
+	AccountId             string  `json:"accountId"`
+	Email                 *string `json:"email,omitempty"`
+	Environment           *string `json:"environment,omitempty"`
+	EnvironmentIdentifier *string `json:"environmentIdentifier,omitempty"`
+	Exp                   *int64  `json:"exp,omitempty"`
+	Iat                   *int64  `json:"iat,omitempty"`
+	Name                  *string `json:"name,omitempty"`
+	Type                  string  `json:"type"`
+	Username              *string `json:"username,omitempty"`
+}
+
+// JournalEntry defines model for JournalEntry.
+type JournalEntry struct {
+	Body *string `json:"body,omitempty"`
+
+	// Duration in milliseconds
+	Duration int64 `json:"duration"`
+
+	// Set when the response was injected by a fault rule
+	Fault     *bool     `json:"fault,omitempty"`
+	Method    string    `json:"method"`
+	Operation string    `json:"operation"`
+	Path      string    `json:"path"`
+	Query     *string   `json:"query,omitempty"`
+	Status    int       `json:"status"`
+	Time      time.Time `json:"time"`
+}
+
+// NetworkFault defines model for NetworkFault.
+type NetworkFault struct {
+	// Bytes of the body sent before the connection is dropped
+	After *int `json:"after,omitempty"`
+
+	// Delay between headers and body in milliseconds
+	BodyDelay *int64 `json:"bodyDelay,omitempty"`
+
+	// Rate the body is trickled at, the body is sent at once when not set
+	BytesPerSecond *int `json:"bytesPerSecond,omitempty"`
+
+	// Content-Length declaring more or less bytes than the body has, or not a number
+	ContentLength *NetworkFaultContentLength `json:"contentLength,omitempty"`
+
+	// Drop the connection after the number of body bytes in after
+	Disconnect *NetworkFaultDisconnect `json:"disconnect,omitempty"`
+
+	// Delay before headers are sent in milliseconds
+	HeaderDelay *int64  `json:"headerDelay,omitempty"`
+	Id          *string `json:"id,omitempty"`
+
+	// operationId of the faulted requests listed by name of its route, all operations are faulted when not set
+	Operation *string `json:"operation,omitempty"`
+
+	// Number of faulted requests, the fault never expires when not set
+	Times *int `json:"times,omitempty"`
+}
+
+// Content-Length declaring more or less bytes than the body has, or not a number
+type NetworkFaultContentLength string
+
+// Drop the connection after the number of body bytes in after
+type NetworkFaultDisconnect string
+
+// RateLimit defines model for RateLimit.
+type RateLimit struct {
+	// Size of the bucket, rate rounded up is used when not set
+	Burst *int `json:"burst,omitempty"`
+
+	// Request attribute a bucket is kept for
+	By RateLimitBy `json:"by"`
+	Id *string     `json:"id,omitempty"`
+
+	// operationId of the limited requests listed by name of its route, all operations are limited when not set
+	Operation *string `json:"operation,omitempty"`
+
+	// Requests per second refilled to the bucket
+	Rate float64 `json:"rate"`
+
+	// Format of Retry-After header, seconds when not set
+	RetryAfter *RateLimitRetryAfter `json:"retryAfter,omitempty"`
+}
+
+// Request attribute a bucket is kept for
+type RateLimitBy string
+
+// Format of Retry-After header, seconds when not set
+type RateLimitRetryAfter string
+
+// ReceivedMetrics defines model for ReceivedMetrics.
+type ReceivedMetrics struct {
+	// Evaluation counts summed by flag and variation
+	Evaluations []EvaluationCount      `json:"evaluations"`
+	Payloads    []externalRef0.Metrics `json:"payloads"`
+
+	// Targets reported by SDKs, each target once
+	Targets []externalRef0.TargetData `json:"targets"`
+}
+
+// RejectTokensRequest defines model for RejectTokensRequest.
+type RejectTokensRequest struct {
+	// Number of rejected requests, 0 clears a pending rejection
+	Count int `json:"count"`
+}
+
+// RotateSigningKeyRequest defines model for RotateSigningKeyRequest.
+type RotateSigningKeyRequest struct {
+	// Secret or PEM encoded private key, generated when not set
+	Key            *string `json:"key,omitempty"`
+	RevokePrevious *bool   `json:"revokePrevious,omitempty"`
+}
+
+// SigningKey defines model for SigningKey.
+type SigningKey struct {
+	Alg     string `json:"alg"`
+	Current bool   `json:"current"`
+	Kid     string `json:"kid"`
+}
+
+// Snapshot defines model for Snapshot.
+type Snapshot struct {
+	Name string `json:"name"`
+
+	// Time of the server clock when the snapshot was saved
+	Time time.Time `json:"time"`
+}
+
+// SnapshotRequest defines model for SnapshotRequest.
+type SnapshotRequest struct {
+	// Generated when not set
+	Name *string `json:"name,omitempty"`
+}
+
+// State defines model for State.
+type State struct {
+	ApiKeys         []APIKey                     `json:"apiKeys"`
+	Compression     Compression                  `json:"compression"`
+	EtagMode        StateEtagMode                `json:"etagMode"`
+	EventsAvailable bool                         `json:"eventsAvailable"`
+	EventsFaults    []FaultRule                  `json:"eventsFaults"`
+	Faults          []FaultRule                  `json:"faults"`
+	Flags           []externalRef0.FeatureConfig `json:"flags"`
+	NetworkFaults   []NetworkFault               `json:"networkFaults"`
+	RateLimits      []RateLimit                  `json:"rateLimits"`
+
+	// Entries of the replayed session left to play in the format of --replay session files, only set when the server replays a session. Imported sessions start with the next request
+	ReplaySession *State_ReplaySession   `json:"replaySession,omitempty"`
+	Segments      []externalRef0.Segment `json:"segments"`
+
+	// Takes streams offline like the --sse and --sse-out options
+	StreamScript     StreamScript `json:"streamScript"`
+	StreamsAvailable bool         `json:"streamsAvailable"`
+	TokenOptions     TokenOptions `json:"tokenOptions"`
+}
+
+// StateEtagMode defines model for State.EtagMode.
+type StateEtagMode string
+
+// Entries of the replayed session left to play in the format of --replay session files, only set when the server replays a session. Imported sessions start with the next request
+type State_ReplaySession struct {
+	AdditionalProperties map[string]interface{} `json:"-"`
+}
+
+// Stream defines model for Stream.
+type Stream struct {
+	Connections int `json:"connections"`
+
+	// api key of the stream
+	Key string `json:"key"`
+}
+
+// StreamAvailability defines model for StreamAvailability.
+type StreamAvailability struct {
+	Available bool `json:"available"`
+}
+
+// StreamEvent defines model for StreamEvent.
+type StreamEvent struct {
+	Domain     StreamEventDomain `json:"domain"`
+	Event      StreamEventEvent  `json:"event"`
+	Identifier string            `json:"identifier"`
+	Version    int64             `json:"version"`
+}
+
+// StreamEventDomain defines model for StreamEvent.Domain.
+type StreamEventDomain string
+
+// StreamEventEvent defines model for StreamEvent.Event.
+type StreamEventEvent string
+
+// Takes streams offline like the --sse and --sse-out options
+type StreamScript struct {
+	// Seconds the stream endpoint responds with 503 after a stream was closed
+	OffDuration *int `json:"offDuration,omitempty"`
+
+	// Seconds streams stay connected before they are closed, every connection uses the next entry
+	OffSequence *[]int `json:"offSequence,omitempty"`
+}
+
+// Tenant defines model for Tenant.
+type Tenant struct {
+	ClientKey string `json:"clientKey"`
+
+	// UUID of the tenant environment
+	Environment string `json:"environment"`
+	Id          string `json:"id"`
+	ProxyKey    string `json:"proxyKey"`
+	ServerKey   string `json:"serverKey"`
+}
+
+// TenantRequest defines model for TenantRequest.
+type TenantRequest struct {
+	// Letters, digits and dashes, generated when not set
+	Id *string `json:"id,omitempty"`
+}
+
+// TokenOptions defines model for TokenOptions.
+type TokenOptions struct {
+	Issuer string `json:"issuer"`
+
+	// Lifetime in seconds, zero means tokens never expire
+	Lifetime int64 `json:"lifetime"`
+
+	// Offset of nbf claim from the issue time in seconds
+	NotBefore int64 `json:"notBefore"`
+}
+
+// IdentifierPathParam defines model for identifierPathParam.
+type IdentifierPathParam string
+
+// KeyQueryParam defines model for keyQueryParam.
+type KeyQueryParam string
+
+// SnapshotPathParam defines model for snapshotPathParam.
+type SnapshotPathParam string
+
+// TenantPathParam defines model for tenantPathParam.
+type TenantPathParam string
+
+// BadRequest defines model for BadRequest.
+type BadRequest externalRef0.Error
+
+// Conflict defines model for Conflict.
+type Conflict externalRef0.Error
+
+// NotFound defines model for NotFound.
+type NotFound externalRef0.Error
+
+// CreateAPIKeyJSONBody defines parameters for CreateAPIKey.
+type CreateAPIKeyJSONBody APIKey
+
+// AdvanceClockJSONBody defines parameters for AdvanceClock.
+type AdvanceClockJSONBody ClockAdvance
+
+// SetClockSpeedJSONBody defines parameters for SetClockSpeed.
+type SetClockSpeedJSONBody ClockSpeed
+
+// SetCompressionJSONBody defines parameters for SetCompression.
+type SetCompressionJSONBody Compression
+
+// SetETagsJSONBody defines parameters for SetETags.
+type SetETagsJSONBody ETags
+
+// SetEventsAvailabilityJSONBody defines parameters for SetEventsAvailability.
+type SetEventsAvailabilityJSONBody Availability
+
+// CreateEventsFaultJSONBody defines parameters for CreateEventsFault.
+type CreateEventsFaultJSONBody FaultRule
+
+// CreateFaultJSONBody defines parameters for CreateFault.
+type CreateFaultJSONBody FaultRule
+
+// SetFlagJSONBody defines parameters for SetFlag.
+type SetFlagJSONBody externalRef0.FeatureConfig
+
+// CreateIdentityTokenJSONBody defines parameters for CreateIdentityToken.
+type CreateIdentityTokenJSONBody IdentityServiceClaims
+
+// GetJournalParams defines parameters for GetJournal.
+type GetJournalParams struct {
+	// operationId of the client api, all requests are returned when not set
+	Operation *string `json:"operation,omitempty"`
+}
+
+// CreateNetworkFaultJSONBody defines parameters for CreateNetworkFault.
+type CreateNetworkFaultJSONBody NetworkFault
+
+// CreateRateLimitJSONBody defines parameters for CreateRateLimit.
+type CreateRateLimitJSONBody RateLimit
+
+// SetSegmentJSONBody defines parameters for SetSegment.
+type SetSegmentJSONBody externalRef0.Segment
+
+// RotateSigningKeyJSONBody defines parameters for RotateSigningKey.
+type RotateSigningKeyJSONBody RotateSigningKeyRequest
+
+// SaveSnapshotJSONBody defines parameters for SaveSnapshot.
+type SaveSnapshotJSONBody SnapshotRequest
+
+// ImportStateJSONBody defines parameters for ImportState.
+type ImportStateJSONBody State
+
+// SetStreamAvailabilityJSONBody defines parameters for SetStreamAvailability.
+type SetStreamAvailabilityJSONBody StreamAvailability
+
+// DisconnectStreamsParams defines parameters for DisconnectStreams.
+type DisconnectStreamsParams struct {
+	// api key of the stream, all streams are used when not set
+	Key *KeyQueryParam `json:"key,omitempty"`
+}
+
+// PublishStreamEventJSONBody defines parameters for PublishStreamEvent.
+type PublishStreamEventJSONBody StreamEvent
+
+// PublishStreamEventParams defines parameters for PublishStreamEvent.
+type PublishStreamEventParams struct {
+	// api key of the stream, all streams are used when not set
+	Key *KeyQueryParam `json:"key,omitempty"`
+}
+
+// CreateTenantJSONBody defines parameters for CreateTenant.
+type CreateTenantJSONBody TenantRequest
+
+// SetTokenOptionsJSONBody defines parameters for SetTokenOptions.
+type SetTokenOptionsJSONBody TokenOptions
+
+// RejectTokensJSONBody defines parameters for RejectTokens.
+type RejectTokensJSONBody RejectTokensRequest
+
+// CreateAPIKeyJSONRequestBody defines body for CreateAPIKey for application/json ContentType.
+type CreateAPIKeyJSONRequestBody CreateAPIKeyJSONBody
+
+// AdvanceClockJSONRequestBody defines body for AdvanceClock for application/json ContentType.
+type AdvanceClockJSONRequestBody AdvanceClockJSONBody
+
+// SetClockSpeedJSONRequestBody defines body for SetClockSpeed for application/json ContentType.
+type SetClockSpeedJSONRequestBody SetClockSpeedJSONBody
+
+// SetCompressionJSONRequestBody defines body for SetCompression for application/json ContentType.
+type SetCompressionJSONRequestBody SetCompressionJSONBody
+
+// SetETagsJSONRequestBody defines body for SetETags for application/json ContentType.
+type SetETagsJSONRequestBody SetETagsJSONBody
+
+// SetEventsAvailabilityJSONRequestBody defines body for SetEventsAvailability for application/json ContentType.
+type SetEventsAvailabilityJSONRequestBody SetEventsAvailabilityJSONBody
+
+// CreateEventsFaultJSONRequestBody defines body for CreateEventsFault for application/json ContentType.
+type CreateEventsFaultJSONRequestBody CreateEventsFaultJSONBody
+
+// CreateFaultJSONRequestBody defines body for CreateFault for application/json ContentType.
+type CreateFaultJSONRequestBody CreateFaultJSONBody
+
+// SetFlagJSONRequestBody defines body for SetFlag for application/json ContentType.
+type SetFlagJSONRequestBody SetFlagJSONBody
+
+// CreateIdentityTokenJSONRequestBody defines body for CreateIdentityToken for application/json ContentType.
+type CreateIdentityTokenJSONRequestBody CreateIdentityTokenJSONBody
+
+// CreateNetworkFaultJSONRequestBody defines body for CreateNetworkFault for application/json ContentType.
+type CreateNetworkFaultJSONRequestBody CreateNetworkFaultJSONBody
+
+// CreateRateLimitJSONRequestBody defines body for CreateRateLimit for application/json ContentType.
+type CreateRateLimitJSONRequestBody CreateRateLimitJSONBody
+
+// SetSegmentJSONRequestBody defines body for SetSegment for application/json ContentType.
+type SetSegmentJSONRequestBody SetSegmentJSONBody
+
+// RotateSigningKeyJSONRequestBody defines body for RotateSigningKey for application/json ContentType.
+type RotateSigningKeyJSONRequestBody RotateSigningKeyJSONBody
+
+// SaveSnapshotJSONRequestBody defines body for SaveSnapshot for application/json ContentType.
+type SaveSnapshotJSONRequestBody SaveSnapshotJSONBody
+
+// ImportStateJSONRequestBody defines body for ImportState for application/json ContentType.
+type ImportStateJSONRequestBody ImportStateJSONBody
+
+// SetStreamAvailabilityJSONRequestBody defines body for SetStreamAvailability for application/json ContentType.
+type SetStreamAvailabilityJSONRequestBody SetStreamAvailabilityJSONBody
+
+// PublishStreamEventJSONRequestBody defines body for PublishStreamEvent for application/json ContentType.
+type PublishStreamEventJSONRequestBody PublishStreamEventJSONBody
+
+// CreateTenantJSONRequestBody defines body for CreateTenant for application/json ContentType.
+type CreateTenantJSONRequestBody CreateTenantJSONBody
+
+// SetTokenOptionsJSONRequestBody defines body for SetTokenOptions for application/json ContentType.
+type SetTokenOptionsJSONRequestBody SetTokenOptionsJSONBody
+
+// RejectTokensJSONRequestBody defines body for RejectTokens for application/json ContentType.
+type RejectTokensJSONRequestBody RejectTokensJSONBody
+
+// Getter for additional properties for State_ReplaySession. Returns the specified
+// element and whether it was found
+func (a State_ReplaySession) Get(fieldName string) (value interface{}, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for State_ReplaySession
+func (a *State_ReplaySession) Set(fieldName string, value interface{}) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]interface{})
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for State_ReplaySession to handle AdditionalProperties
+func (a *State_ReplaySession) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]interface{})
+		for fieldName, fieldBuf := range object {
+			var fieldVal interface{}
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for State_ReplaySession to handle AdditionalProperties
+func (a State_ReplaySession) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
@@ -25,25 +25,31 @@ type ServerInterface interface {
 	Authenticate(ctx echo.Context) error
 	// Get all feature flags activations
 	// (GET /client/env/{environmentUUID}/feature-configs)
-	GetFeatureConfig(ctx echo.Context, environmentUUID string) error
+	GetFeatureConfig(ctx echo.Context, environmentUUID string, params GetFeatureConfigParams) error
 	// Get feature config
 	// (GET /client/env/{environmentUUID}/feature-configs/{identifier})
-	GetFeatureConfigByIdentifier(ctx echo.Context, environmentUUID string, identifier string) error
+	GetFeatureConfigByIdentifier(ctx echo.Context, environmentUUID string, identifier string, params GetFeatureConfigByIdentifierParams) error
 	// Retrieve all segments.
 	// (GET /client/env/{environmentUUID}/target-segments)
-	GetAllSegments(ctx echo.Context, environmentUUID string) error
+	GetAllSegments(ctx echo.Context, environmentUUID string, params GetAllSegmentsParams) error
 	// Retrieve a segment by identifier
 	// (GET /client/env/{environmentUUID}/target-segments/{identifier})
-	GetSegmentByIdentifier(ctx echo.Context, environmentUUID string, identifier string) error
+	GetSegmentByIdentifier(ctx echo.Context, environmentUUID string, identifier string, params GetSegmentByIdentifierParams) error
 	// Get feature evaluations for target
 	// (GET /client/env/{environmentUUID}/target/{target}/evaluations)
-	GetEvaluations(ctx echo.Context, environmentUUID string, target string) error
+	GetEvaluations(ctx echo.Context, environmentUUID string, target string, params GetEvaluationsParams) error
 	// Get feature evaluations for target
 	// (GET /client/env/{environmentUUID}/target/{target}/evaluations/{feature})
-	GetEvaluationByIdentifier(ctx echo.Context, environmentUUID string, target string, feature string) error
+	GetEvaluationByIdentifier(ctx echo.Context, environmentUUID string, target string, feature string, params GetEvaluationByIdentifierParams) error
 	// Send metrics to the Analytics server.
 	// (POST /metrics/{environment})
-	PostMetrics(ctx echo.Context, environment EnvironmentPathParam) error
+	PostMetrics(ctx echo.Context, environment EnvironmentPathParam, params PostMetricsParams) error
+	// Endpoint that the Proxy can use to authenticate with the client server
+	// (POST /proxy/auth)
+	AuthenticateProxyKey(ctx echo.Context) error
+	// Gets Proxy config for multiple environments
+	// (GET /proxy/config)
+	GetProxyConfig(ctx echo.Context, params GetProxyConfigParams) error
 	// Stream endpoint.
 	// (GET /stream)
 	Stream(ctx echo.Context, params StreamParams) error
@@ -76,8 +82,48 @@ func (w *ServerInterfaceWrapper) GetFeatureConfig(ctx echo.Context) error {
 
 	ctx.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetFeatureConfigParams
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
+
+	// ------------- Optional query parameter "pageNumber" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageNumber", ctx.QueryParams(), &params.PageNumber)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pageNumber: %s", err))
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", ctx.QueryParams(), &params.PageSize)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pageSize: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch IfNoneMatchHeaderParam
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.GetFeatureConfig(ctx, environmentUUID)
+	err = w.Handler.GetFeatureConfig(ctx, environmentUUID, params)
 	return err
 }
 
@@ -102,8 +148,34 @@ func (w *ServerInterfaceWrapper) GetFeatureConfigByIdentifier(ctx echo.Context)
 
 	ctx.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetFeatureConfigByIdentifierParams
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch IfNoneMatchHeaderParam
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.GetFeatureConfigByIdentifier(ctx, environmentUUID, identifier)
+	err = w.Handler.GetFeatureConfigByIdentifier(ctx, environmentUUID, identifier, params)
 	return err
 }
 
@@ -120,8 +192,55 @@ func (w *ServerInterfaceWrapper) GetAllSegments(ctx echo.Context) error {
 
 	ctx.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAllSegmentsParams
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
+
+	// ------------- Optional query parameter "rules" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rules", ctx.QueryParams(), &params.Rules)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter rules: %s", err))
+	}
+
+	// ------------- Optional query parameter "pageNumber" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageNumber", ctx.QueryParams(), &params.PageNumber)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pageNumber: %s", err))
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", ctx.QueryParams(), &params.PageSize)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pageSize: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch IfNoneMatchHeaderParam
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.GetAllSegments(ctx, environmentUUID)
+	err = w.Handler.GetAllSegments(ctx, environmentUUID, params)
 	return err
 }
 
@@ -146,8 +265,41 @@ func (w *ServerInterfaceWrapper) GetSegmentByIdentifier(ctx echo.Context) error
 
 	ctx.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSegmentByIdentifierParams
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
+
+	// ------------- Optional query parameter "rules" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "rules", ctx.QueryParams(), &params.Rules)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter rules: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch IfNoneMatchHeaderParam
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.GetSegmentByIdentifier(ctx, environmentUUID, identifier)
+	err = w.Handler.GetSegmentByIdentifier(ctx, environmentUUID, identifier, params)
 	return err
 }
 
@@ -172,8 +324,48 @@ func (w *ServerInterfaceWrapper) GetEvaluations(ctx echo.Context) error {
 
 	ctx.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetEvaluationsParams
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
+
+	// ------------- Optional query parameter "pageNumber" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageNumber", ctx.QueryParams(), &params.PageNumber)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pageNumber: %s", err))
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", ctx.QueryParams(), &params.PageSize)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pageSize: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch IfNoneMatchHeaderParam
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.GetEvaluations(ctx, environmentUUID, target)
+	err = w.Handler.GetEvaluations(ctx, environmentUUID, target, params)
 	return err
 }
 
@@ -206,8 +398,34 @@ func (w *ServerInterfaceWrapper) GetEvaluationByIdentifier(ctx echo.Context) err
 
 	ctx.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetEvaluationByIdentifierParams
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch IfNoneMatchHeaderParam
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.GetEvaluationByIdentifier(ctx, environmentUUID, target, feature)
+	err = w.Handler.GetEvaluationByIdentifier(ctx, environmentUUID, target, feature, params)
 	return err
 }
 
@@ -224,8 +442,91 @@ func (w *ServerInterfaceWrapper) PostMetrics(ctx echo.Context) error {
 
 	ctx.Set(BearerAuthScopes, []string{""})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostMetricsParams
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.PostMetrics(ctx, environment, params)
+	return err
+}
+
+// AuthenticateProxyKey converts echo context to params.
+func (w *ServerInterfaceWrapper) AuthenticateProxyKey(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.AuthenticateProxyKey(ctx)
+	return err
+}
+
+// GetProxyConfig converts echo context to params.
+func (w *ServerInterfaceWrapper) GetProxyConfig(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetProxyConfigParams
+	// ------------- Optional query parameter "pageNumber" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageNumber", ctx.QueryParams(), &params.PageNumber)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pageNumber: %s", err))
+	}
+
+	// ------------- Optional query parameter "pageSize" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "pageSize", ctx.QueryParams(), &params.PageSize)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pageSize: %s", err))
+	}
+
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
+
+	// ------------- Optional query parameter "environment" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "environment", ctx.QueryParams(), &params.Environment)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter environment: %s", err))
+	}
+
+	// ------------- Required query parameter "key" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "key", ctx.QueryParams(), &params.Key)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter key: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch IfNoneMatchHeaderParam
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.PostMetrics(ctx, environment)
+	err = w.Handler.GetProxyConfig(ctx, params)
 	return err
 }
 
@@ -237,6 +538,12 @@ func (w *ServerInterfaceWrapper) Stream(ctx echo.Context) error {
 
 	// Parameter object where we will unmarshal all parameters from the context
 	var params StreamParams
+	// ------------- Optional query parameter "cluster" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cluster", ctx.QueryParams(), &params.Cluster)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cluster: %s", err))
+	}
 
 	headers := ctx.Request().Header
 	// ------------- Required header parameter "API-Key" -------------
@@ -298,52 +605,69 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	router.GET(baseURL+"/client/env/:environmentUUID/target/:target/evaluations", wrapper.GetEvaluations).Name = "GetEvaluations"
 	router.GET(baseURL+"/client/env/:environmentUUID/target/:target/evaluations/:feature", wrapper.GetEvaluationByIdentifier).Name = "GetEvaluationByIdentifier"
 	router.POST(baseURL+"/metrics/:environment", wrapper.PostMetrics).Name = "PostMetrics"
+	router.POST(baseURL+"/proxy/auth", wrapper.AuthenticateProxyKey).Name = "AuthenticateProxyKey"
+	router.GET(baseURL+"/proxy/config", wrapper.GetProxyConfig).Name = "GetProxyConfig"
 	router.GET(baseURL+"/stream", wrapper.Stream).Name = "Stream"
 
 } // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xb3XPbNhL/VzC8e7qhTLlW3ERP5zh1zpdJ44mV9iHjB5hcSqhJgAFAOapH//sNvvgJ",
-	"SlSipu21T4lJ7Ad2f7vYxVJPQczyglGgUgTzp6DAHOcggeu/gK4JZzQHKm+wXN2ol+p5AiLmpJCE0WDe",
-	"XIUqekQo+lQC35wEYUDUsgLLVRAGFOfQJgrCgMOnknBIgrnkJYSBiFeQYyVKbgq1XEhO6DLYbrdqsSgY",
-	"FaBVvKYSOMXZLfA18B84Z1w9jhmVivX8KcBFkZEYK2WjX4TS+KnB/58c0mAe/COq7RCZtyIy3LTM9o6d",
-	"UCS0VARmYRj8yOQVK2ny26uwWAESBcQkJZAgDoKVPAb0iAWiTKJUa7ENgw8Ul3IFVCr58A0U6wqsdGCc",
-	"/PrtFLDS1GtLoRhe1KoRRt/DpxKE1qPgrAAuiQEVLsgb2OgI+IzzIlMIfP7ifDp7lp5NZt8BTGbw/dnk",
-	"xemz2WT6/Px8Nvv+/Pn9i/Mg7OI1DCTmS/DJoIxuclaKBsrvGcsAU0WGpeTkvpTQfM/uf4FYqtckUdtI",
-	"CXBPkLgg60dPM9I+NpnchV0ZnbXWJP11Yc+oJjo9Oy7lasEegO7XrF7qE3iZ4dIrwNnMaxOS+E0FS9yi",
-	"aDiBFV6SNc5KI5FIyIV3jX2AOccbj+GDhocDLajSpOLv2/orIgwVMbHSNsB9GT+AfLkZ0JoT7aG25rti",
-	"7Gcgy5WE5CdHundnlQYteb6dVKm6vYWYJX735SAEXo5AteZQr/fKVhbGfhumGV4O4GdnyD0QmgyjZb/a",
-	"Wq5bbdnt1n28Hxv77TkwDK4Ay5LDJaMpWfYNkkCKy0zqA3afILNoG7aOd59RUiNzpyWBlrnGlA1IVUeo",
-	"YsGuDAN9YNx5ci5L0xqyPhEFB217QSSMN+NNg8pnyIIz7SWfRF5mB0hSdiR0+b7MvIKEtClrFw/r1lu9",
-	"thn/C7bQZ9JbXIxWqDKnIvJo9AXJpZVUckKvDdF3HubAhfVkyniOZTBXUDif1ectoRKWwHtR5VwSdgpO",
-	"hz9nS4u61kZCB31jrqCDq7AdGb5YbbmggWhNzdLUC943sPnJpYx2JD7A5msyjCJ3i33avgXJSSz6cnPz",
-	"4hWWeLR33zZoPHgxVdFBHBc1ie8UGtqOkzFQKozHa+UXz3ZiVrYSXQXH0BlvoV889ap4IlCMKboHxFKk",
-	"qJEFjfNGWKHm6urtD4v315e3XthIkoOQOC/6UtQrhCV6XAFFUolMsMS6V+AQM56AQn5V6p6eT5+ffv9s",
-	"dv4sPDjeGnZ1Vmmq1jaHD4Q3eEnowNGs3HTpLF2rG3qsXuAlXA47Rb2+pgl8bnGaDnG6Jb/CfpmNNLUv",
-	"K1XahY1NNWQ1NWz+32ux5qnUL2d2HLQDSfuwWrbOpHsKvltYunKgU/JxUJ3ihRyV4PfXFvA5zsrEtJoH",
-	"ZBZfXLdrvm6fST6VgOolKGUcSdWXm52e+NpBQo+mXM4S3f2PNpzrCtv7+BHnJvm0Na8735cgMVqA0LdC",
-	"4Y7Sps33giKtquKtlyC5wlJnu1j1bwijUgBHkqns5+yCiE1RDVVG2ck2hd6zZikOMPfSW998eQnSwJB1",
-	"gT86bHndKb47/d4u1Vu9YTO8/aWBVwVXdPaDVJt3vB2H3VFwwjiRG39mVki59jdS4oAOpFcGWplhtRHH",
-	"rxLp88rC9IIdYCPlRYRpgnQxhQpMuMJp22IDdzCjy7VBqCyGbpTibhlSy/y626ZjZ+gvu70KA8aXA+3c",
-	"cONl88ghvZc5p/Zf4nTjuttk1M2Hc43ZwrBTv0mxepS7Q7vhlhEaeg5v0XadncruWNeZ6d5E27oY6N50",
-	"NML8iLAdGfAtWw63aa1WvLcH01jd+kC/p65zTZk4sDTZdyOwf+P1Ut9++5eQvU2vd172PGoGI8rydaO3",
-	"t0Tee3EBcalOk1tlB6PBS8Ac+EUpV/oeVv915RLlf39eBHYMofOuflsnzpWUhRlkEJoyNyDBJp9BjkkW",
-	"zIM4/fcKcwpCnBBWj9Fss4iuMrxEE5TAGjJlGXW+lTyz3MU8ih4fH08aHHTHKHV99x/zFNlKHqWKlzoc",
-	"SQwozghQiXBBFMuqDApOT6YnU3NHDhQXJJgHZ/pRqAd92iiRIY6wNUvBzLClU0cLSFQRyFVnCGtAOMuQ",
-	"gaIrAoUurWPgEhOKbDJFJDnRV+fAtdNU1dCcRYCdK4KQL1myOdrgyT9D2vZHk99Np7+ZUDtj8Yy/3r1R",
-	"XplNT4dYVjpGnlHdbHo2ls5N2BTRbD9RNRrdhsEzY5ndBL7Jro6+Ms8x33ScjR6JXOkeBic5oXYyq1sw",
-	"Xf5/DAwYgzvFwyET6Dp6ahzaHz5cv9pGNhAmsb4R1760BVenEMyyVtAIowSOJVmbRlh1M7YKQO3aoA3b",
-	"1yDbt/BhaxL/cXzv2RzGm5RlOipAFzfX+2fxygAHzePvvhL0ow6atm36hZk3CBqJWluwmaI/3inFayS9",
-	"BqnTTtubDUceA0fRU+2vbQNUu6HwcnPdrAu+EBY6p4/FQ6sQGQ+F8P8XpQeA8zhgdECMXTo4HH3mCJ00",
-	"+x9vFvOevwcfvK9BXmRZVXf+nb/GtZR/weN7bBi89+Hx5BihMJSI98WF00KDFXvCAt1vUCt59mLEIuE4",
-	"Sd2p04uTP2Ra/3Nl9Spk/w7RMSFaYbEbAV8arNGT+XcbQfuDl6GiqfldzJ/m9DkgtmxHPFYr6b5Z+Hbh",
-	"hLPsXarNvfMjmnrAq7bfvsjp+HrcN03CN864O3Ih1tDMuMTZ96gAj56svO04qB/nHPkDo/6gvqUeRf9F",
-	"I3DsN4C/Z2jYD0BacbEdvh28BZogS6OqsQuKs41Uf5gbnl6RdcOErL+d6USEz0D1ksj7ewfjlePfIzol",
-	"h28Of/ey49tUEV0X67DquLlZ99ulFk9CcjC/SvGmy1vzuocEHbArwEk9LZoHFzfXkzf6K7mvjtme70Ir",
-	"zfwOIY5BiMklo5KzbHKRZexx8o6TJekAyH5iGMyDf/U/vdiGwSWOV+D4DJFSNonVOj8HRinEbnjiI38A",
-	"KCY4I+tBBioYJu4LNx8LCZ9lBGu1TDiH9H7ZowF35ksBZhTxgeI1Jhm+z+BAgGmRCGhSMGK/y+mlJveB",
-	"gffspAytmJD1L26q8UqECxKd6lFIlyhjMc40mZPcnsrMo6haMj+bTqc7eCUsfgA+htGL6YsGo7tqq+6D",
-	"BLdlJcM+cQG1vdv+LwAA//82P70JEDYAAA==",
+	"H4sIAAAAAAAC/+w8bW/bONJ/hdDzAAsc5Jc0abY1cMDlrd1c0TaXpNsPRXCgpbHNjUyqJOXUG/i/H0iK",
+	"EiVRttw43babL20skTPDeePMcKj7IGLzlFGgUgSj+2AGOAau/zy7xlP1fwwi4iSVhNFgFFxJzugUAZVE",
+	"LpHEU8QmSM4AcRApowJCJIDGiEhEKDqf9N4xCr23WEYzJBmagkT7wwN0NwOKKJMzQqcommE6hTgIAxHN",
+	"YI4VVrlMIRgFQnJCp8FqtQqDFHM8B5mTFyWZkMD/kwFfvtfU4eRCjWjS/IGSzxkgEiuqJwQ4mjCuic6B",
+	"FL9xFLGMyiAMiJr4WQEPwoDiuSImH72WzjAAuiCc0TlQeYHlrIUmZxQqFqZYpnH2LQUplrOSAGdSEAYc",
+	"PmeEQxyMJM9gPVFkouSgxfCbFnELWWeFXIUSbISjGcSFbIUly6hJSVhFzhv4k+IpvMvmY+BN9BflO78I",
+	"UndAAwuhEqbACzRX5E/wI9Fv2lHkr9ciEDBVgrjMEhC/P9N6WDAVJwm7O5uncvk7TjKwEqrS8VHZgACp",
+	"7IIrKP9cPEN3JEkQB5lxio7eneoXSNkolmScABLAF4RO/6snoAmBJO6jj7k1aWiMW6CYLhGTM+Booaio",
+	"wGZJjBwYiNFk6eK5I3KG3l+aMf0WVumXm8y2VJ7RfXCM40v4nIGQ6lfEqASq/8RpmpAIK9YM/hCKP/cO",
+	"2P/nMAlGwf8NSm81MG/F4Ixzxg2qKn+PsdJcg2wVBudUAqc4uQK+AG5mPToNFqmWG3AEZmAYvGPyFcto",
+	"/PgkXM8AiRQi5fm0KbOMR4DusNAqM9FUGIresliPatrMOybRPH8balfp7AANRz9X/4LQ46KMc+XkGAWF",
+	"5QPFmZypyRGW8A2WX0dY0MA4+fPbEZBjU6/zGQrgUUkaYdSxjJSzFLgkxmpwSt7AUv0FX/A8TSAYBS9e",
+	"Hg4Pnk/2ewfPAHoH8Ot+7+Xe84Pe8MXh4cHBr4cvxi8Pg7BukGEgMZ+CDwdldDlnmXDMeMxYApiqaVhK",
+	"TsaZBPc9G/8BkTaucm/1eAHrL3y7QbmJfXKB3IR1HLWxOUua48IGU4378aw4k7Nrdgt0M2XlUB/CkwRn",
+	"XgSWZ16ekNj7mMIUS/ALgaXeKdq7a4xEwlx4x+QPMOd46WF84Eg40IgKSgr4vqWfEmFmEUabDBhn0S3I",
+	"42UL1ZxoCVUpX2djH4FMZxLi3+3UjSsrKKjg862k2BCqS4hY7BffHITA0w5arSGU4724FYexn4eTxITh",
+	"Hv1Za3K3hMbt2rKZbI3Xjs7Brae9uxzLOU0BhsErwDLjcMLohEybDIlhgrNE6m18EyIzqBqUe5kyMTjX",
+	"chJoNtc6lRtkGBAdh9MiHjVTwkDvHDce58smk1J3fbhSDloIgkjozs8LZ5aPoylnWlw+jCaG64rpyoSf",
+	"Kuj1IRIy913rYOTyvdJjXUdwza715vQWp50JKtipJnko+govU/Euc0LPzaRnHuDARS7JCeNzLE2KcHgQ",
+	"hL6MwTUvK5KwltRZRbS8zNWvspCaHoVVk/AZaYXljirr2Wwy8Srra86y1BV40znqjU80w8UjlBAhdQZp",
+	"hqh0JBOgQkVdLFDgwm7iyHdXr1oTxolcNvFf5zgEsmMQhwRLsgBFiUmKTGqDyrGYAwLjmiBWpDIeAzep",
+	"0B4ag6pVKOpnZDoDIT1CNuZ0HrdT9ItAJLZsUEqjmITRhw/np81oraYzOWxn4WEhAp/U34BNP+uCu4Xl",
+	"Q/YGNd0O9iF+C5KTSDTxzs2LUyxxZ3N868zxKIGJZ7eCeF1O8cUPbcuxOFqCvO4OppCLZzmm/uSpOISW",
+	"edf6RVO/iEARpmgMuiK3TAHlVm+lERZm/+rV27Pry/OTK6/dSzIHIfE8bWJRrxCWpnwnFcoYS6xzSQ6R",
+	"MhelnkWSsnc4fLH36/ODw+fh1g7S4avliktalR0+JbzAU0JbgiolphPL6ZJcn0GrctBJu1DU63Maw5cK",
+	"pGEbJFuRWo/T2Vc2bSMFdaGzqNAtYpUUun97OeaGEc1AdE2I1LLLbpeFlFvfhlD9grMvy46pc6rG7ih5",
+	"bm7hBnQrjWUIi5Pk/SQYfdoQx5UquwrrK3HihCqXfbWCreRQRB2G3u6OrBqpe+C25Lh56XSbsFNP2KhC",
+	"dvGNFTk4fdLatAfcNIpqx6dBY1QYWDqbkRIHFVQcyU6x4uZ8Bb5ESRZD3JmDZs/zy8jNI7uenOTs7PtK",
+	"TITujDhbc+zMOFtpqpUv8RzsQZVDeekQjkFidA1CAhe+JRVZUi3OpUiTqmDrIUjOsNT7cKTiMoRV1MtV",
+	"zDkGZPliwj8iXFIeGAqLMkx/NDLDIpjXIasCpEPmo3env0CMMI2dOPr95S8Q99GlPm2A/HxBR9L2qKPz",
+	"qht5iDcK3MJtqYPN1U6zOceGchW88XqHvGRRK2jUamjrSK/U29yN1x+0e0noks7tMDNblyN5dobuVZ3G",
+	"dtxIiiy8AqVPKt5j7iOkpKh12pydpZjwfhDWONZS1+6cSLWqynVblT6qJwglzodV8He9Q33diUAYMD5t",
+	"qYy117AeLZ5o2nW9XlPWccreAbWEdqF+kzRyJ+cx+YIrTHDobF9iXsCr5Vw7PiJa42grNdZ69dgx8x2q",
+	"bUeDr9DfXkCpVDUbazAljyuf0m+M9M1csWVotqm4unnh5VDfepsHO41FL9bWze80gA4J88Ipm+aTvGeN",
+	"AqJM7SZXig957wJgDlzlnerXWP96ZR3lvz9e20YI7Xf129JxzqRMzeEwoRNmD52x8WcwxyQJRkE0+dcM",
+	"cwpC9AkruyzyLAu9SvAU9VAMC0gUZ0QQBhlPcuhiNBjc3d31HQi6liN1fPubeYrytAip0x3TSxKpLigC",
+	"VCKcEhE4YVCw1x/2h+bcEShOSTAK9vWjUPclaaYMzOQBztmSMiEbdhZ8EBDrNheQnMACEE4SZFTRRpdC",
+	"pxYRcIkJtY1YiMR9fRwJXAtNRQ3u+S7kbVAg5DGLlzs7zPcXF1bNfpZnw+GjITVofC0F798oqRwMh20g",
+	"CxoHTsONnrK3eYqnY+JguN91nm10UJMONk8q+mBWYfC8y4J8bTzaYLP5HPNlTT9MyqFb++I5oXkbTj+w",
+	"GcOnwOhvcKNgWGUGuhjcO/u8qs6vBrnt9KKySpLHaLXYMUkqdiYMETiSZKFFK1RmlQcOqBpOVDX9Nchq",
+	"iaXaBNlSTSqHDFra/lZhneb2RN+hDxn/aE8vji7ON/cp5gcbW/QqblxUe+dnh8lOA2HH0bqMqipADzL8",
+	"XdTTvI4g9PXs+pDkwwZ6jIa139FAi7awH8DpPMyH5Ju+Ni13u/90s7pxXcxrkAg3zNyx8F04mMF9aY4r",
+	"x9us9xHHy3M3xvzm/kJHFl0dRSUc3spH/JTu6+YRo4uab3nyJd9dALON87GOJ7JxwfbexoTfPbd2MoWu",
+	"sfvWQftrkEdJUuSsT4HMQwOZ1usHP3oQ1F6be3JZP6rLuvT5jv4u3FZbkLTJh1kqtHvAHheGxktUCU8a",
+	"/ixX1L864LILafi07zLk+on87GNGa4UTfHJ6P4HTK2y07lO+1v0N7s3/qwFU2+/bUkS3S//vEXt1JS2v",
+	"Qnelygx/qmjtpqWtqrzdrowIb4PWk6P8ropjNj91JGwszprPTj3f4D7Ht+rmA//qkO07dodble/Kjt2/",
+	"o2t+zPjPvSH35Nl+ilLddq4wv91Q8YOr9gP2K6AxyueoRPeI4mQp1Q9z4tnIXy+YkOXFkO08oPcLJzuw",
+	"pd2f49sVtp/c/4gH699Gd+sqpZ1vTa3cEk4+NNdffTliQ1fIGY1TRqg03cgKvr41ofuSM6Fv6mHvWX7e",
+	"sNKi2u75/4W9o/E4+rXmJspTs8gP0SyyMx20ZqCnV4wgKm4BeWuTr0EKi1SP1LvDPEskSZNKrCgQMVcY",
+	"3sDSfCfKfDoKEaHaSRZENe0zjqYKogMLI0HotApLgcK08qQFoq/06d5u2mH8/EiZ7kMT8Fp7TxRBKoXi",
+	"3lnJvfPTPjqfmPsSrjikuSmpeJ8rmv4Kk74OkX+KyZGUnu7IpAIzgYlEoD4qVUI1euN+2MmVe5JU1QcL",
+	"wSKibzcXemwgvIFl20eeqg1KWwT2R1GUKk3Em3HcwnKruP0xQ29Xt59i7x869u7qV1vct5Ac8Nxx3FU/",
+	"eGVeN/yf9wt5RxfnvTdbavljZKfrlVn5NiF6J4xKzpLekfqMXe89J1NSM6D8ixPBKPiH957sCY5mYOG0",
+	"TaWspz8w6IfAKIXINnz7pt8CpD2ckEUrAAlU9ux9eR8ICV/kABZqmLDSrENa/dQZgZq778snTWv4B4oX",
+	"mCR4nMCW2YNmZ7Hr+c967YWv3HCqJFCGZkzI8tt1Rbv7AKdksKdb0+uTEhbhRE+zmKtd8qPBoBgy2h8O",
+	"h2tgxSy6Bd4F0MvhSwfQTbHU+/LLocS0NBRPbLbkPDKuZ3Wz+t8AspOqWpVVAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -15,6 +15,8 @@ const (
 
 	FeatureConfigKindJson FeatureConfigKind = "json"
 
+	FeatureConfigKindNumber FeatureConfigKind = "number"
+
 	FeatureConfigKindString FeatureConfigKind = "string"
 )
 
@@ -100,6 +102,18 @@ type FeatureConfigKind string
 // FeatureState defines model for FeatureState.
 type FeatureState string
 
+// GroupServingRule defines model for GroupServingRule.
+type GroupServingRule struct {
+	// A list of clauses to use in the rule
+	Clauses []Clause `json:"clauses"`
+
+	// The rules priority relative to other rules. The rules are evaluated in order with 1 being the highest
+	Priority int `json:"priority"`
+
+	// The rule's id in the form of a UUID
+	RuleId string `json:"ruleId"`
+}
+
 // KeyValue defines model for KeyValue.
 type KeyValue struct {
 	Key   string `json:"key"`
@@ -142,6 +156,24 @@ type Prerequisite struct {
 	Variations []string `json:"variations"`
 }
 
+// ProxyAuthenticationRequest defines model for ProxyAuthenticationRequest.
+type ProxyAuthenticationRequest struct {
+	ProxyKey string `json:"proxyKey"`
+}
+
+// ProxyConfig defines model for ProxyConfig.
+type ProxyConfig struct {
+	// Embedded struct due to allOf(#/components/schemas/Pagination)
+	Pagination `yaml:",inline"`
+	// Embedded fields due to inline allOf schema
+	Environments *[]struct {
+		ApiKeys        []string        `json:"apiKeys"`
+		FeatureConfigs []FeatureConfig `json:"featureConfigs"`
+		Id             *string         `json:"id,omitempty"`
+		Segments       []Segment       `json:"segments"`
+	} `json:"environments,omitempty"`
+}
+
 // Segment defines model for Segment.
 type Segment struct {
 	CreatedAt   *int64    `json:"createdAt,omitempty"`
@@ -157,9 +189,12 @@ type Segment struct {
 	Name string `json:"name"`
 
 	// An array of rules that can cause a user to be included in this segment.
-	Rules   *[]Clause `json:"rules,omitempty"`
-	Tags    *[]Tag    `json:"tags,omitempty"`
-	Version *int64    `json:"version,omitempty"`
+	Rules *[]Clause `json:"rules,omitempty"`
+
+	// An array of rules that can cause a user to be included in this segment, clauses of a rule are AND'ed and rules are OR'ed. Returned only with rules=v2.
+	ServingRules *[]GroupServingRule `json:"servingRules,omitempty"`
+	Tags         *[]Tag              `json:"tags,omitempty"`
+	Version      *int64              `json:"version,omitempty"`
 }
 
 // Serve defines model for Serve.
@@ -205,8 +240,8 @@ type TargetData struct {
 
 // TargetMap defines model for TargetMap.
 type TargetMap struct {
-	Identifier *string `json:"identifier,omitempty"`
-	Name       string  `json:"name"`
+	Identifier string `json:"identifier"`
+	Name       string `json:"name"`
 }
 
 // Variation defines model for Variation.
@@ -230,9 +265,27 @@ type WeightedVariation struct {
 	Weight    int    `json:"weight"`
 }
 
+// ClusterQueryOptionalParam defines model for clusterQueryOptionalParam.
+type ClusterQueryOptionalParam string
+
 // EnvironmentPathParam defines model for environmentPathParam.
 type EnvironmentPathParam string
 
+// IfNoneMatchHeaderParam defines model for ifNoneMatchHeaderParam.
+type IfNoneMatchHeaderParam string
+
+// PageNumber defines model for pageNumber.
+type PageNumber int
+
+// PageSize defines model for pageSize.
+type PageSize int
+
+// SegmentRulesV2QueryParam defines model for segmentRulesV2QueryParam.
+type SegmentRulesV2QueryParam string
+
+// BadRequest defines model for BadRequest.
+type BadRequest Error
+
 // InternalServerError defines model for InternalServerError.
 type InternalServerError Error
 
@@ -248,12 +301,122 @@ type Unauthorized Error
 // AuthenticateJSONBody defines parameters for Authenticate.
 type AuthenticateJSONBody AuthenticationRequest
 
+// GetFeatureConfigParams defines parameters for GetFeatureConfig.
+type GetFeatureConfigParams struct {
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+
+	// PageNumber
+	PageNumber *PageNumber `json:"pageNumber,omitempty"`
+
+	// PageSize
+	PageSize *PageSize `json:"pageSize,omitempty"`
+
+	// Entity tags of cached responses
+	IfNoneMatch *IfNoneMatchHeaderParam `json:"If-None-Match,omitempty"`
+}
+
+// GetFeatureConfigByIdentifierParams defines parameters for GetFeatureConfigByIdentifier.
+type GetFeatureConfigByIdentifierParams struct {
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+
+	// Entity tags of cached responses
+	IfNoneMatch *IfNoneMatchHeaderParam `json:"If-None-Match,omitempty"`
+}
+
+// GetAllSegmentsParams defines parameters for GetAllSegments.
+type GetAllSegmentsParams struct {
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+
+	// When set to rules=v2 will return AND rule compatible serving_rules field. When not set or set to any other value will return old rules field only compatible with OR rules.
+	Rules *SegmentRulesV2QueryParam `json:"rules,omitempty"`
+
+	// PageNumber
+	PageNumber *PageNumber `json:"pageNumber,omitempty"`
+
+	// PageSize
+	PageSize *PageSize `json:"pageSize,omitempty"`
+
+	// Entity tags of cached responses
+	IfNoneMatch *IfNoneMatchHeaderParam `json:"If-None-Match,omitempty"`
+}
+
+// GetSegmentByIdentifierParams defines parameters for GetSegmentByIdentifier.
+type GetSegmentByIdentifierParams struct {
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+
+	// When set to rules=v2 will return AND rule compatible serving_rules field. When not set or set to any other value will return old rules field only compatible with OR rules.
+	Rules *SegmentRulesV2QueryParam `json:"rules,omitempty"`
+
+	// Entity tags of cached responses
+	IfNoneMatch *IfNoneMatchHeaderParam `json:"If-None-Match,omitempty"`
+}
+
+// GetEvaluationsParams defines parameters for GetEvaluations.
+type GetEvaluationsParams struct {
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+
+	// PageNumber
+	PageNumber *PageNumber `json:"pageNumber,omitempty"`
+
+	// PageSize
+	PageSize *PageSize `json:"pageSize,omitempty"`
+
+	// Entity tags of cached responses
+	IfNoneMatch *IfNoneMatchHeaderParam `json:"If-None-Match,omitempty"`
+}
+
+// GetEvaluationByIdentifierParams defines parameters for GetEvaluationByIdentifier.
+type GetEvaluationByIdentifierParams struct {
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+
+	// Entity tags of cached responses
+	IfNoneMatch *IfNoneMatchHeaderParam `json:"If-None-Match,omitempty"`
+}
+
 // PostMetricsJSONBody defines parameters for PostMetrics.
 type PostMetricsJSONBody Metrics
 
+// PostMetricsParams defines parameters for PostMetrics.
+type PostMetricsParams struct {
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+}
+
+// AuthenticateProxyKeyJSONBody defines parameters for AuthenticateProxyKey.
+type AuthenticateProxyKeyJSONBody ProxyAuthenticationRequest
+
+// GetProxyConfigParams defines parameters for GetProxyConfig.
+type GetProxyConfigParams struct {
+	// PageNumber
+	PageNumber *PageNumber `json:"pageNumber,omitempty"`
+
+	// PageSize
+	PageSize *PageSize `json:"pageSize,omitempty"`
+
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+
+	// Accepts an EnvironmentID. If this is provided then the endpoint will only return config for this environment. If this is left empty then the Proxy will return config for all environments associated with the Proxy Key.
+	Environment *string `json:"environment,omitempty"`
+
+	// Accpets a Proxy Key.
+	Key string `json:"key"`
+
+	// Entity tags of cached responses
+	IfNoneMatch *IfNoneMatchHeaderParam `json:"If-None-Match,omitempty"`
+}
+
 // StreamParams defines parameters for Stream.
 type StreamParams struct {
-	APIKey string `json:"API-Key"`
+	// Unique identifier for the cluster for the account
+	Cluster *ClusterQueryOptionalParam `json:"cluster,omitempty"`
+	APIKey  string                     `json:"API-Key"`
 }
 
 // AuthenticateJSONRequestBody defines body for Authenticate for application/json ContentType.
@@ -261,3 +424,6 @@ type AuthenticateJSONRequestBody AuthenticateJSONBody
 
 // PostMetricsJSONRequestBody defines body for PostMetrics for application/json ContentType.
 type PostMetricsJSONRequestBody PostMetricsJSONBody
+
+// AuthenticateProxyKeyJSONRequestBody defines body for AuthenticateProxyKey for application/json ContentType.
+type AuthenticateProxyKeyJSONRequestBody AuthenticateProxyKeyJSONBody
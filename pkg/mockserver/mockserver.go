@@ -0,0 +1,499 @@
+// Package mockserver starts the feature flag mock server in-process, Go test
+// suites use it instead of running the docker image
+//
+//	srv, err := mockserver.New(mockserver.WithFlags(flag))
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer srv.Close()
+//	// point the SDK to srv.ConfigURL() and srv.EventsURL() and authenticate with srv.ServerKey
+package mockserver
+
+import (
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/drone/ff-mock-server/internal"
+	"github.com/drone/ff-mock-server/internal/config"
+	"github.com/drone/ff-mock-server/internal/router"
+	"github.com/drone/ff-mock-server/internal/server"
+	"github.com/drone/ff-mock-server/internal/service"
+	"github.com/drone/ff-mock-server/pkg/admin"
+	"github.com/drone/ff-mock-server/pkg/api"
+)
+
+// Operations of the client API, they are used to match faults and filter journals
+const (
+	OperationAuthenticate                 = "Authenticate"
+	OperationGetFeatureConfig             = "GetFeatureConfig"
+	OperationGetFeatureConfigByIdentifier = "GetFeatureConfigByIdentifier"
+	OperationGetAllSegments               = "GetAllSegments"
+	OperationGetSegmentByIdentifier       = "GetSegmentByIdentifier"
+	OperationGetEvaluations               = "GetEvaluations"
+	OperationGetEvaluationByIdentifier    = "GetEvaluationByIdentifier"
+	OperationPostMetrics                  = "PostMetrics"
+	OperationStream                       = "Stream"
+	OperationAuthenticateProxyKey         = "AuthenticateProxyKey"
+	OperationGetProxyConfig               = "GetProxyConfig"
+)
+
+// Fault injects an error status and/or a delay into requests of an operation
+type Fault = service.FaultRule
+
+// RateLimit is a token bucket per api key, environment, operation or ip
+type RateLimit = service.RateLimit
+
+// Request attributes rate limits are counted by and formats of Retry-After
+const (
+	RateLimitByAPIKey      = service.RateLimitByAPIKey
+	RateLimitByEnvironment = service.RateLimitByEnvironment
+	RateLimitByOperation   = service.RateLimitByOperation
+	RateLimitByIP          = service.RateLimitByIP
+	RetryAfterSeconds      = service.RetryAfterSeconds
+	RetryAfterDate         = service.RetryAfterDate
+)
+
+// NetworkFault breaks responses of an operation at the connection level
+type NetworkFault = service.NetworkFault
+
+// Ways network faults drop the connection and Content-Length headers they send
+const (
+	DisconnectReset        = service.DisconnectReset
+	DisconnectClose        = service.DisconnectClose
+	ContentLengthGreater   = service.ContentLengthGreater
+	ContentLengthSmaller   = service.ContentLengthSmaller
+	ContentLengthMalformed = service.ContentLengthMalformed
+)
+
+// JournalEntry is a request received by the server
+type JournalEntry = service.JournalEntry
+
+// Option configures the server
+type Option func(*options)
+
+type options struct {
+	config    config.Config
+	logOutput io.Writer
+	flags     []api.FeatureConfig
+	segments  []api.Segment
+	tls       bool
+	events    bool
+}
+
+// WithFlags serves the flags in addition to the mocked flags
+func WithFlags(flags ...api.FeatureConfig) Option {
+	return func(o *options) {
+		o.flags = append(o.flags, flags...)
+	}
+}
+
+// WithSegments serves the target groups in addition to the mocked target group
+func WithSegments(segments ...api.Segment) Option {
+	return func(o *options) {
+		o.segments = append(o.segments, segments...)
+	}
+}
+
+// WithDatasetFile serves flags and segments from a dataset file instead of the mocked ones
+func WithDatasetFile(filename string) Option {
+	return func(o *options) {
+		o.config.DatasetFile = filename
+	}
+}
+
+// WithGeneratedDataset serves a generated dataset instead of the mocked flags and segments
+func WithGeneratedDataset(seed int64, flags, segments int) Option {
+	return func(o *options) {
+		o.config.GenerateSeed = seed
+		o.config.GenerateFlags = flags
+		o.config.GenerateSegments = segments
+	}
+}
+
+// WithTokenLifetime sets lifetime of issued tokens, zero means tokens never expire
+func WithTokenLifetime(lifetime time.Duration) Option {
+	return func(o *options) {
+		o.config.TokenLifetime = int64(lifetime / time.Second)
+	}
+}
+
+// WithClockSpeed runs the server clock speed times faster than wall clock, token
+// expiry and stream off sequences follow the server clock
+func WithClockSpeed(speed float64) Option {
+	return func(o *options) {
+		o.config.ClockSpeed = speed
+	}
+}
+
+// WithStateFile loads state document exported from /admin/state on startup
+func WithStateFile(filename string) Option {
+	return func(o *options) {
+		o.config.StateFile = filename
+	}
+}
+
+// WithMaxPageSize limits page size of paginated lists
+func WithMaxPageSize(size int) Option {
+	return func(o *options) {
+		o.config.MaxPageSize = size
+	}
+}
+
+// WithResponseValidation validates responses against api.yaml, invalid responses
+// are logged or with fail set replaced with 500
+func WithResponseValidation(fail bool) Option {
+	return func(o *options) {
+		o.config.ValidateResponses = router.ResponseValidationLog
+		if fail {
+			o.config.ValidateResponses = router.ResponseValidationFail
+		}
+	}
+}
+
+// WithJournalSize sets number of requests kept in the journal
+func WithJournalSize(size int) Option {
+	return func(o *options) {
+		o.config.JournalSize = size
+	}
+}
+
+// WithBasePath serves the client API under path instead of /api/1.0, stream
+// and metrics are served under eventsPath unless it is empty
+func WithBasePath(path, eventsPath string) Option {
+	return func(o *options) {
+		o.config.BasePath = path
+		o.config.EventsBasePath = eventsPath
+	}
+}
+
+// WithEventsServer serves stream and metrics on a separate port like the
+// events cluster, use EventsURL for them
+func WithEventsServer() Option {
+	return func(o *options) {
+		o.events = true
+	}
+}
+
+// WithTLS serves requests over TLS with HTTP/2 enabled, SDKs have to trust
+// Certificate or use Client
+func WithTLS() Option {
+	return func(o *options) {
+		o.tls = true
+	}
+}
+
+// WithLogOutput writes request logs to w, they are discarded by default
+func WithLogOutput(w io.Writer) Option {
+	return func(o *options) {
+		o.logOutput = w
+	}
+}
+
+// Server is the mock server listening on a random local port
+type Server struct {
+	// URL is base URL of the server, e.g. http://127.0.0.1:50123 or https with WithTLS
+	URL             string
+	ServerKey       string
+	ClientKey       string
+	ProxyKey        string
+	EnvironmentUUID string
+	Environment     string
+	Project         string
+
+	server       *server.Server
+	httpServer   *httptest.Server
+	eventsServer *httptest.Server
+	basePath     string
+	eventsPath   string
+}
+
+// New starts new Server configured with opts
+func New(opts ...Option) (*Server, error) {
+	o := &options{
+		config:    config.Default(),
+		logOutput: ioutil.Discard,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.events {
+		// listeners are started by httptest, the address only splits off the events API
+		o.config.EventsListen = []string{"127.0.0.1:0"}
+	}
+	srv, err := server.New(o.config)
+	if err != nil {
+		return nil, err
+	}
+	srv.Logger.SetOutput(o.logOutput)
+	srv.Echo.Logger.SetOutput(o.logOutput)
+	srv.Events.Logger.SetOutput(o.logOutput)
+	for _, fc := range o.flags {
+		if _, err := srv.SetFlag(fc); err != nil {
+			return nil, err
+		}
+	}
+	for _, segment := range o.segments {
+		if _, err := srv.SetSegment(segment); err != nil {
+			return nil, err
+		}
+	}
+
+	httpServer := startServer(srv.Echo, o.tls)
+	eventsServer := httpServer
+	if srv.Events != srv.Echo {
+		eventsServer = startServer(srv.Events, o.tls)
+	}
+	eventsPath := o.config.EventsBasePath
+	if eventsPath == "" {
+		eventsPath = o.config.BasePath
+	}
+	return &Server{
+		URL:             httpServer.URL,
+		ServerKey:       internal.ServerKey,
+		ClientKey:       internal.ClientKey,
+		ProxyKey:        internal.ProxyKey,
+		EnvironmentUUID: internal.EnvironmentUUID,
+		Environment:     internal.Environment,
+		Project:         internal.Project,
+		server:          srv,
+		httpServer:      httpServer,
+		eventsServer:    eventsServer,
+		basePath:        "/" + strings.Trim(o.config.BasePath, "/"),
+		eventsPath:      "/" + strings.Trim(eventsPath, "/"),
+	}, nil
+}
+
+func startServer(handler http.Handler, tls bool) *httptest.Server {
+	s := httptest.NewUnstartedServer(handler)
+	if tls {
+		s.EnableHTTP2 = true
+		s.StartTLS()
+	} else {
+		s.Start()
+	}
+	return s
+}
+
+// ConfigURL returns base URL of the client API used as config URL of SDKs
+func (s *Server) ConfigURL() string {
+	return s.URL + s.basePath
+}
+
+// EventsURL returns base URL of the stream and metrics used as events URL of SDKs
+func (s *Server) EventsURL() string {
+	return s.eventsServer.URL + s.eventsPath
+}
+
+// Client returns HTTP client trusting the server certificate
+func (s *Server) Client() *http.Client {
+	return s.httpServer.Client()
+}
+
+// Certificate returns the server certificate, nil is returned without WithTLS
+func (s *Server) Certificate() *x509.Certificate {
+	return s.httpServer.Certificate()
+}
+
+// AdminURL returns base URL of the admin API described in admin.yaml
+func (s *Server) AdminURL() string {
+	return s.URL + "/admin"
+}
+
+// AdminClient returns client of the admin API, it controls the server the same
+// way as when it runs in docker
+func (s *Server) AdminClient(opts ...admin.ClientOption) (*admin.ClientWithResponses, error) {
+	opts = append([]admin.ClientOption{admin.WithHTTPClient(s.Client())}, opts...)
+	return admin.NewClientWithResponses(s.AdminURL(), opts...)
+}
+
+// SetFlag creates or replaces the flag and notifies connected SDKs, empty project
+// and environment are set to the mocked ones
+func (s *Server) SetFlag(fc api.FeatureConfig) (api.FeatureConfig, error) {
+	return s.server.SetFlag(fc)
+}
+
+// DeleteFlag removes the flag and notifies connected SDKs
+func (s *Server) DeleteFlag(identifier string) (bool, error) {
+	return s.server.DeleteFlag(identifier)
+}
+
+// SetSegment creates or replaces the target group and notifies connected SDKs
+func (s *Server) SetSegment(segment api.Segment) (api.Segment, error) {
+	return s.server.SetSegment(segment)
+}
+
+// DeleteSegment removes the target group and notifies connected SDKs
+func (s *Server) DeleteSegment(identifier string) (bool, error) {
+	return s.server.DeleteSegment(identifier)
+}
+
+// InjectFault adds the fault rule and returns it with assigned id, operation must
+// be an operationId of api.yaml or one of the Operation constants
+func (s *Server) InjectFault(fault Fault) (Fault, error) {
+	operation, err := router.NormalizeOperation(fault.Operation)
+	if err != nil {
+		return Fault{}, err
+	}
+	fault.Operation = operation
+	return s.server.Faults.Add(fault), nil
+}
+
+// RemoveFault removes the fault rule with id
+func (s *Server) RemoveFault(id string) bool {
+	return s.server.Faults.Remove(id)
+}
+
+// ClearFaults removes all fault rules
+func (s *Server) ClearFaults() {
+	s.server.Faults.Clear()
+}
+
+// InjectEventsFault adds the fault rule applied to stream and metrics only, the
+// rules are independent of the client API ones like on the events cluster
+func (s *Server) InjectEventsFault(fault Fault) (Fault, error) {
+	operation, err := router.NormalizeOperation(fault.Operation)
+	if err != nil {
+		return Fault{}, err
+	}
+	fault.Operation = operation
+	return s.server.EventsFaults.Add(fault), nil
+}
+
+// ClearEventsFaults removes all fault rules of stream and metrics
+func (s *Server) ClearEventsFaults() {
+	s.server.EventsFaults.Clear()
+}
+
+// SetEventsAvailable makes stream and metrics respond with 503 when available is
+// false, connected streams are closed
+func (s *Server) SetEventsAvailable(available bool) {
+	s.server.EventsAvailability.SetAvailable(available)
+	if !available {
+		s.server.Handler.DisconnectStreams("")
+	}
+}
+
+// Now returns current time of the server clock
+func (s *Server) Now() time.Time {
+	return s.server.Clock.Now()
+}
+
+// FreezeClock stops the server clock until it is resumed
+func (s *Server) FreezeClock() {
+	s.server.Clock.Freeze()
+}
+
+// ResumeClock starts the frozen server clock
+func (s *Server) ResumeClock() {
+	s.server.Clock.Resume()
+}
+
+// AdvanceClock moves the server clock forward by d, issued tokens expire and
+// stream off sequences progress without waiting
+func (s *Server) AdvanceClock(d time.Duration) {
+	s.server.Clock.Advance(d)
+}
+
+// SetClockSpeed changes speed of the server clock, speed must be positive
+func (s *Server) SetClockSpeed(speed float64) error {
+	_, err := s.server.Clock.SetSpeed(speed)
+	return err
+}
+
+// SetETagMode changes entity tags sent with configuration responses, "strong" is the
+// default, "missing" sends no tags and "stale" answers 304 to tags of changed resources
+func (s *Server) SetETagMode(mode string) error {
+	return s.server.ETags.SetMode(mode)
+}
+
+// SetCompression changes compression of responses, mode is "negotiate" by default,
+// "off" or one of fault modes "wrong-encoding", "truncated" and "unrequested".
+// Responses of at least minSize bytes are compressed in negotiate mode
+func (s *Server) SetCompression(mode string, minSize int) error {
+	return s.server.Compression.SetOptions(service.CompressionOptions{Mode: mode, MinSize: minSize})
+}
+
+// AddRateLimit adds rate limit, requests exceeding it get 429 with Retry-After.
+// The operation must be an operationId of api.yaml or one of the Operation
+// constants. The limit with assigned id is returned
+func (s *Server) AddRateLimit(limit RateLimit) (RateLimit, error) {
+	operation, err := router.NormalizeOperation(limit.Operation)
+	if err != nil {
+		return RateLimit{}, err
+	}
+	limit.Operation = operation
+	if err := limit.Validate(); err != nil {
+		return RateLimit{}, err
+	}
+	return s.server.RateLimits.Add(limit), nil
+}
+
+// ClearRateLimits removes all rate limits
+func (s *Server) ClearRateLimits() {
+	s.server.RateLimits.Clear()
+}
+
+// AddNetworkFault adds network fault, the operation must be an operationId of
+// api.yaml or one of the Operation constants. The fault with assigned id is returned
+func (s *Server) AddNetworkFault(fault NetworkFault) (NetworkFault, error) {
+	operation, err := router.NormalizeOperation(fault.Operation)
+	if err != nil {
+		return NetworkFault{}, err
+	}
+	fault.Operation = operation
+	if err := fault.Validate(); err != nil {
+		return NetworkFault{}, err
+	}
+	return s.server.NetworkFaults.Add(fault), nil
+}
+
+// ClearNetworkFaults removes all network faults
+func (s *Server) ClearNetworkFaults() {
+	s.server.NetworkFaults.Clear()
+}
+
+// SaveSnapshot saves flags, segments, api keys, token options and fault rules under
+// the name, Reset restores the latest snapshot
+func (s *Server) SaveSnapshot(name string) error {
+	_, err := s.server.Admin.Snapshot(name)
+	return err
+}
+
+// Reset restores the latest snapshot, the state on startup unless other snapshots
+// were saved. Connected SDKs are notified about changed flags and segments
+func (s *Server) Reset() error {
+	_, err := s.server.Admin.Rollback("")
+	return err
+}
+
+// Journal returns requests of the operation, empty operation returns all requests
+// and unknown operation none
+func (s *Server) Journal(operation string) []JournalEntry {
+	name, err := router.NormalizeOperation(operation)
+	if err != nil {
+		return []JournalEntry{}
+	}
+	return s.server.Journal.Entries(name)
+}
+
+// ClearJournal removes all recorded requests
+func (s *Server) ClearJournal() {
+	s.server.Journal.Clear()
+}
+
+// Close disconnects streams and shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+	if s.eventsServer != s.httpServer {
+		s.eventsServer.CloseClientConnections()
+		s.eventsServer.Close()
+	}
+	s.httpServer.CloseClientConnections()
+	s.httpServer.Close()
+}
@@ -0,0 +1,445 @@
+package mockserver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drone/ff-mock-server/pkg/admin"
+	"github.com/drone/ff-mock-server/pkg/mockserver"
+)
+
+// authenticate returns token of the server key
+func authenticate(t *testing.T, srv *mockserver.Server) string {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"apiKey": srv.ServerKey})
+	res, err := srv.Client().Post(srv.ConfigURL()+"/client/auth", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("authentication failed with status %d", res.StatusCode)
+	}
+	var auth struct {
+		AuthToken string `json:"authToken"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&auth); err != nil {
+		t.Fatal(err)
+	}
+	return auth.AuthToken
+}
+
+// metrics has the target data the server requires in the first metrics
+const metrics = `{"targetData":[{"identifier":"alice","name":"Alice","attributes":[]}]}`
+
+// postMetrics posts metrics and returns the response status
+func postMetrics(t *testing.T, srv *mockserver.Server, token string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srv.EventsURL()+"/metrics/"+srv.EnvironmentUUID, bytes.NewReader([]byte(metrics)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res.StatusCode
+}
+
+func TestInjectFaultAndJournal(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	token := authenticate(t, srv)
+
+	if status := postMetrics(t, srv, token); status != http.StatusOK {
+		t.Fatalf("metrics were posted with status %d", status)
+	}
+	fault, err := srv.InjectFault(mockserver.Fault{Operation: mockserver.OperationPostMetrics, Status: http.StatusServiceUnavailable, Times: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fault.ID == "" {
+		t.Error("injected fault has no id")
+	}
+	if status := postMetrics(t, srv, token); status != http.StatusServiceUnavailable {
+		t.Errorf("faulted metrics were posted with status %d", status)
+	}
+	if status := postMetrics(t, srv, token); status != http.StatusOK {
+		t.Errorf("metrics were posted with status %d after the fault expired", status)
+	}
+
+	entries := srv.Journal(mockserver.OperationPostMetrics)
+	if len(entries) != 3 {
+		t.Fatalf("got %d journal entries of metrics, want 3", len(entries))
+	}
+	for i, want := range []int{http.StatusOK, http.StatusServiceUnavailable, http.StatusOK} {
+		entry := entries[i]
+		if entry.Operation != mockserver.OperationPostMetrics || entry.Method != http.MethodPost ||
+			entry.Status != want || entry.Fault != (want != http.StatusOK) {
+			t.Errorf("got journal entry %+v, want status %d", entry, want)
+		}
+	}
+	if entries := srv.Journal(mockserver.OperationAuthenticate); len(entries) != 1 {
+		t.Errorf("got %d journal entries of authentication, want 1", len(entries))
+	}
+	if entries := srv.Journal(""); len(entries) != 4 {
+		t.Errorf("got %d journal entries, want 4", len(entries))
+	}
+
+	srv.ClearJournal()
+	if entries := srv.Journal(""); len(entries) != 0 {
+		t.Errorf("got %d journal entries after clearing", len(entries))
+	}
+}
+
+func TestFaultOperations(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	client, err := srv.AdminClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := authenticate(t, srv)
+
+	tests := []struct {
+		name      string
+		operation string
+		want      int
+		// faulted tells if metrics are faulted by the rule
+		faulted bool
+	}{
+		{name: "operationId", operation: "postMetrics", want: http.StatusCreated, faulted: true},
+		{name: "route name", operation: mockserver.OperationPostMetrics, want: http.StatusCreated, faulted: true},
+		{name: "other operation", operation: "GetFeatureConfig", want: http.StatusCreated},
+		{name: "unknown operation", operation: "postmetrics", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.ClearFaults()
+			status := http.StatusServiceUnavailable
+			res, err := client.CreateFaultWithResponse(context.Background(), admin.CreateFaultJSONRequestBody{Operation: &tt.operation, Status: &status})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode() != tt.want {
+				t.Fatalf("fault was created with status %d, want %d", res.StatusCode(), tt.want)
+			}
+			if res.JSON201 != nil && *res.JSON201.Operation != "PostMetrics" && tt.faulted {
+				t.Errorf("got operation %s, want PostMetrics", *res.JSON201.Operation)
+			}
+			if faulted := postMetrics(t, srv, token) == http.StatusServiceUnavailable; faulted != tt.faulted {
+				t.Errorf("metrics were faulted %t, want %t", faulted, tt.faulted)
+			}
+		})
+	}
+
+	if _, err := srv.InjectFault(mockserver.Fault{Operation: "postmetrics", Status: http.StatusServiceUnavailable}); err == nil {
+		t.Error("fault of unknown operation was injected")
+	}
+	for operation, want := range map[string]int{"postMetrics": http.StatusOK, "PostMetrics": http.StatusOK, "metrics": http.StatusBadRequest} {
+		operation := operation
+		res, err := client.GetJournalWithResponse(context.Background(), &admin.GetJournalParams{Operation: &operation})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode() != want {
+			t.Errorf("journal of %s was returned with status %d, want %d", operation, res.StatusCode(), want)
+		}
+		if want == http.StatusOK && len(*res.JSON200) != len(tests) {
+			t.Errorf("got %d journal entries of %s, want %d", len(*res.JSON200), operation, len(tests))
+		}
+	}
+}
+
+func TestRateLimitOperations(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	client, err := srv.AdminClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := authenticate(t, srv)
+
+	tests := []struct {
+		name      string
+		operation string
+		want      int
+		// limited tells if the second metrics request is limited
+		limited bool
+	}{
+		{name: "operationId", operation: "postMetrics", want: http.StatusCreated, limited: true},
+		{name: "route name", operation: mockserver.OperationPostMetrics, want: http.StatusCreated, limited: true},
+		{name: "other operation", operation: "getFeatureConfig", want: http.StatusCreated},
+		{name: "unknown operation", operation: "metrics", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.ClearRateLimits()
+			burst := 1
+			res, err := client.CreateRateLimitWithResponse(context.Background(), admin.CreateRateLimitJSONRequestBody{
+				By: admin.RateLimitByApiKey, Operation: &tt.operation, Rate: 0.001, Burst: &burst,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode() != tt.want {
+				t.Fatalf("rate limit was created with status %d, want %d", res.StatusCode(), tt.want)
+			}
+			if res.JSON201 != nil && *res.JSON201.Operation != "PostMetrics" && tt.limited {
+				t.Errorf("got operation %s, want PostMetrics", *res.JSON201.Operation)
+			}
+			postMetrics(t, srv, token)
+			if limited := postMetrics(t, srv, token) == http.StatusTooManyRequests; limited != tt.limited {
+				t.Errorf("metrics were limited %t, want %t", limited, tt.limited)
+			}
+		})
+	}
+
+	if _, err := srv.AddRateLimit(mockserver.RateLimit{By: mockserver.RateLimitByAPIKey, Operation: "metrics", Rate: 1}); err == nil {
+		t.Error("rate limit of unknown operation was added")
+	}
+}
+
+func TestNetworkFaultOperations(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	client, err := srv.AdminClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := authenticate(t, srv)
+
+	tests := []struct {
+		name      string
+		operation string
+		want      int
+		// faulted tells if metrics are faulted by the fault
+		faulted bool
+	}{
+		{name: "operationId", operation: "postMetrics", want: http.StatusCreated, faulted: true},
+		{name: "route name", operation: mockserver.OperationPostMetrics, want: http.StatusCreated, faulted: true},
+		{name: "other operation", operation: "getFeatureConfig", want: http.StatusCreated},
+		{name: "unknown operation", operation: "metrics", want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.ClearNetworkFaults()
+			srv.ClearJournal()
+			delay := int64(1)
+			res, err := client.CreateNetworkFaultWithResponse(context.Background(), admin.CreateNetworkFaultJSONRequestBody{
+				Operation: &tt.operation, HeaderDelay: &delay,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode() != tt.want {
+				t.Fatalf("network fault was created with status %d, want %d", res.StatusCode(), tt.want)
+			}
+			if res.JSON201 != nil && *res.JSON201.Operation != "PostMetrics" && tt.faulted {
+				t.Errorf("got operation %s, want PostMetrics", *res.JSON201.Operation)
+			}
+			if status := postMetrics(t, srv, token); status != http.StatusOK {
+				t.Fatalf("metrics were posted with status %d", status)
+			}
+			entries := srv.Journal(mockserver.OperationPostMetrics)
+			if len(entries) != 1 || entries[0].Fault != tt.faulted {
+				t.Errorf("got journal entries %+v, want one faulted %t", entries, tt.faulted)
+			}
+		})
+	}
+
+	if _, err := srv.AddNetworkFault(mockserver.NetworkFault{Operation: "metrics", HeaderDelay: time.Millisecond}); err == nil {
+		t.Error("network fault of unknown operation was added")
+	}
+}
+
+func TestClose(t *testing.T) {
+	srv, err := mockserver.New(mockserver.WithEventsServer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := srv.Client()
+	srv.Close()
+	for _, url := range []string{srv.ConfigURL() + "/client/auth", srv.EventsURL() + "/stream"} {
+		if res, err := client.Get(url); err == nil {
+			res.Body.Close()
+			t.Errorf("%s is served after close", url)
+		}
+	}
+}
+
+// adminRequest sends the document to the admin API and returns the response
+func adminRequest(t *testing.T, srv *mockserver.Server, method, path string, document interface{}) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(method, srv.AdminURL()+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func exportState(t *testing.T, srv *mockserver.Server) map[string]interface{} {
+	t.Helper()
+	res := adminRequest(t, srv, http.MethodGet, "/state", nil)
+	defer res.Body.Close()
+	state := make(map[string]interface{})
+	if err := json.NewDecoder(res.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestImportStateIsAtomic(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	before := exportState(t, srv)
+
+	tests := []struct {
+		name  string
+		field string
+		value interface{}
+	}{
+		{"etag mode", "etagMode", "weak"},
+		{"compression", "compression", map[string]interface{}{"mode": "zip"}},
+		{"duplicate api keys", "apiKeys", []map[string]interface{}{{"key": "a", "type": "Server"}, {"key": "a", "type": "Server"}}},
+		{"stream script", "streamScript", map[string]interface{}{"offSequence": []int{-1}}},
+		{"replay session without replay", "replaySession", map[string]interface{}{"responses": []interface{}{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// valid changes of other fields must not be applied either
+			state := exportState(t, srv)
+			state["flags"] = []interface{}{}
+			state["faults"] = []map[string]interface{}{{"status": 503}}
+			state[tt.field] = tt.value
+			res := adminRequest(t, srv, http.MethodPut, "/state", state)
+			res.Body.Close()
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("invalid state was imported with status %d", res.StatusCode)
+			}
+			if after := exportState(t, srv); !reflect.DeepEqual(before, after) {
+				t.Errorf("state changed by invalid import:\n%v\nwant\n%v", after, before)
+			}
+		})
+	}
+}
+
+func TestImportStreamScript(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	state := exportState(t, srv)
+	script := map[string]interface{}{"offSequence": []interface{}{float64(5), float64(10)}, "offDuration": float64(2)}
+	state["streamScript"] = script
+	res := adminRequest(t, srv, http.MethodPut, "/state", state)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("state was imported with status %d", res.StatusCode)
+	}
+	if got := exportState(t, srv)["streamScript"]; !reflect.DeepEqual(got, script) {
+		t.Errorf("got stream script %v, want %v", got, script)
+	}
+}
+
+func TestImportStateRequiresSections(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	before := exportState(t, srv)
+
+	for _, section := range []string{"flags", "segments", "apiKeys", "faults", "tokenOptions", "streamScript"} {
+		t.Run(section, func(t *testing.T) {
+			state := exportState(t, srv)
+			delete(state, section)
+			res := adminRequest(t, srv, http.MethodPut, "/state", state)
+			res.Body.Close()
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("state without %s was imported with status %d", section, res.StatusCode)
+			}
+			if after := exportState(t, srv); !reflect.DeepEqual(before, after) {
+				t.Errorf("state changed by partial import:\n%v\nwant\n%v", after, before)
+			}
+		})
+	}
+}
+
+func TestConcurrentImports(t *testing.T) {
+	srv, err := mockserver.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	// every document sets a fault and a rate limit, an import interleaved with
+	// another one mixes them
+	states := make([]map[string]interface{}, 4)
+	for i := range states {
+		state := exportState(t, srv)
+		state["faults"] = []map[string]interface{}{{"operation": "postMetrics", "status": 500 + i}}
+		state["rateLimits"] = []map[string]interface{}{{"by": "apiKey", "rate": i + 1}}
+		states[i] = state
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, state := range states {
+			wg.Add(1)
+			go func(state map[string]interface{}) {
+				defer wg.Done()
+				res := adminRequest(t, srv, http.MethodPut, "/state", state)
+				res.Body.Close()
+			}(state)
+		}
+	}
+	wg.Wait()
+
+	after := exportState(t, srv)
+	faults := after["faults"].([]interface{})
+	limits := after["rateLimits"].([]interface{})
+	if len(faults) != 1 || len(limits) != 1 {
+		t.Fatalf("got faults %v and rate limits %v, want one of each", faults, limits)
+	}
+	status := faults[0].(map[string]interface{})["status"].(float64)
+	rate := limits[0].(map[string]interface{})["rate"].(float64)
+	if int(status)-500 != int(rate)-1 {
+		t.Errorf("fault status %v and rate limit %v come from different imports", status, rate)
+	}
+}